@@ -0,0 +1,68 @@
+// Package main prints a report of the carts left behind when SSH sessions
+// ended without an order.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thomas/eva-terminal-go/internal/carts"
+	"github.com/thomas/eva-terminal-go/internal/config"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	path := flag.String("file", cfg.AbandonedCartPath, "abandoned carts file")
+	olderThan := flag.Duration("older-than", 0, "only list carts abandoned at least this long ago")
+	flag.Parse()
+
+	abandoned, err := carts.OpenAbandonedLog(*path)
+	if err != nil {
+		log.Fatalf("Failed to open abandoned carts: %v", err)
+	}
+
+	if err := writeReport(os.Stdout, abandoned.List(), *olderThan, time.Now()); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+}
+
+// writeReport prints one line per abandoned cart, most recent first.
+func writeReport(out io.Writer, list []carts.Abandoned, olderThan time.Duration, now time.Time) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FINGERPRINT\tAGE\tVALUE\tITEMS")
+
+	for _, entry := range list {
+		age := now.Sub(entry.AbandonedAt)
+		if age < olderThan {
+			continue
+		}
+
+		value := "n/a"
+		if subtotal, err := entry.Subtotal(); err == nil {
+			value = subtotal.Currency() + " " + subtotal.String()
+		}
+
+		items := make([]string, len(entry.Cart.Items))
+		for i, item := range entry.Cart.Items {
+			name := item.Name
+			if item.GrindSize != "" {
+				name += " - " + item.GrindSize
+			}
+			items[i] = fmt.Sprintf("%d× %s", item.Quantity, name)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Fingerprint, carts.FormatAge(age), value, strings.Join(items, ", "))
+	}
+
+	return w.Flush()
+}
@@ -6,9 +6,13 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/thomas/eva-terminal-go/internal/woo"
 )
@@ -18,6 +22,41 @@ var testdataFS embed.FS
 
 var products []woo.Product
 var variationsMap map[int][]woo.Variation
+var categories []woo.Category
+var tags []woo.Tag
+var generalSettings json.RawMessage
+var currentCurrency json.RawMessage
+var paymentGateways json.RawMessage
+var shippingZones []mockShippingZone
+var continents json.RawMessage
+var taxSettings json.RawMessage
+var taxClasses json.RawMessage
+var taxRates []woo.TaxRate
+var coupons []woo.Coupon
+var orders []woo.OrderResponse
+var orderNotes map[string][]woo.OrderNote // Keyed by order ID
+
+// Customers can be created and updated, so they are guarded by a mutex.
+var (
+	customersMu sync.Mutex
+	customers   []woo.Customer
+)
+
+// Reviews can be submitted, so they are guarded by a mutex.
+var (
+	reviewsMu sync.Mutex
+	reviews   []woo.ProductReview
+)
+
+// mockShippingZone is a shipping zone fixture. Locations and methods are
+// served from their own endpoints, like WooCommerce does.
+type mockShippingZone struct {
+	ID        int                        `json:"id"`
+	Name      string                     `json:"name"`
+	Order     int                        `json:"order"`
+	Locations []woo.ShippingZoneLocation `json:"locations"`
+	Methods   []woo.ShippingZoneMethod   `json:"methods"`
+}
 
 func init() {
 	// Load products
@@ -29,6 +68,23 @@ func init() {
 		log.Fatalf("Failed to parse products.json: %v", err)
 	}
 
+	// Load categories and tags
+	loadFixture("testdata/categories.json", &categories)
+	loadFixture("testdata/tags.json", &tags)
+	loadFixture("testdata/settings_general.json", &generalSettings)
+	loadFixture("testdata/currency.json", &currentCurrency)
+	loadFixture("testdata/payment_gateways.json", &paymentGateways)
+	loadFixture("testdata/shipping_zones.json", &shippingZones)
+	loadFixture("testdata/continents.json", &continents)
+	loadFixture("testdata/settings_tax.json", &taxSettings)
+	loadFixture("testdata/tax_classes.json", &taxClasses)
+	loadFixture("testdata/tax_rates.json", &taxRates)
+	loadFixture("testdata/coupons.json", &coupons)
+	loadFixture("testdata/customers.json", &customers)
+	loadFixture("testdata/orders.json", &orders)
+	loadFixture("testdata/order_notes.json", &orderNotes)
+	loadFixture("testdata/product_reviews.json", &reviews)
+
 	// Load variations
 	variationsMap = make(map[int][]woo.Variation)
 
@@ -51,11 +107,39 @@ func init() {
 	}
 }
 
+// loadFixture decodes an embedded JSON fixture into v.
+func loadFixture(path string, v interface{}) {
+	data, err := testdataFS.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Failed to load %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		log.Fatalf("Failed to parse %s: %v", path, err)
+	}
+}
+
 func main() {
 	addr := getEnv("MOCKWOO_ADDR", ":18080")
 
 	http.HandleFunc("/wp-json/wc/v3/products", handleProducts)
 	http.HandleFunc("/wp-json/wc/v3/products/", handleProductsWithID)
+	http.HandleFunc("/wp-json/wc/v3/products/categories", handleCategories)
+	http.HandleFunc("/wp-json/wc/v3/products/tags", handleTags)
+	http.HandleFunc("/wp-json/wc/v3/products/reviews", handleReviews)
+	http.HandleFunc("/wp-json/wc/v3/settings/general", handleRawJSON(generalSettings))
+	http.HandleFunc("/wp-json/wc/v3/data/currencies/current", handleRawJSON(currentCurrency))
+	http.HandleFunc("/wp-json/wc/v3/payment_gateways", handleRawJSON(paymentGateways))
+	http.HandleFunc("/wp-json/wc/v3/shipping/zones", handleShippingZones)
+	http.HandleFunc("/wp-json/wc/v3/shipping/zones/", handleShippingZoneWithID)
+	http.HandleFunc("/wp-json/wc/v3/data/continents", handleRawJSON(continents))
+	http.HandleFunc("/wp-json/wc/v3/settings/tax", handleRawJSON(taxSettings))
+	http.HandleFunc("/wp-json/wc/v3/taxes/classes", handleRawJSON(taxClasses))
+	http.HandleFunc("/wp-json/wc/v3/taxes", handleTaxes)
+	http.HandleFunc("/wp-json/wc/v3/coupons", handleCoupons)
+	http.HandleFunc("/wp-json/wc/v3/orders", handleOrders)
+	http.HandleFunc("/wp-json/wc/v3/orders/", handleOrderWithID)
+	http.HandleFunc("/wp-json/wc/v3/customers", handleCustomers)
+	http.HandleFunc("/wp-json/wc/v3/customers/", handleCustomerWithID)
 
 	log.Printf("Mock WooCommerce server listening on %s", addr)
 	log.Printf("Loaded %d products", len(products))
@@ -83,7 +167,9 @@ func handleProducts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Filter products
-	filtered := filterProducts(products, query.Get("search"), query.Get("stock_status"))
+	filtered := filterProducts(products, query)
+	sortProducts(filtered, query.Get("orderby"), query.Get("order"))
+	total := len(filtered)
 
 	// Paginate
 	start := (page - 1) * perPage
@@ -99,8 +185,8 @@ func handleProducts(w http.ResponseWriter, r *http.Request) {
 
 	// Set headers
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("X-WP-Total", strconv.Itoa(len(products)))
-	w.Header().Set("X-WP-TotalPages", strconv.Itoa((len(products)+perPage-1)/perPage))
+	w.Header().Set("X-WP-Total", strconv.Itoa(total))
+	w.Header().Set("X-WP-TotalPages", strconv.Itoa((total+perPage-1)/perPage))
 
 	json.NewEncoder(w).Encode(filtered)
 }
@@ -157,13 +243,448 @@ func handleVariations(w http.ResponseWriter, productID int) {
 	json.NewEncoder(w).Encode(variations)
 }
 
-func filterProducts(products []woo.Product, search, stockStatus string) []woo.Product {
-	if search == "" && stockStatus == "" {
-		return products
+func handleCategories(w http.ResponseWriter, r *http.Request) {
+	result := []woo.Category{}
+	for _, c := range categories {
+		if r.URL.Query().Get("hide_empty") == "true" && c.Count == 0 {
+			continue
+		}
+		result = append(result, c)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-WP-Total", strconv.Itoa(len(result)))
+	w.Header().Set("X-WP-TotalPages", "1")
+	json.NewEncoder(w).Encode(result)
+}
+
+func handleTags(w http.ResponseWriter, r *http.Request) {
+	result := []woo.Tag{}
+	for _, t := range tags {
+		if r.URL.Query().Get("hide_empty") == "true" && t.Count == 0 {
+			continue
+		}
+		result = append(result, t)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-WP-Total", strconv.Itoa(len(result)))
+	w.Header().Set("X-WP-TotalPages", "1")
+	json.NewEncoder(w).Encode(result)
+}
+
+func handleShippingZones(w http.ResponseWriter, r *http.Request) {
+	result := []woo.ShippingZone{}
+	for _, z := range shippingZones {
+		result = append(result, woo.ShippingZone{ID: z.ID, Name: z.Name, Order: z.Order})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleShippingZoneWithID serves /shipping/zones/{id}/locations and /shipping/zones/{id}/methods.
+func handleShippingZoneWithID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/wp-json/wc/v3/shipping/zones/")
+	idPart, resource, _ := strings.Cut(path, "/")
+	zoneID, err := strconv.Atoi(idPart)
+	if err != nil {
+		http.Error(w, "Invalid zone ID", http.StatusBadRequest)
+		return
+	}
+
+	for _, z := range shippingZones {
+		if z.ID != zoneID {
+			continue
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch resource {
+		case "locations":
+			json.NewEncoder(w).Encode(append([]woo.ShippingZoneLocation{}, z.Locations...))
+		case "methods":
+			json.NewEncoder(w).Encode(append([]woo.ShippingZoneMethod{}, z.Methods...))
+		default:
+			json.NewEncoder(w).Encode(woo.ShippingZone{ID: z.ID, Name: z.Name, Order: z.Order})
+		}
+		return
+	}
+
+	http.Error(w, "Shipping zone not found", http.StatusNotFound)
+}
+
+func handleTaxes(w http.ResponseWriter, r *http.Request) {
+	class := r.URL.Query().Get("class")
+	result := []woo.TaxRate{}
+	for _, rate := range taxRates {
+		if class == "" || rate.Class == class {
+			result = append(result, rate)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-WP-Total", strconv.Itoa(len(result)))
+	w.Header().Set("X-WP-TotalPages", "1")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleCoupons lists coupons, filtered by exact code like WooCommerce does.
+func handleCoupons(w http.ResponseWriter, r *http.Request) {
+	code := strings.ToLower(r.URL.Query().Get("code"))
+	result := []woo.Coupon{}
+	for _, c := range coupons {
+		if code == "" || c.Code == code {
+			result = append(result, c)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-WP-Total", strconv.Itoa(len(result)))
+	w.Header().Set("X-WP-TotalPages", "1")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleOrders lists orders, newest first. Orders can't be placed against
+// the mock server; the fixtures are for browsing order history.
+func handleOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	customer, _ := strconv.Atoi(query.Get("customer"))
+	search := strings.ToLower(query.Get("search"))
+	include := map[string]bool{}
+	for _, id := range strings.Split(query.Get("include"), ",") {
+		if id != "" {
+			include[id] = true
+		}
+	}
+	statuses := map[string]bool{}
+	for _, status := range strings.Split(query.Get("status"), ",") {
+		if status != "" && status != "any" {
+			statuses[status] = true
+		}
+	}
+
+	result := []woo.OrderResponse{}
+	for _, o := range orders {
+		if customer > 0 && o.CustomerID != customer {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(o.Billing.Email), search) {
+			continue
+		}
+		if len(include) > 0 && !include[strconv.Itoa(o.ID)] {
+			continue
+		}
+		if len(statuses) > 0 && !statuses[o.Status] {
+			continue
+		}
+		result = append(result, o)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].DateCreated > result[j].DateCreated
+	})
+
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(query.Get("per_page"))
+	if perPage < 1 {
+		perPage = 10
+	}
+	total := len(result)
+	start := min((page-1)*perPage, total)
+	end := min(start+perPage, total)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-WP-Total", strconv.Itoa(total))
+	w.Header().Set("X-WP-TotalPages", strconv.Itoa((total+perPage-1)/perPage))
+	json.NewEncoder(w).Encode(result[start:end])
+}
+
+// handleReviews lists reviews, newest first, and takes new ones. Like a
+// WordPress install with its default discussion settings, reviews are held
+// for moderation unless the reviewer has an approved review already.
+func handleReviews(w http.ResponseWriter, r *http.Request) {
+	reviewsMu.Lock()
+	defer reviewsMu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		product, _ := strconv.Atoi(query.Get("product"))
+		status := query.Get("status")
+		if status == "" {
+			status = "approved"
+		}
+
+		result := []woo.ProductReview{}
+		for _, rv := range reviews {
+			if product > 0 && rv.ProductID != product {
+				continue
+			}
+			if status != "all" && rv.Status != status {
+				continue
+			}
+			result = append(result, rv)
+		}
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].DateCreated > result[j].DateCreated
+		})
+
+		page, _ := strconv.Atoi(query.Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(query.Get("per_page"))
+		if perPage < 1 {
+			perPage = 10
+		}
+		total := len(result)
+		start := min((page-1)*perPage, total)
+		end := min(start+perPage, total)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-WP-Total", strconv.Itoa(total))
+		w.Header().Set("X-WP-TotalPages", strconv.Itoa((total+perPage-1)/perPage))
+		json.NewEncoder(w).Encode(result[start:end])
+
+	case http.MethodPost:
+		var req woo.ProductReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Review == "" || req.Reviewer == "" || req.ReviewerEmail == "" {
+			writeError(w, http.StatusBadRequest, "rest_missing_callback_param", "Missing parameter(s): review, reviewer, reviewer_email")
+			return
+		}
+		if req.Rating < 0 || req.Rating > 5 {
+			writeError(w, http.StatusBadRequest, "rest_invalid_param", "Invalid parameter(s): rating")
+			return
+		}
+		found := false
+		for _, p := range products {
+			found = found || p.ID == req.ProductID
+		}
+		if !found {
+			writeError(w, http.StatusNotFound, "woocommerce_rest_product_invalid_id", "Invalid product ID.")
+			return
+		}
+
+		review := woo.ProductReview{
+			ID:            1,
+			DateCreated:   time.Now().UTC().Format("2006-01-02T15:04:05"),
+			ProductID:     req.ProductID,
+			Status:        "hold",
+			Reviewer:      req.Reviewer,
+			ReviewerEmail: req.ReviewerEmail,
+			Review:        req.Review,
+			Rating:        req.Rating,
+			Verified:      boughtProduct(req.ReviewerEmail, req.ProductID),
+		}
+		for _, rv := range reviews {
+			review.ID = max(review.ID, rv.ID+1)
+			if strings.EqualFold(rv.ReviewerEmail, req.ReviewerEmail) {
+				if rv.ProductID == req.ProductID && rv.Review == req.Review {
+					writeError(w, http.StatusConflict, "woocommerce_rest_comment_duplicate", "Duplicate comment detected; it looks as though you've already said that!")
+					return
+				}
+				if rv.Status == "approved" {
+					review.Status = "approved"
+				}
+			}
+		}
+		reviews = append(reviews, review)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(review)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// boughtProduct reports whether an order billed to email has the product.
+func boughtProduct(email string, productID int) bool {
+	for _, o := range orders {
+		if !strings.EqualFold(o.Billing.Email, email) {
+			continue
+		}
+		for _, item := range o.LineItems {
+			if item.ProductID == productID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// handleOrderWithID serves /orders/{id} and /orders/{id}/notes.
+func handleOrderWithID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/wp-json/wc/v3/orders/")
+	idPart, resource, _ := strings.Cut(path, "/")
+
+	for _, o := range orders {
+		if strconv.Itoa(o.ID) != idPart {
+			continue
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if resource != "notes" {
+			json.NewEncoder(w).Encode(o)
+			return
+		}
+
+		// Newest first, optionally only the notes for the customer
+		notes := []woo.OrderNote{}
+		for _, n := range orderNotes[idPart] {
+			if r.URL.Query().Get("type") == "customer" && !n.CustomerNote {
+				continue
+			}
+			notes = append(notes, n)
+		}
+		sort.SliceStable(notes, func(i, j int) bool {
+			return notes[i].DateCreated > notes[j].DateCreated
+		})
+		json.NewEncoder(w).Encode(notes)
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "woocommerce_rest_shop_order_invalid_id", "Invalid ID.")
+}
+
+// handleCustomers lists customers, filtered by email, or creates one.
+// New customers only live until the server restarts.
+func handleCustomers(w http.ResponseWriter, r *http.Request) {
+	customersMu.Lock()
+	defer customersMu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		email := strings.ToLower(r.URL.Query().Get("email"))
+		result := []woo.Customer{}
+		for _, c := range customers {
+			if email == "" || strings.ToLower(c.Email) == email {
+				result = append(result, c)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-WP-Total", strconv.Itoa(len(result)))
+		w.Header().Set("X-WP-TotalPages", "1")
+		json.NewEncoder(w).Encode(result)
+
+	case http.MethodPost:
+		var req woo.CustomerRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+			writeError(w, http.StatusBadRequest, "rest_missing_callback_param", "Missing parameter(s): email")
+			return
+		}
+		for _, c := range customers {
+			if strings.EqualFold(c.Email, req.Email) {
+				writeError(w, http.StatusBadRequest, "registration-error-email-exists", "An account is already registered with your email address.")
+				return
+			}
+		}
+		customer := woo.Customer{ID: 1, Email: req.Email, FirstName: req.FirstName, LastName: req.LastName, Username: req.Username}
+		for _, c := range customers {
+			customer.ID = max(customer.ID, c.ID+1)
+		}
+		if customer.Username == "" {
+			customer.Username, _, _ = strings.Cut(req.Email, "@")
+		}
+		applyCustomerRequest(&customer, req)
+		customers = append(customers, customer)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(customer)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCustomerWithID serves and updates a single customer.
+func handleCustomerWithID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/wp-json/wc/v3/customers/"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "woocommerce_rest_invalid_id", "Invalid resource ID.")
+		return
+	}
+
+	customersMu.Lock()
+	defer customersMu.Unlock()
+
+	for i := range customers {
+		if customers[i].ID != id {
+			continue
+		}
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var req woo.CustomerRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, "rest_invalid_json", "The JSON body passed is invalid.")
+				return
+			}
+			applyCustomerRequest(&customers[i], req)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(customers[i])
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "woocommerce_rest_invalid_id", "Invalid resource ID.")
+}
+
+// applyCustomerRequest copies the fields set in req onto c.
+func applyCustomerRequest(c *woo.Customer, req woo.CustomerRequest) {
+	if req.Email != "" {
+		c.Email = req.Email
+	}
+	if req.FirstName != "" {
+		c.FirstName = req.FirstName
+	}
+	if req.LastName != "" {
+		c.LastName = req.LastName
+	}
+	if req.Billing != nil {
+		c.Billing = *req.Billing
+	}
+	if req.Shipping != nil {
+		c.Shipping = *req.Shipping
 	}
+}
+
+// writeError writes an error in the WooCommerce REST format.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    code,
+		"message": message,
+		"data":    map[string]int{"status": status},
+	})
+}
 
-	search = strings.ToLower(search)
-	var filtered []woo.Product
+// handleRawJSON serves a fixture as-is.
+func handleRawJSON(body json.RawMessage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}
+}
+
+func filterProducts(products []woo.Product, query url.Values) []woo.Product {
+	search := strings.ToLower(query.Get("search"))
+	stockStatus := query.Get("stock_status")
+	category, _ := strconv.Atoi(query.Get("category"))
+	tag, _ := strconv.Atoi(query.Get("tag"))
+	minPrice, hasMin := parseQueryPrice(query.Get("min_price"))
+	maxPrice, hasMax := parseQueryPrice(query.Get("max_price"))
+	onSale := query.Get("on_sale") == "true"
+	featured := query.Get("featured") == "true"
+	sku := query.Get("sku")
+	include := parseIDList(query.Get("include"))
+	exclude := parseIDList(query.Get("exclude"))
+
+	filtered := []woo.Product{}
 
 	for _, p := range products {
 		// Filter by search term
@@ -179,18 +700,107 @@ func filterProducts(products []woo.Product, search, stockStatus string) []woo.Pr
 			continue
 		}
 
+		// Filter by category and tag
+		if category > 0 && !p.HasCategory(category) {
+			continue
+		}
+		if tag > 0 && !p.HasTag(tag) {
+			continue
+		}
+
+		// Filter by price range, flags and SKU
+		price, _ := strconv.ParseFloat(p.Price, 64)
+		if (hasMin && price < minPrice) || (hasMax && price > maxPrice) {
+			continue
+		}
+		if (onSale && !p.OnSale) || (featured && !p.Featured) {
+			continue
+		}
+		if sku != "" && p.SKU != sku {
+			continue
+		}
+
+		// Filter by included and excluded IDs
+		if include != nil && !include[p.ID] {
+			continue
+		}
+		if exclude[p.ID] {
+			continue
+		}
+
 		filtered = append(filtered, p)
 	}
 
 	return filtered
 }
 
+// sortProducts orders products like WooCommerce does for the orderby and
+// order query parameters. Rating is not tracked by the fixtures, so it keeps
+// the fixture order.
+func sortProducts(products []woo.Product, orderBy, order string) {
+	if orderBy == "" {
+		orderBy = "date"
+	}
+	if order == "" {
+		order = "desc"
+		if orderBy == "title" {
+			order = "asc"
+		}
+	}
+
+	var less func(a, b woo.Product) bool
+	switch orderBy {
+	case "date":
+		less = func(a, b woo.Product) bool { return a.DateCreated < b.DateCreated }
+	case "price":
+		less = func(a, b woo.Product) bool {
+			pa, _ := strconv.ParseFloat(a.Price, 64)
+			pb, _ := strconv.ParseFloat(b.Price, 64)
+			return pa < pb
+		}
+	case "popularity":
+		less = func(a, b woo.Product) bool { return a.TotalSales < b.TotalSales }
+	case "title":
+		less = func(a, b woo.Product) bool { return a.Name < b.Name }
+	default:
+		return
+	}
+
+	sort.SliceStable(products, func(i, j int) bool {
+		if order == "asc" {
+			return less(products[i], products[j])
+		}
+		return less(products[j], products[i])
+	})
+}
+
+// parseQueryPrice parses a min_price/max_price query value.
+func parseQueryPrice(s string) (float64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+// parseIDList parses a comma-separated include/exclude list into a set.
+// It returns nil for an empty list.
+func parseIDList(s string) map[int]bool {
+	if s == "" {
+		return nil
+	}
+	ids := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
-
-
-
@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -22,7 +23,9 @@ import (
 
 	"github.com/thomas/eva-terminal-go/internal/auth"
 	"github.com/thomas/eva-terminal-go/internal/cache"
+	"github.com/thomas/eva-terminal-go/internal/carts"
 	"github.com/thomas/eva-terminal-go/internal/config"
+	"github.com/thomas/eva-terminal-go/internal/termimg"
 	"github.com/thomas/eva-terminal-go/internal/tui"
 	"github.com/thomas/eva-terminal-go/internal/woo"
 )
@@ -66,16 +69,59 @@ func main() {
 
 	// Create WooCommerce client
 	clientOpts := []woo.ClientOption{}
-	log.Printf("WooCommerce API credentials: %s, %s", cfg.WooConsumerKey, cfg.WooConsumerSecret)
+	authMode, err := woo.ParseAuthMode(cfg.WooAuthMode)
+	if err != nil {
+		log.Fatalf("Invalid WOO_AUTH_MODE: %v", err)
+	}
 	if cfg.WooConsumerKey != "" && cfg.WooConsumerSecret != "" {
-		log.Printf("Using WooCommerce API credentials from environment")
-		clientOpts = append(clientOpts, woo.WithCredentials(cfg.WooConsumerKey, cfg.WooConsumerSecret))
+		// Never log the key or secret themselves
+		log.Printf("Using WooCommerce API credentials from environment (auth mode: %s)", authMode)
+		clientOpts = append(clientOpts,
+			woo.WithCredentials(cfg.WooConsumerKey, cfg.WooConsumerSecret),
+			woo.WithAuthMode(authMode),
+		)
+		if authMode == woo.AuthQueryString {
+			log.Printf("WARNING: query-string auth sends the consumer secret in request URLs")
+		}
+	}
+
+	// Retry transient failures and share one rate limiter across all sessions
+	retryPolicy := woo.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts = cfg.WooMaxRetries
+	clientOpts = append(clientOpts, woo.WithRetryPolicy(retryPolicy))
+	if cfg.WooRateLimit > 0 {
+		clientOpts = append(clientOpts, woo.WithRateLimiter(woo.NewRateLimiter(cfg.WooRateLimit, cfg.WooRateBurst)))
 	}
 	wooClient := woo.NewClient(cfg.WooBaseURL, clientOpts...)
 
+	// Fetch the store currency and price format once; every session shares them
+	storeSettings := loadStoreSettings(wooClient)
+
+	// Link SSH keys to customer accounts if configured
+	var customerLinks *auth.CustomerLinks
+	if cfg.CustomerLinksPath != "" {
+		customerLinks, err = auth.LoadCustomerLinks(cfg.CustomerLinksPath)
+		if err != nil {
+			log.Fatalf("Failed to load customer links: %v", err)
+		}
+		log.Printf("Linking SSH keys to customer accounts in %s", cfg.CustomerLinksPath)
+	}
+
+	// Keep carts between sessions, so a dropped connection doesn't lose them
+	cartStore, err := carts.OpenFileStore(cfg.CartStorePath, cfg.CartMaxAge)
+	if err != nil {
+		log.Fatalf("Failed to open cart store: %v", err)
+	}
+	log.Printf("Saving carts to %s", cfg.CartStorePath)
+	abandonedLog, err := carts.OpenAbandonedLog(cfg.AbandonedCartPath)
+	if err != nil {
+		log.Fatalf("Failed to open abandoned carts log: %v", err)
+	}
+
 	// Create caches
-	productsCache := cache.New[tui.ProductListCacheKey, []woo.Product](cfg.CacheTTL)
+	productsCache := cache.New[tui.ProductListCacheKey, woo.ProductPage](cfg.CacheTTL)
 	variationsCache := cache.New[int, []woo.Variation](cfg.CacheTTL)
+	imageLoader := termimg.NewLoader(cfg.ImageMaxBytes, cfg.ImageMaxPixels, cfg.ImageCacheEntries, cfg.ImageCacheTTL)
 
 	// Create SSH server options
 	opts := []ssh.Option{
@@ -83,8 +129,22 @@ func main() {
 		wish.WithHostKeyPath(cfg.SSHHostKeyPath),
 		wish.WithMiddleware(
 			bubbletea.Middleware(func(s ssh.Session) (tea.Model, []tea.ProgramOption) {
-				return tui.NewModel(wooClient, productsCache, variationsCache), []tea.ProgramOption{tea.WithAltScreen()}
+				m := tui.NewModel(wooClient, productsCache, variationsCache, storeSettings)
+				m = m.WithContext(s.Context())
+				m = m.WithLogger(log.Printf)
+				pty, _, _ := s.Pty()
+				m = m.WithImages(imageLoader, pty.Term)
+				if s.PublicKey() != nil {
+					fingerprint := auth.Fingerprint(s.PublicKey())
+					m = m.WithCartStore(cartStore, fingerprint)
+					s.Context().SetValue(flushCartKey{}, m.FlushCart)
+					if customerLinks != nil {
+						m = m.WithCustomerLinks(customerLinks, fingerprint)
+					}
+				}
+				return m, []tea.ProgramOption{tea.WithAltScreen()}
 			}),
+			recordAbandonedCart(cartStore, abandonedLog),
 		),
 	}
 
@@ -137,6 +197,56 @@ func main() {
 	}
 }
 
+// loadStoreSettings fetches the store settings, falling back to the
+// WooCommerce defaults if the store cannot be reached at startup.
+func loadStoreSettings(client *woo.Client) woo.StoreSettings {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	settings, err := client.GetStoreSettings(ctx)
+	if err != nil {
+		log.Printf("WARNING: Failed to load store settings, using defaults: %v", err)
+		return woo.DefaultStoreSettings()
+	}
+	for _, warning := range settings.Warnings {
+		log.Printf("WARNING: Store settings: %s", warning)
+	}
+	log.Printf("Store currency: %s (%s)", settings.Currency, settings.CurrencySymbol)
+	return *settings
+}
+
+// flushCartKey holds the session's Model.FlushCart in its context.
+type flushCartKey struct{}
+
+// recordAbandonedCart returns a middleware that records the cart a session
+// ends with, once the TUI has exited. Carts are saved in the background
+// whenever they change; once the last save is written the saved cart is
+// the one the session ended with. The log keeps one cart per key, not per
+// session: sessions sharing a key share the saved cart, and the last of
+// them to end replaces the entry of those before.
+func recordAbandonedCart(store *carts.FileStore, abandoned *carts.AbandonedLog) wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			next(s)
+			if s.PublicKey() == nil {
+				return
+			}
+
+			if flush, ok := s.Context().Value(flushCartKey{}).(func()); ok {
+				flush()
+			}
+			fingerprint := auth.Fingerprint(s.PublicKey())
+			cart, err := store.Load(fingerprint)
+			if err == nil {
+				err = abandoned.Record(fingerprint, cart, time.Now())
+			}
+			if err != nil {
+				log.Printf("WARNING: Failed to record abandoned cart: %v", err)
+			}
+		}
+	}
+}
+
 // ensureHostKey generates an ED25519 host key if it doesn't exist.
 func ensureHostKey(path string) error {
 	// Check if key exists
@@ -179,6 +289,3 @@ func ensureHostKey(path string) error {
 
 // Dummy usage to prevent import errors
 var _ = list.Model{}
-
-
-
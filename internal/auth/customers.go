@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// CustomerLinks maps SSH key fingerprints to WooCommerce customer IDs.
+// It is backed by a text file with one "fingerprint customer_id" pair per
+// line, and is safe for concurrent use by many sessions.
+type CustomerLinks struct {
+	path string
+
+	mu  sync.RWMutex
+	ids map[string]int
+}
+
+// Fingerprint returns the SHA256 fingerprint of a public key, the same
+// "SHA256:..." form printed by ssh-keygen -l.
+func Fingerprint(key ssh.PublicKey) string {
+	return ssh.FingerprintSHA256(key)
+}
+
+// LoadCustomerLinks reads the customer links file. A missing file is not an
+// error: it is created when the first key is linked. If a fingerprint
+// appears more than once, the last line wins.
+func LoadCustomerLinks(path string) (*CustomerLinks, error) {
+	links := &CustomerLinks{path: path, ids: make(map[string]int)}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return links, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// A wrong mapping would show one customer another's account, so
+		// invalid lines are errors rather than skipped
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "SHA256:") {
+			return nil, fmt.Errorf("%s:%d: expected \"SHA256:<fingerprint> <customer id>\"", path, lineNum)
+		}
+		id, err := strconv.Atoi(fields[1])
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%s:%d: invalid customer ID %q", path, lineNum, fields[1])
+		}
+		links.ids[fields[0]] = id
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// CustomerID returns the customer linked to a key fingerprint.
+func (l *CustomerLinks) CustomerID(fingerprint string) (int, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	id, ok := l.ids[fingerprint]
+	return id, ok
+}
+
+// Link records that a key fingerprint belongs to a customer and appends
+// the pair to the links file.
+func (l *CustomerLinks) Link(fingerprint string, customerID int) error {
+	if !strings.HasPrefix(fingerprint, "SHA256:") || customerID <= 0 {
+		return fmt.Errorf("invalid customer link %q -> %d", fingerprint, customerID)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening customer links: %w", err)
+	}
+	if _, err := fmt.Fprintf(file, "%s %d\n", fingerprint, customerID); err != nil {
+		file.Close()
+		return fmt.Errorf("writing customer link: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("writing customer link: %w", err)
+	}
+
+	l.ids[fingerprint] = customerID
+	return nil
+}
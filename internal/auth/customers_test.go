@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestCustomerLinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "customers")
+
+	// A missing file is an empty set of links
+	links, err := LoadCustomerLinks(path)
+	if err != nil {
+		t.Fatalf("LoadCustomerLinks failed: %v", err)
+	}
+	if _, ok := links.CustomerID("SHA256:abc"); ok {
+		t.Error("expected no customer for an unknown key")
+	}
+
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := Fingerprint(key)
+
+	if err := links.Link(fingerprint, 42); err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+	if id, ok := links.CustomerID(fingerprint); !ok || id != 42 {
+		t.Errorf("expected customer 42, got %d", id)
+	}
+	if err := links.Link("not a fingerprint", 42); err == nil {
+		t.Error("expected an error for an invalid fingerprint")
+	}
+
+	// Links survive a reload, and later lines win
+	if err := links.Link(fingerprint, 43); err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+	reloaded, err := LoadCustomerLinks(path)
+	if err != nil {
+		t.Fatalf("LoadCustomerLinks failed: %v", err)
+	}
+	if id, _ := reloaded.CustomerID(fingerprint); id != 43 {
+		t.Errorf("expected customer 43 after reload, got %d", id)
+	}
+}
+
+func TestLoadCustomerLinksInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "customers")
+	content := "# fingerprint customer_id\nSHA256:abc 7\nSHA256:def seven\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadCustomerLinks(path)
+	if err == nil || !strings.Contains(err.Error(), ":3:") {
+		t.Errorf("expected an error on line 3, got %v", err)
+	}
+}
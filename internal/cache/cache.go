@@ -1,4 +1,5 @@
-// Package cache provides a generic in-memory TTL cache.
+// Package cache provides a generic in-memory TTL cache, optionally bounded
+// in size.
 package cache
 
 import (
@@ -17,6 +18,7 @@ type Cache[K comparable, V any] struct {
 	mu      sync.RWMutex
 	items   map[K]entry[V]
 	ttl     time.Duration
+	maxLen  int              // 0 for no limit
 	nowFunc func() time.Time // For testing
 }
 
@@ -29,6 +31,15 @@ func New[K comparable, V any](ttl time.Duration) *Cache[K, V] {
 	}
 }
 
+// NewBounded creates a cache with the specified TTL that holds at most
+// maxLen items. Setting a new key in a full cache drops the expired items,
+// or if there are none the one set longest ago.
+func NewBounded[K comparable, V any](ttl time.Duration, maxLen int) *Cache[K, V] {
+	c := New[K, V](ttl)
+	c.maxLen = maxLen
+	return c
+}
+
 // Get retrieves a value from the cache.
 // Returns the value and true if found and not expired, otherwise zero value and false.
 func (c *Cache[K, V]) Get(key K) (V, bool) {
@@ -54,6 +65,9 @@ func (c *Cache[K, V]) Set(key K, value V) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if _, ok := c.items[key]; !ok && c.maxLen > 0 && len(c.items) >= c.maxLen {
+		c.evict()
+	}
 	c.items[key] = entry[V]{
 		value:     value,
 		expiresAt: c.nowFunc().Add(c.ttl),
@@ -90,6 +104,27 @@ func (c *Cache[K, V]) Cleanup() {
 	}
 }
 
+// evict makes room for an item: it removes the expired items, or if there
+// are none the one closest to expiring. The caller must hold the lock.
+func (c *Cache[K, V]) evict() {
+	now := c.nowFunc()
+	var oldest K
+	var oldestAt time.Time
+	found := false
+	for key, e := range c.items {
+		if now.After(e.expiresAt) {
+			delete(c.items, key)
+			continue
+		}
+		if !found || e.expiresAt.Before(oldestAt) {
+			oldest, oldestAt, found = key, e.expiresAt, true
+		}
+	}
+	if found && len(c.items) >= c.maxLen {
+		delete(c.items, oldest)
+	}
+}
+
 // Len returns the number of items in the cache (including expired ones).
 func (c *Cache[K, V]) Len() int {
 	c.mu.RLock()
@@ -235,6 +235,43 @@ func TestCacheLen(t *testing.T) {
 	}
 }
 
+func TestCacheBounded(t *testing.T) {
+	c := NewBounded[string, int](time.Minute, 2)
+	currentTime := time.Now()
+	c.nowFunc = func() time.Time {
+		return currentTime
+	}
+
+	c.Set("a", 1)
+	currentTime = currentTime.Add(time.Second)
+	c.Set("b", 2)
+	currentTime = currentTime.Add(time.Second)
+	c.Set("b", 3) // Replacing a key makes no room
+
+	if c.Len() != 2 {
+		t.Fatalf("expected len=2, got %d", c.Len())
+	}
+
+	// The item set longest ago makes room for a new one
+	c.Set("c", 4)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected the oldest item to be dropped")
+	}
+	if val, ok := c.Get("b"); !ok || val != 3 {
+		t.Errorf("expected b=3 to be kept, got %d, %v", val, ok)
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected len=2, got %d", c.Len())
+	}
+
+	// Expired items go first
+	currentTime = currentTime.Add(2 * time.Minute)
+	c.Set("d", 5)
+	if c.Len() != 1 {
+		t.Errorf("expected only the new item, got len=%d", c.Len())
+	}
+}
+
 func TestCacheOverwrite(t *testing.T) {
 	c := New[string, int](time.Minute)
 
@@ -0,0 +1,107 @@
+package carts
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// Abandoned is a cart left behind when an SSH session ended.
+type Abandoned struct {
+	Fingerprint string    `json:"fingerprint"`
+	Cart        Cart      `json:"cart"`
+	AbandonedAt time.Time `json:"abandoned_at"` // When the session ended
+}
+
+// Subtotal returns the value of the abandoned items.
+func (a Abandoned) Subtotal() (woo.Money, error) {
+	total := woo.ZeroMoney(a.Cart.Currency)
+	for _, item := range a.Cart.Items {
+		price, err := woo.ParseMoney(item.Price, a.Cart.Currency)
+		if err != nil {
+			return woo.Money{}, fmt.Errorf("%s: %w", item.Name, err)
+		}
+		// Parsed in the cart currency, so adding can't fail
+		total, _ = total.Add(price.Mul(item.Quantity))
+	}
+	return total, nil
+}
+
+// ItemCount returns the total quantity of the abandoned items.
+func (a Abandoned) ItemCount() int {
+	count := 0
+	for _, item := range a.Cart.Items {
+		count += item.Quantity
+	}
+	return count
+}
+
+// AbandonedLog records the carts left in sessions that ended without an
+// order, one per key fingerprint, in a JSON file. A key's entry is
+// replaced when another of its sessions ends, and removed when one ends
+// with an empty cart. It is safe for concurrent use by many sessions.
+type AbandonedLog struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]Abandoned
+}
+
+// OpenAbandonedLog reads the abandoned carts file. A missing file is not
+// an error: it is created when the first cart is recorded.
+func OpenAbandonedLog(path string) (*AbandonedLog, error) {
+	l := &AbandonedLog{path: path, entries: make(map[string]Abandoned)}
+	if err := readJSON(path, &l.entries); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Record notes the cart a session ended with. A nil or empty cart means
+// the key has nothing left behind, so its entry is removed.
+func (l *AbandonedLog) Record(fingerprint string, cart *Cart, at time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, had := l.entries[fingerprint]
+	if (cart == nil || len(cart.Items) == 0) && !had {
+		return nil
+	}
+
+	entries := make(map[string]Abandoned, len(l.entries)+1)
+	for fp, entry := range l.entries {
+		entries[fp] = entry
+	}
+	if cart == nil || len(cart.Items) == 0 {
+		delete(entries, fingerprint)
+	} else {
+		entries[fingerprint] = Abandoned{Fingerprint: fingerprint, Cart: *cart, AbandonedAt: at}
+	}
+
+	if err := writeJSON(l.path, entries); err != nil {
+		return fmt.Errorf("recording abandoned cart: %w", err)
+	}
+	l.entries = entries
+	return nil
+}
+
+// List returns the abandoned carts, most recently abandoned first.
+func (l *AbandonedLog) List() []Abandoned {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	list := make([]Abandoned, 0, len(l.entries))
+	for _, entry := range l.entries {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].AbandonedAt.Equal(list[j].AbandonedAt) {
+			return list[i].AbandonedAt.After(list[j].AbandonedAt)
+		}
+		return list[i].Fingerprint < list[j].Fingerprint
+	})
+	return list
+}
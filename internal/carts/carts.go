@@ -0,0 +1,122 @@
+// Package carts keeps shopping carts across SSH sessions, keyed by the
+// fingerprint of the customer's public key.
+package carts
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// Cart is a saved shopping cart. Checkout state such as the shipping
+// method and tax estimate is not kept: it is worked out again at checkout.
+type Cart struct {
+	Currency  string      `json:"currency"`
+	Items     []Item      `json:"items"`
+	Coupon    *woo.Coupon `json:"coupon,omitempty"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Item is a line of a saved cart.
+type Item struct {
+	ProductID   int               `json:"product_id"`
+	VariationID int               `json:"variation_id,omitempty"`
+	Name        string            `json:"name"`
+	Price       string            `json:"price"` // Unit price as a decimal string, e.g. "18.99"
+	Quantity    int               `json:"quantity"`
+	GrindSize   string            `json:"grind_size,omitempty"`
+	TaxClass    string            `json:"tax_class,omitempty"`
+	TaxStatus   string            `json:"tax_status,omitempty"`
+	CategoryIDs []int             `json:"category_ids,omitempty"`
+	OnSale      bool              `json:"on_sale,omitempty"`
+	Stock       Stock             `json:"stock"`
+	Meta        map[string]string `json:"meta,omitempty"`
+}
+
+// Stock is the stock of an item when it was last seen, to cap its quantity.
+type Stock struct {
+	Status     string `json:"status,omitempty"`
+	Quantity   *int   `json:"quantity,omitempty"`
+	Backorders bool   `json:"backorders,omitempty"`
+}
+
+// expired reports whether a cart was last changed more than maxAge ago.
+// A zero maxAge keeps carts forever.
+func (c *Cart) expired(maxAge time.Duration, now time.Time) bool {
+	return maxAge > 0 && now.Sub(c.UpdatedAt) > maxAge
+}
+
+// FormatAge describes how long ago a cart was saved or abandoned, roughly:
+// "just now", "5 minutes ago", "3 hours ago" or "2 days ago".
+func FormatAge(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	default:
+		return plural(int(d/(24*time.Hour)), "day")
+	}
+}
+
+// MemoryStore keeps carts in memory. It is meant for tests and for
+// running without a writable disk; carts are lost on restart.
+type MemoryStore struct {
+	maxAge  time.Duration
+	nowFunc func() time.Time // For testing
+
+	mu    sync.Mutex
+	carts map[string]Cart
+}
+
+// NewMemoryStore creates an empty in-memory store. Carts not changed for
+// maxAge are forgotten; zero keeps them forever.
+func NewMemoryStore(maxAge time.Duration) *MemoryStore {
+	return &MemoryStore{
+		maxAge:  maxAge,
+		nowFunc: time.Now,
+		carts:   make(map[string]Cart),
+	}
+}
+
+// Load returns the cart saved for a key fingerprint, or nil if there is
+// none or it has expired.
+func (s *MemoryStore) Load(fingerprint string) (*Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cart, ok := s.carts[fingerprint]
+	if !ok {
+		return nil, nil
+	}
+	if cart.expired(s.maxAge, s.nowFunc()) {
+		delete(s.carts, fingerprint)
+		return nil, nil
+	}
+	return &cart, nil
+}
+
+// Save replaces the cart saved for a key fingerprint. An empty cart
+// removes it.
+func (s *MemoryStore) Save(fingerprint string, cart Cart) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(cart.Items) == 0 {
+		delete(s.carts, fingerprint)
+		return nil
+	}
+	s.carts[fingerprint] = cart
+	return nil
+}
@@ -0,0 +1,171 @@
+package carts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+func testCart(updatedAt time.Time) Cart {
+	return Cart{
+		Currency:  "EUR",
+		Items:     []Item{{ProductID: 101, VariationID: 1012, Name: "House Blend (1kg)", Price: "49.99", Quantity: 2, GrindSize: "Espresso"}},
+		Coupon:    &woo.Coupon{Code: "benvenuto10", DiscountType: woo.DiscountPercent, Amount: "10"},
+		UpdatedAt: updatedAt,
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "carts.json")
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	store, err := OpenFileStore(path, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("OpenFileStore failed on a missing file: %v", err)
+	}
+	store.nowFunc = func() time.Time { return now }
+
+	if cart, err := store.Load("SHA256:ada"); err != nil || cart != nil {
+		t.Fatalf("expected no cart, got %+v, %v", cart, err)
+	}
+	if err := store.Save("SHA256:ada", testCart(now)); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Save("SHA256:old", testCart(now.Add(-48*time.Hour))); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Carts survive a restart
+	store, err = OpenFileStore(path, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("OpenFileStore failed: %v", err)
+	}
+	store.nowFunc = func() time.Time { return now }
+
+	cart, err := store.Load("SHA256:ada")
+	if err != nil || cart == nil {
+		t.Fatalf("expected the saved cart, got %+v, %v", cart, err)
+	}
+	if len(cart.Items) != 1 || cart.Items[0].VariationID != 1012 || cart.Items[0].Price != "49.99" || cart.Coupon == nil || !cart.UpdatedAt.Equal(now) {
+		t.Errorf("unexpected cart %+v", cart)
+	}
+
+	// Expired carts are not restored, and dropped from the file on the next save
+	if cart, _ := store.Load("SHA256:old"); cart != nil {
+		t.Errorf("expected the old cart to have expired, got %+v", cart)
+	}
+	if err := store.Save("SHA256:ada", Cart{Currency: "EUR", UpdatedAt: now}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading carts file: %v", err)
+	}
+	if string(data) != "{}" {
+		t.Errorf("expected emptied and expired carts to be removed, got %s", data)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat carts file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("expected the carts file to be private, got %v", perm)
+	}
+}
+
+func TestOpenFileStoreInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "carts.json")
+	if err := os.WriteFile(path, []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFileStore(path, 0); err == nil {
+		t.Error("expected an error for a corrupt carts file")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(time.Hour)
+	store.nowFunc = func() time.Time { return now }
+
+	if err := store.Save("SHA256:ada", testCart(now.Add(-30*time.Minute))); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if cart, _ := store.Load("SHA256:ada"); cart == nil || cart.Items[0].Quantity != 2 {
+		t.Errorf("expected the saved cart, got %+v", cart)
+	}
+
+	now = now.Add(time.Hour)
+	if cart, _ := store.Load("SHA256:ada"); cart != nil {
+		t.Errorf("expected the cart to have expired, got %+v", cart)
+	}
+}
+
+func TestAbandonedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abandoned.json")
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	abandoned, err := OpenAbandonedLog(path)
+	if err != nil {
+		t.Fatalf("OpenAbandonedLog failed on a missing file: %v", err)
+	}
+
+	// Sessions ending with nothing in the cart leave nothing behind
+	if err := abandoned.Record("SHA256:grace", nil, now); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no file to be written for an empty cart, got %v", err)
+	}
+
+	cart := testCart(now.Add(-time.Hour))
+	if err := abandoned.Record("SHA256:ada", &cart, now.Add(-2*time.Hour)); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := abandoned.Record("SHA256:bob", &cart, now); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	abandoned, err = OpenAbandonedLog(path)
+	if err != nil {
+		t.Fatalf("OpenAbandonedLog failed: %v", err)
+	}
+	list := abandoned.List()
+	if len(list) != 2 || list[0].Fingerprint != "SHA256:bob" || list[1].Fingerprint != "SHA256:ada" {
+		t.Fatalf("expected bob then ada, most recent first, got %+v", list)
+	}
+	subtotal, err := list[1].Subtotal()
+	if err != nil || subtotal.String() != "99.98" || subtotal.Currency() != "EUR" {
+		t.Errorf("expected a value of EUR 99.98, got %s %s, %v", subtotal.Currency(), subtotal, err)
+	}
+	if list[1].ItemCount() != 2 || !list[1].AbandonedAt.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("unexpected entry %+v", list[1])
+	}
+
+	// Coming back and checking out clears the entry
+	if err := abandoned.Record("SHA256:ada", &Cart{Currency: "EUR"}, now); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if list := abandoned.List(); len(list) != 1 || list[0].Fingerprint != "SHA256:bob" {
+		t.Errorf("expected only bob's cart left, got %+v", list)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Second: "just now",
+		time.Minute:      "1 minute ago",
+		45 * time.Minute: "45 minutes ago",
+		3 * time.Hour:    "3 hours ago",
+		50 * time.Hour:   "2 days ago",
+	}
+	for d, want := range tests {
+		if got := FormatAge(d); got != want {
+			t.Errorf("FormatAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
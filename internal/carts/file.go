@@ -0,0 +1,119 @@
+package carts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileStore keeps carts in a JSON file, one entry per key fingerprint.
+// The file is read once when the store is opened and rewritten on every
+// save. It is safe for concurrent use by many sessions.
+type FileStore struct {
+	path    string
+	maxAge  time.Duration
+	nowFunc func() time.Time // For testing
+
+	mu    sync.Mutex
+	carts map[string]Cart
+}
+
+// OpenFileStore reads the carts file. A missing file is not an error: it
+// is created on the first save. Carts not changed for maxAge are dropped;
+// zero keeps them forever.
+func OpenFileStore(path string, maxAge time.Duration) (*FileStore, error) {
+	s := &FileStore{
+		path:    path,
+		maxAge:  maxAge,
+		nowFunc: time.Now,
+		carts:   make(map[string]Cart),
+	}
+
+	if err := readJSON(path, &s.carts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load returns the cart saved for a key fingerprint, or nil if there is
+// none or it has expired.
+func (s *FileStore) Load(fingerprint string) (*Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cart, ok := s.carts[fingerprint]
+	if !ok || cart.expired(s.maxAge, s.nowFunc()) {
+		return nil, nil
+	}
+	return &cart, nil
+}
+
+// Save replaces the cart saved for a key fingerprint and writes the file.
+// An empty cart removes it. Expired carts are dropped on the way.
+func (s *FileStore) Save(fingerprint string, cart Cart) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	carts := make(map[string]Cart, len(s.carts)+1)
+	now := s.nowFunc()
+	for fp, c := range s.carts {
+		if !c.expired(s.maxAge, now) {
+			carts[fp] = c
+		}
+	}
+	if len(cart.Items) == 0 {
+		delete(carts, fingerprint)
+	} else {
+		carts[fingerprint] = cart
+	}
+
+	if err := writeJSON(s.path, carts); err != nil {
+		return fmt.Errorf("saving cart: %w", err)
+	}
+	s.carts = carts
+	return nil
+}
+
+// writeJSON replaces the file at path with v encoded as JSON, through a
+// temporary file in the same directory so a crash never leaves it half
+// written.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readJSON decodes the file at path into v. A missing file leaves v as
+// it is.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
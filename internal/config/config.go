@@ -24,25 +24,49 @@ type Config struct {
 	SSHAuthMode    AuthMode
 	AllowlistPath  string
 
+	// Links SSH keys to WooCommerce customer accounts; empty disables accounts
+	CustomerLinksPath string
+
+	// Carts kept between sessions, keyed by SSH key
+	CartStorePath     string
+	CartMaxAge        time.Duration // Carts not changed for this long are dropped
+	AbandonedCartPath string        // Carts left when sessions ended, for the report
+
 	// WooCommerce API settings
-	WooBaseURL       string
-	WooConsumerKey   string
+	WooBaseURL        string
+	WooConsumerKey    string
 	WooConsumerSecret string
+	WooAuthMode       string // "auto", "basic", "oauth1" or "query"
+
+	// WooCommerce request throttling
+	WooMaxRetries int     // Attempts per GET request, including the first
+	WooRateLimit  float64 // Requests per second shared by all sessions
+	WooRateBurst  int
 
 	// Cache settings
 	CacheTTL time.Duration
+
+	// Product images shown in the details view
+	ImageCacheTTL     time.Duration
+	ImageCacheEntries int   // Rendered images kept at most
+	ImageMaxBytes     int64 // Larger images aren't downloaded
+	ImageMaxPixels    int64 // Larger images aren't decoded
 }
 
 // Load reads configuration from environment variables with defaults.
 func Load() (*Config, error) {
 	cfg := &Config{
-		SSHAddr:          getEnv("SSH_ADDR", ":23234"),
-		SSHHostKeyPath:   getEnv("SSH_HOSTKEY_PATH", "./.ssh_host_ed25519_key"),
-		SSHAuthMode:      AuthMode(getEnv("SSH_AUTH_MODE", "allowlist")),
-		AllowlistPath:    getEnv("SSH_ALLOWLIST_PATH", "./allowlist_authorized_keys"),
-		WooBaseURL:       getEnv("WOO_BASE_URL", "http://127.0.0.1:18080"),
-		WooConsumerKey:   os.Getenv("WOO_CONSUMER_KEY"),
+		SSHAddr:           getEnv("SSH_ADDR", ":23234"),
+		SSHHostKeyPath:    getEnv("SSH_HOSTKEY_PATH", "./.ssh_host_ed25519_key"),
+		SSHAuthMode:       AuthMode(getEnv("SSH_AUTH_MODE", "allowlist")),
+		AllowlistPath:     getEnv("SSH_ALLOWLIST_PATH", "./allowlist_authorized_keys"),
+		CustomerLinksPath: os.Getenv("SSH_CUSTOMERS_PATH"),
+		CartStorePath:     getEnv("CART_STORE_PATH", "./carts.json"),
+		AbandonedCartPath: getEnv("CART_ABANDONED_PATH", "./abandoned_carts.json"),
+		WooBaseURL:        getEnv("WOO_BASE_URL", "http://127.0.0.1:18080"),
+		WooConsumerKey:    os.Getenv("WOO_CONSUMER_KEY"),
 		WooConsumerSecret: os.Getenv("WOO_CONSUMER_SECRET"),
+		WooAuthMode:       getEnv("WOO_AUTH_MODE", "auto"),
 	}
 
 	// Parse cache TTL
@@ -52,6 +76,46 @@ func Load() (*Config, error) {
 	}
 	cfg.CacheTTL = time.Duration(ttlSeconds) * time.Second
 
+	// Parse image settings
+	imageTTLSeconds, err := strconv.Atoi(getEnv("IMAGE_CACHE_TTL_SECONDS", "3600"))
+	if err != nil {
+		return nil, errors.New("IMAGE_CACHE_TTL_SECONDS must be a valid integer")
+	}
+	cfg.ImageCacheTTL = time.Duration(imageTTLSeconds) * time.Second
+	cfg.ImageMaxBytes, err = strconv.ParseInt(getEnv("IMAGE_MAX_BYTES", "2097152"), 10, 64)
+	if err != nil || cfg.ImageMaxBytes < 1 {
+		return nil, errors.New("IMAGE_MAX_BYTES must be a positive integer")
+	}
+	cfg.ImageMaxPixels, err = strconv.ParseInt(getEnv("IMAGE_MAX_PIXELS", "16777216"), 10, 64)
+	if err != nil || cfg.ImageMaxPixels < 1 {
+		return nil, errors.New("IMAGE_MAX_PIXELS must be a positive integer")
+	}
+	cfg.ImageCacheEntries, err = strconv.Atoi(getEnv("IMAGE_CACHE_ENTRIES", "256"))
+	if err != nil || cfg.ImageCacheEntries < 1 {
+		return nil, errors.New("IMAGE_CACHE_ENTRIES must be a positive integer")
+	}
+
+	// Parse cart max age
+	cartMaxAgeHours, err := strconv.Atoi(getEnv("CART_MAX_AGE_HOURS", "336"))
+	if err != nil || cartMaxAgeHours < 0 {
+		return nil, errors.New("CART_MAX_AGE_HOURS must be a non-negative integer")
+	}
+	cfg.CartMaxAge = time.Duration(cartMaxAgeHours) * time.Hour
+
+	// Parse retry and rate limit settings
+	cfg.WooMaxRetries, err = strconv.Atoi(getEnv("WOO_MAX_RETRIES", "3"))
+	if err != nil || cfg.WooMaxRetries < 1 {
+		return nil, errors.New("WOO_MAX_RETRIES must be a positive integer")
+	}
+	cfg.WooRateLimit, err = strconv.ParseFloat(getEnv("WOO_RATE_LIMIT", "10"), 64)
+	if err != nil || cfg.WooRateLimit < 0 {
+		return nil, errors.New("WOO_RATE_LIMIT must be a non-negative number")
+	}
+	cfg.WooRateBurst, err = strconv.Atoi(getEnv("WOO_RATE_BURST", "20"))
+	if err != nil || cfg.WooRateBurst < 1 {
+		return nil, errors.New("WOO_RATE_BURST must be a positive integer")
+	}
+
 	// Validate auth mode
 	if cfg.SSHAuthMode != AuthModeAllowlist && cfg.SSHAuthMode != AuthModePublic {
 		return nil, errors.New("SSH_AUTH_MODE must be 'allowlist' or 'public'")
@@ -67,6 +131,3 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
-
-
-
@@ -0,0 +1,50 @@
+package termimg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+)
+
+const (
+	// kittyChunkSize is the largest payload the Kitty protocol takes per escape.
+	kittyChunkSize = 4096
+
+	// kittyImageID is the ID every image is sent with, so each one replaces
+	// the last instead of piling up in the terminal's memory.
+	kittyImageID = 1
+)
+
+// Kitty encodes an image for the Kitty graphics protocol, drawn over
+// cols×rows cells from the cursor, which is left where it is. The
+// terminal is asked not to reply, since replies would arrive as key presses.
+func Kitty(img image.Image, cols, rows int) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("encoding image: %w", err)
+	}
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var sb strings.Builder
+	for first := true; first || payload != ""; first = false {
+		chunk := payload
+		if len(chunk) > kittyChunkSize {
+			chunk = chunk[:kittyChunkSize]
+		}
+		payload = payload[len(chunk):]
+
+		more := 0
+		if payload != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&sb, "\x1b_Ga=T,f=100,i=%d,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", kittyImageID, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return sb.String(), nil
+}
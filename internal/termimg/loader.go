@@ -0,0 +1,113 @@
+package termimg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register GIF decoding
+	_ "image/jpeg" // Register JPEG decoding
+	_ "image/png"  // Register PNG decoding
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/thomas/eva-terminal-go/internal/cache"
+)
+
+// ErrTooLarge is returned for images bigger than the loader accepts.
+var ErrTooLarge = errors.New("image too large")
+
+// Loader downloads, decodes and renders product images, caching what it
+// renders so sessions browsing the same products share one download.
+// Only the rendered output is kept, not the full-size images.
+type Loader struct {
+	client    *http.Client
+	maxBytes  int64
+	maxPixels int64
+	cache     *cache.Cache[renderKey, string]
+}
+
+// renderKey identifies an image rendered at a size for a protocol.
+type renderKey struct {
+	url        string
+	cols, rows int
+	protocol   Protocol
+}
+
+// NewLoader creates a loader that accepts images of up to maxBytes and
+// maxPixels, and keeps up to maxEntries rendered images for ttl.
+func NewLoader(maxBytes, maxPixels int64, maxEntries int, ttl time.Duration) *Loader {
+	return &Loader{
+		client:    &http.Client{Timeout: 15 * time.Second},
+		maxBytes:  maxBytes,
+		maxPixels: maxPixels,
+		cache:     cache.NewBounded[renderKey, string](ttl, maxEntries),
+	}
+}
+
+// Render returns the image at url drawn in at most cols×rows cells with a
+// protocol, as Render does, loading it if it isn't cached.
+func (l *Loader) Render(ctx context.Context, url string, cols, rows int, protocol Protocol) (string, error) {
+	key := renderKey{url: url, cols: cols, rows: rows, protocol: protocol}
+	if rendered, ok := l.cache.Get(key); ok {
+		return rendered, nil
+	}
+
+	img, err := l.Load(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	rendered, err := Render(img, cols, rows, protocol)
+	if err != nil {
+		return "", err
+	}
+	l.cache.Set(key, rendered)
+	return rendered, nil
+}
+
+// Load downloads and decodes the image at url. JPEG, PNG and GIF images
+// are supported. Images over the pixel limit are refused before they are
+// decoded, since a small file can hold a huge image.
+func (l *Loader) Load(ctx context.Context, url string) (image.Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("downloading image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading image: %s", resp.Status)
+	}
+	if resp.ContentLength > l.maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, resp.ContentLength)
+	}
+
+	// Read one byte past the cap to tell a full image from a cut-off one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, l.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("downloading image: %w", err)
+	}
+	if int64(len(data)) > l.maxBytes {
+		return nil, fmt.Errorf("%w: over %d bytes", ErrTooLarge, l.maxBytes)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+	if int64(config.Width)*int64(config.Height) > l.maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", ErrTooLarge, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+	return img, nil
+}
@@ -0,0 +1,101 @@
+package termimg
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// sixelLevels are the steps of each channel in the 6×6×6 color cube the
+// Sixel output is reduced to.
+var sixelLevels = [6]int{0, 51, 102, 153, 204, 255}
+
+// sixelIndex returns the cube color closest to an 8-bit RGB color.
+func sixelIndex(r, g, b uint8) int {
+	step := func(v uint8) int { return (int(v) + 25) / 51 }
+	return step(r)*36 + step(g)*6 + step(b)
+}
+
+// Sixel encodes an image as DEC Sixel graphics, in the colors of a 6×6×6
+// cube. Transparent pixels are not drawn.
+func Sixel(img image.Image) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// Color index of each pixel, -1 where transparent
+	pixels := make([]int, w*h)
+	used := make(map[int]bool)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c, ok := opaque(img.At(b.Min.X+x, b.Min.Y+y))
+			idx := -1
+			if ok {
+				idx = sixelIndex(c.R, c.G, c.B)
+				used[idx] = true
+			}
+			pixels[y*w+x] = idx
+		}
+	}
+
+	var sb strings.Builder
+	// P2=1: pixels left at 0 stay transparent
+	fmt.Fprintf(&sb, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+	for idx := 0; idx < 216; idx++ {
+		if used[idx] {
+			r, g, bl := sixelLevels[idx/36], sixelLevels[idx/6%6], sixelLevels[idx%6]
+			fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", idx, r*100/255, g*100/255, bl*100/255)
+		}
+	}
+
+	// Six rows of pixels per band; each color of the band is drawn in turn
+	band := make([]byte, w)
+	for top := 0; top < h; top += 6 {
+		if top > 0 {
+			sb.WriteByte('-')
+		}
+		first := true
+		for idx := 0; idx < 216; idx++ {
+			if !used[idx] {
+				continue
+			}
+			found := false
+			for x := 0; x < w; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < h; dy++ {
+					if pixels[(top+dy)*w+x] == idx {
+						bits |= 1 << dy
+					}
+				}
+				band[x] = '?' + bits
+				found = found || bits != 0
+			}
+			if !found {
+				continue
+			}
+			if !first {
+				sb.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&sb, "#%d", idx)
+			writeSixelRun(&sb, band)
+		}
+	}
+	sb.WriteString("\x1b\\")
+	return sb.String()
+}
+
+// writeSixelRun writes a line of sixels, run-length encoded.
+func writeSixelRun(sb *strings.Builder, line []byte) {
+	for i := 0; i < len(line); {
+		j := i
+		for j < len(line) && line[j] == line[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(sb, "!%d%c", n, line[i])
+		} else {
+			sb.Write(line[i:j])
+		}
+		i = j
+	}
+}
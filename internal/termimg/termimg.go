@@ -0,0 +1,185 @@
+// Package termimg draws images in a terminal: as Kitty or Sixel graphics
+// where the terminal supports them, or as colored half-block characters.
+package termimg
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Protocol is a way of drawing images in a terminal.
+type Protocol int
+
+const (
+	ProtocolText       Protocol = iota // No graphics: describe the image instead
+	ProtocolHalfBlocks                 // ▀ characters, two pixels per cell, in 24-bit color
+	ProtocolSixel                      // DEC Sixel graphics
+	ProtocolKitty                      // Kitty graphics protocol
+)
+
+// String returns the protocol name.
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolHalfBlocks:
+		return "half-blocks"
+	case ProtocolSixel:
+		return "sixel"
+	case ProtocolKitty:
+		return "kitty"
+	}
+	return "text"
+}
+
+// Cell size in pixels assumed for the graphics protocols. Terminals vary;
+// on most the image comes out a little smaller than the cells it's given.
+const (
+	cellWidth  = 10
+	cellHeight = 20
+)
+
+// DetectProtocol picks the best protocol for a TERM value. SSH clients
+// send only TERM, so terminals are recognised by the names they set.
+func DetectProtocol(term string) Protocol {
+	term = strings.ToLower(term)
+	switch {
+	case term == "" || term == "dumb" || term == "unknown" || strings.HasPrefix(term, "vt1"):
+		return ProtocolText
+	case strings.Contains(term, "kitty"), strings.Contains(term, "ghostty"), strings.Contains(term, "wezterm"):
+		return ProtocolKitty
+	case strings.Contains(term, "sixel"), strings.HasPrefix(term, "mlterm"), strings.HasPrefix(term, "foot"),
+		strings.HasPrefix(term, "contour"), strings.HasPrefix(term, "yaft"):
+		return ProtocolSixel
+	}
+	return ProtocolHalfBlocks
+}
+
+// IsGraphics reports whether a protocol draws pixels rather than text.
+func (p Protocol) IsGraphics() bool {
+	return p == ProtocolSixel || p == ProtocolKitty
+}
+
+// Render draws an image in at most cols×rows terminal cells. Half-blocks
+// come out as rows lines of text. Graphics come out as one escape sequence
+// drawing from the cursor, which is best sent with Place.
+func Render(img image.Image, cols, rows int, protocol Protocol) (string, error) {
+	if cols <= 0 || rows <= 0 {
+		return "", fmt.Errorf("rendering image: no room for it (%dx%d cells)", cols, rows)
+	}
+
+	switch protocol {
+	case ProtocolHalfBlocks:
+		return HalfBlocks(Scale(img, cols, rows*2), rows), nil
+	case ProtocolSixel:
+		return Sixel(Scale(img, cols*cellWidth, rows*cellHeight)), nil
+	case ProtocolKitty:
+		scaled := Scale(img, cols*cellWidth, rows*cellHeight)
+		b := scaled.Bounds()
+		return Kitty(scaled, (b.Dx()+cellWidth-1)/cellWidth, (b.Dy()+cellHeight-1)/cellHeight)
+	}
+	return "", fmt.Errorf("rendering image: %s terminals can't show images", protocol)
+}
+
+// Place wraps graphics so they are drawn up lines above the cursor's line,
+// at column col (0-based), and the cursor is put back afterwards. Sent
+// after the text they cover, graphics aren't erased by it.
+func Place(graphics string, up, col int) string {
+	var sb strings.Builder
+	sb.WriteString("\x1b7\r") // Save the cursor
+	if up > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dA", up)
+	}
+	if col > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dC", col)
+	}
+	sb.WriteString(graphics)
+	sb.WriteString("\x1b8") // Restore it
+	return sb.String()
+}
+
+// Clear removes the images drawn with a protocol that keeps them on screen
+// apart from the text, or returns "" for the others.
+func Clear(protocol Protocol) string {
+	if protocol == ProtocolKitty {
+		return fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", kittyImageID)
+	}
+	return ""
+}
+
+// Scale shrinks an image to fit in width×height pixels, keeping its aspect
+// ratio, by averaging the pixels each new pixel covers. Smaller images are
+// left as they are.
+func Scale(img image.Image, width, height int) *image.RGBA {
+	src := img.Bounds()
+	w, h := src.Dx(), src.Dy()
+	if w > width {
+		h = max(h*width/w, 1)
+		w = width
+	}
+	if h > height {
+		w = max(w*height/h, 1)
+		h = height
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := src.Min.Y + y*src.Dy()/h
+		y1 := max(src.Min.Y+(y+1)*src.Dy()/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := src.Min.X + x*src.Dx()/w
+			x1 := max(src.Min.X+(x+1)*src.Dx()/w, x0+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// HalfBlocks draws an image as rows lines of ▀ characters: the foreground
+// color is the upper pixel, the background the lower one. Transparent
+// pixels are left to the terminal's background.
+func HalfBlocks(img image.Image, rows int) string {
+	b := img.Bounds()
+	var sb strings.Builder
+	for row := 0; row < rows; row++ {
+		if row > 0 {
+			sb.WriteString("\n")
+		}
+		y := b.Min.Y + row*2
+		for x := b.Min.X; x < b.Max.X && y < b.Max.Y; x++ {
+			top, topOK := opaque(img.At(x, y))
+			bottom, bottomOK := color.RGBA{}, false
+			if y+1 < b.Max.Y {
+				bottom, bottomOK = opaque(img.At(x, y+1))
+			}
+
+			switch {
+			case topOK && bottomOK:
+				fmt.Fprintf(&sb, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			case topOK:
+				fmt.Fprintf(&sb, "\x1b[49m\x1b[38;2;%d;%d;%dm▀", top.R, top.G, top.B)
+			case bottomOK:
+				fmt.Fprintf(&sb, "\x1b[49m\x1b[38;2;%d;%d;%dm▄", bottom.R, bottom.G, bottom.B)
+			default:
+				sb.WriteString("\x1b[0m ")
+			}
+		}
+		sb.WriteString("\x1b[0m")
+	}
+	return sb.String()
+}
+
+// opaque returns a pixel's color, and false if it is mostly transparent.
+func opaque(c color.Color) (color.RGBA, bool) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return color.RGBA{R: n.R, G: n.G, B: n.B, A: 255}, n.A >= 128
+}
@@ -0,0 +1,231 @@
+package termimg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testImage returns a w×h image, red on the left half and blue on the right.
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestDetectProtocol(t *testing.T) {
+	tests := []struct {
+		term string
+		want Protocol
+	}{
+		{"", ProtocolText},
+		{"dumb", ProtocolText},
+		{"vt100", ProtocolText},
+		{"xterm-256color", ProtocolHalfBlocks},
+		{"screen", ProtocolHalfBlocks},
+		{"xterm-kitty", ProtocolKitty},
+		{"wezterm", ProtocolKitty},
+		{"xterm-ghostty", ProtocolKitty},
+		{"foot", ProtocolSixel},
+		{"mlterm", ProtocolSixel},
+		{"xterm-sixel", ProtocolSixel},
+	}
+	for _, tt := range tests {
+		if got := DetectProtocol(tt.term); got != tt.want {
+			t.Errorf("DetectProtocol(%q) = %s, want %s", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	img := Scale(testImage(100, 50), 20, 20)
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 10 {
+		t.Fatalf("expected 20x10, got %dx%d", b.Dx(), b.Dy())
+	}
+	if c := img.RGBAAt(0, 0); c.R != 255 || c.B != 0 {
+		t.Errorf("expected red on the left, got %v", c)
+	}
+	if c := img.RGBAAt(19, 9); c.B != 255 || c.R != 0 {
+		t.Errorf("expected blue on the right, got %v", c)
+	}
+
+	// Small images aren't enlarged
+	img = Scale(testImage(4, 2), 20, 20)
+	if b := img.Bounds(); b.Dx() != 4 || b.Dy() != 2 {
+		t.Errorf("expected 4x2, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestRenderHalfBlocks(t *testing.T) {
+	out, err := Render(testImage(40, 32), 10, 4, ProtocolHalfBlocks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(out, "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if n := strings.Count(line, "▀"); n != 10 {
+			t.Errorf("line %d: expected 10 cells, got %d", i, n)
+		}
+		if !strings.HasSuffix(line, "\x1b[0m") {
+			t.Errorf("line %d: expected colors reset at the end", i)
+		}
+	}
+	if !strings.Contains(lines[0], "\x1b[38;2;255;0;0m\x1b[48;2;255;0;0m▀") {
+		t.Errorf("expected red cells, got %q", lines[0])
+	}
+	if !strings.Contains(lines[0], "\x1b[38;2;0;0;255m\x1b[48;2;0;0;255m▀") {
+		t.Errorf("expected blue cells, got %q", lines[0])
+	}
+
+	if _, err := Render(testImage(4, 4), 10, 4, ProtocolText); err == nil {
+		t.Error("expected error rendering for a text terminal")
+	}
+}
+
+func TestPlace(t *testing.T) {
+	got := Place("IMG", 3, 6)
+	if want := "\x1b7\r\x1b[3A\x1b[6CIMG\x1b8"; got != want {
+		t.Errorf("Place = %q, want %q", got, want)
+	}
+	if got := Place("IMG", 0, 0); got != "\x1b7\rIMG\x1b8" {
+		t.Errorf("Place without moves = %q", got)
+	}
+
+	if Clear(ProtocolKitty) == "" {
+		t.Error("expected Kitty images to need clearing")
+	}
+	if Clear(ProtocolSixel) != "" || Clear(ProtocolHalfBlocks) != "" {
+		t.Error("expected only Kitty images to need clearing")
+	}
+}
+
+func TestSixel(t *testing.T) {
+	out := Sixel(testImage(8, 6))
+	if !strings.HasPrefix(out, "\x1bP0;1;0q\"1;1;8;6") || !strings.HasSuffix(out, "\x1b\\") {
+		t.Fatalf("expected a Sixel sequence, got %q", out)
+	}
+	// Red is cube color 5*36, blue 5; each fills the band in 4 columns
+	if !strings.Contains(out, "#180;2;100;0;0") || !strings.Contains(out, "#5;2;0;0;100") {
+		t.Errorf("expected red and blue in the palette, got %q", out)
+	}
+	if !strings.Contains(out, "#5!4?!4~$#180!4~!4?") {
+		t.Errorf("expected blue and red runs, got %q", out)
+	}
+}
+
+func TestKitty(t *testing.T) {
+	out, err := Kitty(testImage(200, 200), 10, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out, "\x1b_Ga=T,f=100,i=1,q=2,C=1,c=10,r=5,m=") {
+		t.Errorf("expected Kitty header, got %q", out[:40])
+	}
+	for _, chunk := range strings.SplitAfter(out, "\x1b\\") {
+		if chunk == "" {
+			continue
+		}
+		payload := chunk[strings.Index(chunk, ";")+1 : len(chunk)-2]
+		if len(payload) > kittyChunkSize {
+			t.Errorf("chunk of %d bytes exceeds %d", len(payload), kittyChunkSize)
+		}
+	}
+	if !strings.HasSuffix(out, "m=0;"+out[strings.LastIndex(out, ";")+1:]) {
+		t.Error("expected last chunk to end the transfer")
+	}
+}
+
+func TestLoader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(16, 16)); err != nil {
+		t.Fatal(err)
+	}
+	// Small to download, but too many pixels to decode
+	var huge bytes.Buffer
+	if err := png.Encode(&huge, image.NewGray(image.Rect(0, 0, 100, 100))); err != nil {
+		t.Fatal(err)
+	}
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/mug.png":
+			w.Write(buf.Bytes())
+		case "/huge.png":
+			w.Write(huge.Bytes())
+		case "/big.png":
+			// No Content-Length, so the cap is enforced while reading
+			w.(http.Flusher).Flush()
+			w.Write(bytes.Repeat([]byte{0}, 4096))
+		case "/notes.txt":
+			w.Write([]byte("not an image"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	l := NewLoader(2048, 1000, 2, time.Minute)
+	ctx := context.Background()
+
+	img, err := l.Load(ctx, server.URL+"/mug.png")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 16 || b.Dy() != 16 {
+		t.Errorf("expected 16x16, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	// Rendered images are cached per size and protocol
+	requests.Store(0)
+	first, err := l.Render(ctx, server.URL+"/mug.png", 4, 2, ProtocolHalfBlocks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again, err := l.Render(ctx, server.URL+"/mug.png", 4, 2, ProtocolHalfBlocks); err != nil || again != first {
+		t.Fatalf("expected the same rendering, got %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected cached rendering to be reused, got %d requests", n)
+	}
+	if _, err := l.Render(ctx, server.URL+"/mug.png", 8, 4, ProtocolHalfBlocks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected another size to be rendered again, got %d requests", n)
+	}
+
+	if _, err := l.Load(ctx, server.URL+"/big.png"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+	if _, err := l.Load(ctx, server.URL+"/huge.png"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge for too many pixels, got %v", err)
+	}
+	if _, err := l.Load(ctx, server.URL+"/notes.txt"); err == nil {
+		t.Error("expected error decoding a non-image")
+	}
+	if _, err := l.Load(ctx, server.URL+"/missing.png"); err == nil {
+		t.Error("expected error for a missing image")
+	}
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/thomas/eva-terminal-go/internal/carts"
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// CartStore keeps each SSH key's cart between sessions, so a dropped
+// connection doesn't lose it. It is shared by all sessions.
+type CartStore interface {
+	// Load returns the saved cart, or nil if there is none.
+	Load(fingerprint string) (*carts.Cart, error)
+	// Save replaces the saved cart; an empty cart removes it.
+	Save(fingerprint string, cart carts.Cart) error
+}
+
+// cartSaver writes a session's cart off the update loop. Saves run one at
+// a time, and one older than the last written is dropped, so the store
+// always ends up with the latest cart.
+type cartSaver struct {
+	store   CartStore
+	mu      sync.Mutex
+	written int // Sequence number of the last cart written
+	pending sync.WaitGroup
+}
+
+// save starts writing the cart numbered seq, unless a later one is
+// written first, and returns a channel receiving the result.
+func (s *cartSaver) save(fingerprint string, seq int, cart carts.Cart) <-chan error {
+	done := make(chan error, 1)
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if seq <= s.written {
+			done <- nil
+			return
+		}
+		s.written = seq
+		done <- s.store.Save(fingerprint, cart)
+	}()
+	return done
+}
+
+// wait blocks until the saves started so far are done.
+func (s *cartSaver) wait() {
+	s.pending.Wait()
+}
+
+// sameCart reports whether two snapshots hold the same cart, whenever
+// they were taken.
+func sameCart(a, b carts.Cart) bool {
+	a.UpdatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	return reflect.DeepEqual(a, b)
+}
+
+// Snapshot returns the items and coupon of the cart for saving.
+func (c *LocalCart) Snapshot(now time.Time) carts.Cart {
+	saved := carts.Cart{
+		Currency:  c.Settings.Currency,
+		Items:     make([]carts.Item, len(c.Items)),
+		Coupon:    c.Coupon,
+		UpdatedAt: now,
+	}
+	for i, item := range c.Items {
+		saved.Items[i] = carts.Item{
+			ProductID:   item.ProductID,
+			VariationID: item.VariationID,
+			Name:        item.Name,
+			Price:       item.Price.String(),
+			Quantity:    item.Quantity,
+			GrindSize:   item.GrindSize,
+			TaxClass:    item.TaxClass,
+			TaxStatus:   item.TaxStatus,
+			CategoryIDs: item.CategoryIDs,
+			OnSale:      item.OnSale,
+			Stock:       carts.Stock{Status: item.Stock.Status, Quantity: item.Stock.Quantity, Backorders: item.Stock.Backorders},
+			Meta:        item.Meta,
+		}
+	}
+	return saved
+}
+
+// RestoreLocalCart rebuilds a cart saved with Snapshot. A cart saved in
+// another currency is dropped, since its prices no longer apply, as are
+// items without a valid price or quantity.
+func RestoreLocalCart(saved *carts.Cart, settings woo.StoreSettings) *LocalCart {
+	cart := NewLocalCart(settings)
+	if saved == nil || saved.Currency != settings.Currency {
+		return cart
+	}
+
+	for _, item := range saved.Items {
+		price, err := woo.ParseMoney(item.Price, settings.Currency)
+		if err != nil || item.Quantity <= 0 {
+			continue
+		}
+		cart.Items = append(cart.Items, LocalCartItem{
+			ProductID:   item.ProductID,
+			VariationID: item.VariationID,
+			Name:        item.Name,
+			Price:       price,
+			Quantity:    item.Quantity,
+			GrindSize:   item.GrindSize,
+			TaxClass:    item.TaxClass,
+			TaxStatus:   item.TaxStatus,
+			CategoryIDs: item.CategoryIDs,
+			OnSale:      item.OnSale,
+			Stock:       Stock{Status: item.Stock.Status, Quantity: item.Stock.Quantity, Backorders: item.Stock.Backorders},
+			Meta:        item.Meta,
+		})
+	}
+	if len(cart.Items) > 0 {
+		// Checked again against the items before checkout
+		cart.Coupon = saved.Coupon
+	}
+
+	return cart
+}
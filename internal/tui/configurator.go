@@ -0,0 +1,235 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// configChoices holds what has been picked in the configurator. The form's
+// selects write straight into it, so it is kept behind a pointer that
+// survives the Model being copied on every update.
+type configChoices struct {
+	Attributes []woo.Attribute // Attributes the variations differ by, in display order
+	Options    []string        // Option picked for each attribute
+	GrindSize  string
+	Quantity   string
+}
+
+// variationAttributes returns the attributes a product's variations
+// differ by, in the order the shop lists them.
+func variationAttributes(p *woo.Product) []woo.Attribute {
+	var attrs []woo.Attribute
+	for _, attr := range p.Attributes {
+		if attr.Variation && len(attr.Options) > 0 {
+			attrs = append(attrs, attr)
+		}
+	}
+	sort.SliceStable(attrs, func(i, j int) bool { return attrs[i].Position < attrs[j].Position })
+	return attrs
+}
+
+// grindOptions returns the grind sizes offered for a product, or nil if it
+// doesn't offer a choice. A grind size the variations differ by is picked
+// like any other attribute instead.
+func grindOptions(p *woo.Product) []string {
+	attr := p.GetAttribute("Grind Size")
+	if attr == nil || attr.Variation {
+		return nil
+	}
+	return attr.Options
+}
+
+// variationOption returns a variation's option for an attribute, or "" if
+// the variation is sold with any option ("Any Size…" in WooCommerce).
+// Global attributes are matched by ID, custom ones by name.
+func variationOption(v *woo.Variation, attr woo.Attribute) string {
+	for _, va := range v.Attributes {
+		if va.ID != attr.ID {
+			continue
+		}
+		if attr.ID != 0 || strings.EqualFold(va.Name, attr.Name) {
+			return va.Option
+		}
+	}
+	return ""
+}
+
+// variationMatches reports whether a variation is sold with the given
+// options. An empty option matches anything.
+func variationMatches(v *woo.Variation, attrs []woo.Attribute, options []string) bool {
+	for i, attr := range attrs {
+		opt := variationOption(v, attr)
+		if opt != "" && options[i] != "" && !strings.EqualFold(opt, options[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchVariation finds the variation sold for a set of options, or nil if
+// there is none. A variation naming an option wins over one accepting any.
+// Unpublished variations aren't sold.
+func matchVariation(variations []woo.Variation, attrs []woo.Attribute, options []string) *woo.Variation {
+	var match *woo.Variation
+	best := -1
+	for i := range variations {
+		v := &variations[i]
+		if !variationPublished(v) || !variationMatches(v, attrs, options) {
+			continue
+		}
+		specific := 0
+		for _, attr := range attrs {
+			if variationOption(v, attr) != "" {
+				specific++
+			}
+		}
+		if specific > best {
+			match, best = v, specific
+		}
+	}
+	return match
+}
+
+// variationPublished reports whether the shop sells a variation. Disabled
+// variations are "private"; stores that don't send the status publish all.
+func variationPublished(v *woo.Variation) bool {
+	return v.Status == "" || v.Status == "publish"
+}
+
+// variationAvailable reports whether a variation can be ordered.
+func variationAvailable(v *woo.Variation) bool {
+	return variationPublished(v) && (v.IsInStock() || v.StockStatus == "onbackorder")
+}
+
+// optionVariations returns the variations that can be ordered with an
+// option of the attribute at index i, whatever the other attributes.
+func optionVariations(variations []woo.Variation, attrs []woo.Attribute, i int, option string) []woo.Variation {
+	options := make([]string, len(attrs))
+	options[i] = option
+
+	var matches []woo.Variation
+	for j := range variations {
+		if variationAvailable(&variations[j]) && variationMatches(&variations[j], attrs, options) {
+			matches = append(matches, variations[j])
+		}
+	}
+	return matches
+}
+
+// combinationError checks the options picked for the attributes up to
+// index i can be ordered together, whatever is picked for those after.
+// Once the last attribute is picked the options must name a variation
+// that can be ordered.
+func combinationError(variations []woo.Variation, attrs []woo.Attribute, options []string, i int) error {
+	picked := make([]string, len(attrs))
+	copy(picked[:i+1], options[:i+1])
+	if i == len(attrs)-1 {
+		v := matchVariation(variations, attrs, picked)
+		if v == nil {
+			return errors.New("this combination isn't available")
+		}
+		if !variationAvailable(v) {
+			return errors.New("this combination is out of stock")
+		}
+		return nil
+	}
+	for j := range variations {
+		if variationAvailable(&variations[j]) && variationMatches(&variations[j], attrs, picked) {
+			return nil
+		}
+	}
+	return errors.New("this combination is out of stock")
+}
+
+// optionLabel labels an option of the attribute at index i with the price
+// of the variations it can be ordered as, or as out of stock if there are none.
+func optionLabel(settings woo.StoreSettings, variations []woo.Variation, attrs []woo.Attribute, i int, option string) string {
+	matches := optionVariations(variations, attrs, i, option)
+	if len(matches) == 0 {
+		return option + " — out of stock"
+	}
+
+	var lowest woo.Money
+	varies := false
+	for j, v := range matches {
+		price, err := settings.ParsePrice(v.GetDisplayPrice())
+		if err != nil {
+			return option
+		}
+		if j == 0 {
+			lowest = price
+			continue
+		}
+		// Every price is parsed in the store currency
+		if c, _ := price.Cmp(lowest); c != 0 {
+			varies = true
+			if c < 0 {
+				lowest = price
+			}
+		}
+	}
+	if varies {
+		return fmt.Sprintf("%s (from %s)", option, settings.Format(lowest))
+	}
+	return fmt.Sprintf("%s (%s)", option, settings.Format(lowest))
+}
+
+// variationStock describes a variation's stock for the configurator.
+func variationStock(v *woo.Variation) string {
+	switch {
+	case v.StockStatus == "onbackorder":
+		return "Available on backorder"
+	case !v.IsInStock():
+		return "Out of stock"
+	case v.StockQuantity != nil:
+		return fmt.Sprintf("%d in stock", *v.StockQuantity)
+	}
+	return "In stock"
+}
+
+// variationName names a configured variation after the product and the
+// options picked, e.g. "House Blend (1kg, Dark)".
+func variationName(product *woo.Product, options []string) string {
+	var picked []string
+	for _, opt := range options {
+		if opt != "" {
+			picked = append(picked, opt)
+		}
+	}
+	if len(picked) == 0 {
+		return product.Name
+	}
+	return fmt.Sprintf("%s (%s)", product.Name, strings.Join(picked, ", "))
+}
+
+// parseQuantity parses the quantity entered in the configurator.
+func parseQuantity(s string) (int, error) {
+	quantity, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || quantity < 1 {
+		return 0, errors.New("enter a quantity of 1 or more")
+	}
+	return quantity, nil
+}
+
+// quantityError checks a quantity against the stock, counting the units
+// already in the cart, and says how many more can be added if it doesn't fit.
+func quantityError(stock Stock, inCart, quantity int) error {
+	limit, ok := stock.Limit()
+	if !ok || inCart+quantity <= limit {
+		return nil
+	}
+	switch {
+	case limit == 0:
+		return errors.New("out of stock")
+	case inCart == 0:
+		return fmt.Errorf("only %d in stock", limit)
+	case inCart >= limit:
+		return fmt.Errorf("only %d in stock, all in your cart already", limit)
+	}
+	return fmt.Errorf("only %d in stock, %d in your cart already", limit, inCart)
+}
@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// A mug sold in two sizes and three colours: the small one in any colour,
+// the large one in red (sold out) and blue only.
+var (
+	mugAttributes = []woo.Attribute{
+		{ID: 0, Name: "Colour", Position: 1, Variation: true, Options: []string{"Red", "Blue", "Green"}},
+		{ID: 3, Name: "Size", Position: 0, Variation: true, Options: []string{"Small", "Large"}},
+		{ID: 4, Name: "Material", Position: 2, Options: []string{"Stoneware"}},
+	}
+	mugVariations = []woo.Variation{
+		{ID: 11, Price: "12.00", StockStatus: "instock", Attributes: []woo.VariationAttribute{{ID: 3, Name: "Size", Option: "Small"}}},
+		{ID: 12, Price: "18.00", StockStatus: "outofstock", Attributes: []woo.VariationAttribute{{ID: 3, Name: "Size", Option: "Large"}, {Name: "Colour", Option: "Red"}}},
+		{ID: 13, Price: "18.00", StockStatus: "instock", Attributes: []woo.VariationAttribute{{ID: 3, Name: "Size", Option: "Large"}, {Name: "Colour", Option: "blue"}}},
+	}
+)
+
+func TestVariationAttributes(t *testing.T) {
+	attrs := variationAttributes(&woo.Product{Attributes: mugAttributes})
+	if len(attrs) != 2 || attrs[0].Name != "Size" || attrs[1].Name != "Colour" {
+		t.Errorf("expected Size then Colour, got %+v", attrs)
+	}
+}
+
+func TestMatchVariation(t *testing.T) {
+	attrs := variationAttributes(&woo.Product{Attributes: mugAttributes})
+
+	tests := []struct {
+		name    string
+		options []string
+		wantID  int
+	}{
+		{"any colour", []string{"Small", "Green"}, 11},
+		{"exact match", []string{"Large", "Red"}, 12},
+		{"case-insensitive", []string{"Large", "Blue"}, 13},
+		{"not sold", []string{"Large", "Green"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := matchVariation(mugVariations, attrs, tt.options)
+			if tt.wantID == 0 {
+				if v != nil {
+					t.Errorf("expected no variation, got %d", v.ID)
+				}
+				return
+			}
+			if v == nil || v.ID != tt.wantID {
+				t.Errorf("expected variation %d, got %+v", tt.wantID, v)
+			}
+		})
+	}
+
+	// A variation naming the option wins over one accepting any
+	anyFirst := append([]woo.Variation{{ID: 10, Price: "9.00", StockStatus: "instock"}}, mugVariations...)
+	if v := matchVariation(anyFirst, attrs, []string{"Large", "Blue"}); v == nil || v.ID != 13 {
+		t.Errorf("expected the blue large mug, got %+v", v)
+	}
+
+	// Unpublished variations aren't sold, even in stock
+	disabled := append([]woo.Variation(nil), mugVariations...)
+	disabled[2].Status = "private"
+	if v := matchVariation(disabled, attrs, []string{"Large", "Blue"}); v != nil {
+		t.Errorf("expected no variation for the disabled blue mug, got %d", v.ID)
+	}
+	if err := combinationError(disabled, attrs, []string{"Large", "Blue"}, 0); err == nil {
+		t.Error("expected no large mug to be left on sale")
+	}
+}
+
+func TestCombinationError(t *testing.T) {
+	attrs := []woo.Attribute{
+		{ID: 3, Name: "Size", Variation: true, Options: []string{"Small", "Large"}},
+		{Name: "Colour", Variation: true, Options: []string{"Red", "Blue", "Green"}},
+		{Name: "Glaze", Variation: true, Options: []string{"Matte", "Gloss"}},
+	}
+	variations := []woo.Variation{
+		{ID: 21, StockStatus: "instock", Attributes: []woo.VariationAttribute{{ID: 3, Name: "Size", Option: "Large"}, {Name: "Colour", Option: "Red"}, {Name: "Glaze", Option: "Matte"}}},
+		{ID: 22, StockStatus: "outofstock", Attributes: []woo.VariationAttribute{{ID: 3, Name: "Size", Option: "Large"}, {Name: "Colour", Option: "Blue"}, {Name: "Glaze", Option: "Gloss"}}},
+		{ID: 23, StockStatus: "instock", Attributes: []woo.VariationAttribute{{ID: 3, Name: "Size", Option: "Small"}, {Name: "Glaze", Option: "Gloss"}}},
+	}
+
+	tests := []struct {
+		name    string
+		options []string
+		i       int
+		wantErr string
+	}{
+		{"first pick", []string{"Large", "Blue", "Gloss"}, 0, ""},
+		{"sold out so far", []string{"Large", "Blue", "Gloss"}, 1, "this combination is out of stock"},
+		{"later picks ignored", []string{"Large", "Red", "Gloss"}, 1, ""},
+		{"any colour", []string{"Small", "Green", "Matte"}, 1, ""},
+		{"not sold", []string{"Large", "Red", "Gloss"}, 2, "this combination isn't available"},
+		{"complete", []string{"Large", "Red", "Matte"}, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := combinationError(variations, attrs, tt.options, tt.i)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("expected %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOptionLabel(t *testing.T) {
+	settings := woo.DefaultStoreSettings()
+	attrs := variationAttributes(&woo.Product{Attributes: mugAttributes})
+
+	if label := optionLabel(settings, mugVariations, attrs, 0, "Small"); label != "Small ($12.00)" {
+		t.Errorf("unexpected label %q", label)
+	}
+	// Red comes small at 12.00 or large (sold out); blue also large at 18.00
+	if label := optionLabel(settings, mugVariations, attrs, 1, "Blue"); label != "Blue (from $12.00)" {
+		t.Errorf("unexpected label %q", label)
+	}
+
+	soldOut := []woo.Variation{mugVariations[1]}
+	if label := optionLabel(settings, soldOut, attrs, 1, "Red"); !strings.Contains(label, "out of stock") {
+		t.Errorf("expected Red to be out of stock, got %q", label)
+	}
+}
+
+func TestVariationStock(t *testing.T) {
+	three := 3
+	tests := []struct {
+		variation woo.Variation
+		want      string
+	}{
+		{woo.Variation{StockStatus: "instock"}, "In stock"},
+		{woo.Variation{StockStatus: "instock", StockQuantity: &three}, "3 in stock"},
+		{woo.Variation{StockStatus: "onbackorder"}, "Available on backorder"},
+		{woo.Variation{StockStatus: "outofstock"}, "Out of stock"},
+	}
+	for _, tt := range tests {
+		if got := variationStock(&tt.variation); got != tt.want {
+			t.Errorf("variationStock(%+v) = %q, want %q", tt.variation, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// userMessage turns an error into a message suitable for customers.
+// WooCommerce API errors are mapped to friendly text instead of raw JSON.
+func userMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	switch {
+	case errors.Is(err, woo.ErrCouponNotFound):
+		return "That coupon code doesn't exist."
+	case errors.Is(err, woo.ErrCouponExpired):
+		return "This coupon has expired."
+	case errors.Is(err, woo.ErrCouponUsageLimit):
+		return "This coupon has been used up."
+	case errors.Is(err, woo.ErrCouponMinimumSpend):
+		return "Your cart doesn't reach the minimum spend for this coupon."
+	case errors.Is(err, woo.ErrCouponMaximumSpend):
+		return "Your cart is over the maximum spend for this coupon."
+	case errors.Is(err, woo.ErrCouponNotApplicable):
+		return "This coupon doesn't apply to the items in your cart."
+	case errors.Is(err, woo.ErrEmailExists):
+		return "This email already has an account at the shop, so the order was placed as a guest."
+	case errors.Is(err, woo.ErrOutOfStock):
+		return "This coffee just sold out. Please update your cart and try again."
+	case errors.Is(err, woo.ErrNotFound):
+		return "This item is no longer available."
+	case errors.Is(err, woo.ErrUnauthorized):
+		return "The shop refused our request. Please try again later."
+	case errors.Is(err, woo.ErrInvalidMoney):
+		return "This item has no valid price and can't be ordered right now."
+	case errors.Is(err, context.DeadlineExceeded):
+		return "The shop took too long to respond. Please try again."
+	}
+
+	var stockErr *StockLimitError
+	if errors.As(err, &stockErr) {
+		if stockErr.Available == 0 {
+			return fmt.Sprintf("%s is out of stock.", stockErr.Name)
+		}
+		return fmt.Sprintf("Only %d of %s in stock.", stockErr.Available, stockErr.Name)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return "Could not reach the shop. Please check back in a moment."
+	}
+
+	var apiErr *woo.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Message != "" {
+			return StripHTML(apiErr.Message)
+		}
+		return "The shop is having trouble right now. Please try again."
+	}
+
+	return err.Error()
+}
@@ -1,13 +1,17 @@
 package tui
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
 	"golang.org/x/net/html"
 )
 
 // StripHTML removes HTML tags from a string and converts it to plain text.
-// It uses the golang.org/x/net/html tokenizer for safe parsing.
+// It uses the golang.org/x/net/html tokenizer for safe parsing, which also
+// decodes entities.
 func StripHTML(s string) string {
 	if s == "" {
 		return ""
@@ -26,7 +30,7 @@ func StripHTML(s string) string {
 
 		case html.TextToken:
 			text := string(tokenizer.Text())
-			result.WriteString(text)
+			result.WriteString(strings.ReplaceAll(stripControl(text), "\u00a0", " "))
 
 		case html.StartTagToken, html.SelfClosingTagToken:
 			tn, _ := tokenizer.TagName()
@@ -70,38 +74,581 @@ func cleanupWhitespace(s string) string {
 	}
 
 	// Join with single newlines
-	result := strings.Join(cleanLines, "\n")
+	return strings.Join(cleanLines, "\n")
+}
+
+// stripControl removes control characters other than whitespace, so text
+// from the shop can't send escape sequences to the terminal.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r < 0x20 && !isHTMLSpace(r)) || (r >= 0x7f && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// isHTMLSpace reports whether r is whitespace HTML collapses. Non-breaking
+// spaces are not.
+func isHTMLSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
+
+// RenderHTML renders WooCommerce HTML, such as a product description, as
+// styled terminal text wrapped to width cells. It keeps emphasis, headings,
+// bulleted and numbered lists, links (as OSC 8 hyperlinks), blockquotes and
+// simple tables. Entities are decoded by the tokenizer.
+func RenderHTML(s string, width int, styles Styles) string {
+	r := &htmlRenderer{styles: styles, width: max(width, 20)}
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			// End of document or error; close what is left open
+			if r.table != nil {
+				r.endTable()
+			}
+			r.flush()
+			return strings.Join(r.lines, "\n")
+
+		case html.TextToken:
+			r.text(string(tokenizer.Text()))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tn, hasAttr := tokenizer.TagName()
+			attrs := make(map[string]string)
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = tokenizer.TagAttr()
+				attrs[string(key)] = string(val)
+			}
+			r.start(string(tn), attrs)
+
+		case html.EndTagToken:
+			tn, _ := tokenizer.TagName()
+			r.end(string(tn))
+		}
+	}
+}
+
+// htmlWord is a word of rendered text, or a line break.
+type htmlWord struct {
+	text  string // Styled
+	width int    // In cells
+	br    bool
+}
+
+// htmlList is a list being rendered.
+type htmlList struct {
+	ordered bool
+	next    int // Number of the next item
+	indent  int // Column the markers start at
+	content int // Column the current item's text starts at
+}
+
+// htmlTable is a table being rendered. Cells are laid out once the table
+// ends, when the widths of the columns are known.
+type htmlTable struct {
+	rows   [][][]htmlWord
+	header []bool // Whether each row is a header row: in <thead> or all <th>
+	inHead bool   // Inside <thead>
+	inCell bool
+	thCell bool // The open cell is a <th>
+	nested int  // Depth of tables open inside a cell
+}
+
+// htmlRenderer turns a stream of HTML tokens into lines of terminal text.
+// Inline content is collected as words and wrapped when its block ends.
+type htmlRenderer struct {
+	styles Styles
+	width  int
+	lines  []string
+
+	// Blank line before the next block, drawn with gapQuotes quote bars
+	gap       bool
+	gapQuotes int
+
+	// Inline content of the current block
+	words   []htmlWord
+	space   bool // Whitespace before the next text
+	heading bool
+
+	// Open inline elements
+	bold, italic, underline, strike, code int
+	link                                  string // Target of the open <a>
+	skip                                  int    // Inside <script> or <style>
+
+	lists  []htmlList
+	marker string // Marker of a list item not drawn yet
+	quotes int    // Blockquote depth
+	table  *htmlTable
+}
+
+func (r *htmlRenderer) start(tag string, attrs map[string]string) {
+	if r.table != nil && r.table.inCell {
+		// Cells hold inline content only; blocks in them run together
+		switch tag {
+		case "p", "div", "br", "li", "ul", "ol", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "hr":
+			r.space = true
+			return
+		}
+	}
+
+	switch tag {
+	case "script", "style":
+		r.skip++
+
+	case "b", "strong":
+		r.bold++
+	case "i", "em", "cite", "dfn":
+		r.italic++
+	case "u", "ins":
+		r.underline++
+	case "s", "del", "strike":
+		r.strike++
+	case "code", "kbd", "samp", "tt":
+		r.code++
+	case "a":
+		r.link = stripControl(attrs["href"])
+	case "img":
+		// Alt text stands in for the image, e.g. the emoji WordPress swaps for images
+		if alt := strings.TrimSpace(attrs["alt"]); alt != "" {
+			r.text(alt)
+		}
+
+	case "br":
+		if len(r.words) > 0 {
+			r.words = append(r.words, htmlWord{br: true})
+		}
+		r.space = false
+
+	case "p", "pre", "section", "article", "header", "footer", "figure", "figcaption", "address", "dl":
+		r.block()
+	case "div", "dt", "dd":
+		r.flush()
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		r.block()
+		r.heading = true
+	case "hr":
+		r.block()
+		avail := r.width - r.indent() - lipgloss.Width(r.quotePrefix())
+		r.emit(r.styles.DescMarker.Render(strings.Repeat("─", max(avail, 3))))
+		r.breakBlock()
+
+	case "blockquote":
+		r.block()
+		r.quotes++
+
+	case "ul", "ol":
+		if r.marker != "" && len(r.words) == 0 {
+			// An item holding only a list still gets its marker
+			r.emit("")
+		}
+		r.flush()
+		if len(r.lists) == 0 {
+			r.breakBlock()
+		}
+		l := htmlList{ordered: tag == "ol", next: 1, indent: 2}
+		if n, err := strconv.Atoi(attrs["start"]); err == nil && l.ordered {
+			l.next = n
+		}
+		if len(r.lists) > 0 {
+			l.indent = r.lists[len(r.lists)-1].content
+		}
+		r.lists = append(r.lists, l)
+	case "li":
+		r.flush()
+		if len(r.lists) == 0 {
+			r.breakBlock()
+			return
+		}
+		l := &r.lists[len(r.lists)-1]
+		marker := []string{"•", "◦", "▪"}[min(len(r.lists)-1, 2)] + " "
+		if l.ordered {
+			marker = fmt.Sprintf("%d. ", l.next)
+			l.next++
+		}
+		l.content = l.indent + lipgloss.Width(marker)
+		r.marker = r.styles.DescMarker.Render(marker)
+
+	case "table":
+		if r.table != nil {
+			// Tables in tables run into the cell they are in
+			r.table.nested++
+			r.space = true
+			return
+		}
+		r.block()
+		r.table = &htmlTable{}
+	case "thead":
+		if r.table != nil && r.table.nested == 0 {
+			r.table.inHead = true
+		}
+	case "tr":
+		if r.table != nil && r.table.nested == 0 {
+			r.endCell()
+			r.table.rows = append(r.table.rows, nil)
+			r.table.header = append(r.table.header, true)
+		}
+	case "td", "th":
+		if r.table != nil && r.table.nested == 0 {
+			r.endCell()
+			if len(r.table.rows) == 0 {
+				r.table.rows = append(r.table.rows, nil)
+				r.table.header = append(r.table.header, true)
+			}
+			r.table.inCell = true
+			r.table.thCell = tag == "th"
+			if r.table.thCell {
+				r.bold++
+			}
+		}
+	}
+}
+
+func (r *htmlRenderer) end(tag string) {
+	if r.table != nil && r.table.inCell {
+		switch tag {
+		case "p", "div", "li", "ul", "ol", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote":
+			r.space = true
+			return
+		}
+	}
+
+	switch tag {
+	case "script", "style":
+		r.skip = max(r.skip-1, 0)
+
+	case "b", "strong":
+		r.bold = max(r.bold-1, 0)
+	case "i", "em", "cite", "dfn":
+		r.italic = max(r.italic-1, 0)
+	case "u", "ins":
+		r.underline = max(r.underline-1, 0)
+	case "s", "del", "strike":
+		r.strike = max(r.strike-1, 0)
+	case "code", "kbd", "samp", "tt":
+		r.code = max(r.code-1, 0)
+	case "a":
+		r.link = ""
+
+	case "p", "pre", "section", "article", "header", "footer", "figure", "figcaption", "address", "dl":
+		r.block()
+	case "div", "dt", "dd", "li":
+		r.flush()
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		r.flush()
+		r.heading = false
+		r.breakBlock()
+
+	case "blockquote":
+		r.flush()
+		r.quotes = max(r.quotes-1, 0)
+		r.breakBlock()
+
+	case "ul", "ol":
+		r.flush()
+		r.marker = ""
+		if len(r.lists) > 0 {
+			r.lists = r.lists[:len(r.lists)-1]
+		}
+		if len(r.lists) == 0 {
+			r.breakBlock()
+		}
+
+	case "thead":
+		if r.table != nil && r.table.nested == 0 {
+			r.table.inHead = false
+		}
+	case "td", "th", "tr":
+		if r.table != nil && r.table.nested == 0 {
+			r.endCell()
+		}
+	case "table":
+		if r.table != nil && r.table.nested > 0 {
+			r.table.nested--
+			r.space = true
+		} else if r.table != nil {
+			r.endTable()
+			r.breakBlock()
+		}
+	}
+}
 
-	// Decode common HTML entities
-	result = decodeHTMLEntities(result)
+// text adds text to the current block, collapsing whitespace as HTML does.
+func (r *htmlRenderer) text(s string) {
+	if r.skip > 0 || (r.table != nil && !r.table.inCell) {
+		return
+	}
+	s = stripControl(s)
 
-	return result
+	fields := strings.FieldsFunc(s, isHTMLSpace)
+	if len(fields) == 0 {
+		if s != "" {
+			r.space = true
+		}
+		return
+	}
+	if isHTMLSpace(rune(s[0])) {
+		r.space = true
+	}
+	for i, field := range fields {
+		if i > 0 {
+			r.space = true
+		}
+		r.word(field)
+	}
+	if isHTMLSpace(rune(s[len(s)-1])) {
+		r.space = true
+	}
 }
 
-// decodeHTMLEntities decodes common HTML entities.
-func decodeHTMLEntities(s string) string {
-	replacements := map[string]string{
-		"&amp;":   "&",
-		"&lt;":    "<",
-		"&gt;":    ">",
-		"&quot;":  "\"",
-		"&#39;":   "'",
-		"&apos;":  "'",
-		"&nbsp;":  " ",
-		"&mdash;": "—",
-		"&ndash;": "–",
-		"&hellip;": "…",
-		"&copy;":  "©",
-		"&reg;":   "®",
-		"&trade;": "™",
+// word adds a piece of text with no whitespace in it, in the style of the
+// open inline elements. Without whitespace before it, it joins the last word.
+func (r *htmlRenderer) word(s string) {
+	styled := r.inlineStyle().Render(s)
+	if r.link != "" {
+		styled = "\x1b]8;;" + r.link + "\x1b\\" + styled + "\x1b]8;;\x1b\\"
+	}
+	w := htmlWord{text: styled, width: lipgloss.Width(s)}
+
+	if n := len(r.words); n > 0 && !r.space && !r.words[n-1].br {
+		r.words[n-1].text += w.text
+		r.words[n-1].width += w.width
+	} else {
+		r.words = append(r.words, w)
+	}
+	r.space = false
+}
+
+// inlineStyle returns the style of text in the open inline elements.
+func (r *htmlRenderer) inlineStyle() lipgloss.Style {
+	st := r.styles.ProductDescription.UnsetMargins()
+	switch {
+	case r.heading:
+		st = r.styles.DescHeading
+	case r.quotes > 0:
+		st = r.styles.DescQuote
+	}
+	if r.code > 0 {
+		st = r.styles.DescCode
+	}
+	if r.link != "" {
+		st = r.styles.DescLink
+	}
+	if r.bold > 0 {
+		st = st.Bold(true)
+	}
+	if r.italic > 0 {
+		st = st.Italic(true)
 	}
+	if r.underline > 0 {
+		st = st.Underline(true)
+	}
+	if r.strike > 0 {
+		st = st.Strikethrough(true)
+	}
+	return st
+}
+
+// block ends the current block and asks for a blank line before the next.
+func (r *htmlRenderer) block() {
+	r.flush()
+	r.breakBlock()
+}
+
+// breakBlock asks for a blank line before the next block.
+func (r *htmlRenderer) breakBlock() {
+	if !r.gap || r.quotes < r.gapQuotes {
+		r.gapQuotes = r.quotes
+	}
+	r.gap = true
+}
+
+// flush wraps the words of the current block into lines.
+func (r *htmlRenderer) flush() {
+	words := r.words
+	r.words = nil
+	r.space = false
+	if len(words) == 0 {
+		return
+	}
+
+	indent := r.indent()
+	avail := r.width - indent - lipgloss.Width(r.quotePrefix())
+	for _, line := range wrapWords(words, max(avail, 10)) {
+		r.emit(line)
+	}
+}
+
+// emit adds a line, indented for the open lists and quotes. The first line
+// of a list item gets its marker.
+func (r *htmlRenderer) emit(line string) {
+	if r.gap && len(r.lines) > 0 {
+		r.lines = append(r.lines, strings.TrimRight(strings.Repeat(r.styles.DescMarker.Render("│")+" ", r.gapQuotes), " "))
+	}
+	r.gap = false
+
+	lead := strings.Repeat(" ", r.indent())
+	if r.marker != "" && len(lead) >= lipgloss.Width(r.marker) {
+		lead = lead[:len(lead)-lipgloss.Width(r.marker)] + r.marker
+	}
+	r.marker = ""
+	r.lines = append(r.lines, r.quotePrefix()+lead+line)
+}
 
-	for entity, replacement := range replacements {
-		s = strings.ReplaceAll(s, entity, replacement)
+// indent returns the column text starts at in the open lists.
+func (r *htmlRenderer) indent() int {
+	if len(r.lists) == 0 {
+		return 0
 	}
+	return r.lists[len(r.lists)-1].content
+}
+
+// quotePrefix returns the bars drawn before lines in the open blockquotes.
+func (r *htmlRenderer) quotePrefix() string {
+	return strings.Repeat(r.styles.DescMarker.Render("│")+" ", r.quotes)
+}
 
-	return s
+// endCell closes the open table cell, keeping its words.
+func (r *htmlRenderer) endCell() {
+	t := r.table
+	if !t.inCell {
+		return
+	}
+	row := len(t.rows) - 1
+	t.rows[row] = append(t.rows[row], r.words)
+	if t.thCell {
+		r.bold = max(r.bold-1, 0)
+	} else if !t.inHead {
+		t.header[row] = false
+	}
+	r.words = nil
+	r.space = false
+	t.inCell = false
+	t.thCell = false
 }
 
+// endTable lays out the table. Columns are as wide as their widest cell,
+// narrowed to fit the width by wrapping the widest ones.
+func (r *htmlRenderer) endTable() {
+	r.endCell()
+	t := r.table
+	r.table = nil
 
+	// Rows without cells are left out
+	var rows [][][]htmlWord
+	var header []bool
+	for i, row := range t.rows {
+		if len(row) > 0 {
+			rows = append(rows, row)
+			header = append(header, t.header[i])
+		}
+	}
+
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	if cols == 0 {
+		return
+	}
 
+	widths := make([]int, cols)
+	minWidths := make([]int, cols) // Longest word
+	for _, row := range rows {
+		for j, cell := range row {
+			widths[j] = max(widths[j], lipgloss.Width(strings.Join(wrapWords(cell, 1<<30), "\n")))
+			for _, w := range cell {
+				minWidths[j] = max(minWidths[j], w.width)
+			}
+		}
+	}
+	const sep = 3 // " │ "
+	avail := r.width - r.indent() - lipgloss.Width(r.quotePrefix())
+	for total := sum(widths) + sep*(cols-1); total > avail; total-- {
+		widest := 0
+		for j := range widths {
+			if widths[j]-minWidths[j] > widths[widest]-minWidths[widest] {
+				widest = j
+			}
+		}
+		if widths[widest] <= minWidths[widest] {
+			break // Overflows; the words can't be broken
+		}
+		widths[widest]--
+	}
+
+	border := r.styles.DescMarker
+	for i, row := range rows {
+		cells := make([][]string, cols)
+		height := 1
+		for j := range cells {
+			if j < len(row) {
+				cells[j] = wrapWords(row[j], widths[j])
+			}
+			height = max(height, len(cells[j]))
+		}
+		for line := 0; line < height; line++ {
+			parts := make([]string, cols)
+			for j := range cells {
+				text := ""
+				if line < len(cells[j]) {
+					text = cells[j][line]
+				}
+				parts[j] = text + strings.Repeat(" ", max(widths[j]-lipgloss.Width(text), 0))
+			}
+			r.emit(strings.TrimRight(strings.Join(parts, border.Render(" │ ")), " "))
+		}
+
+		// Rule under the header
+		if header[i] && i+1 < len(rows) && !header[i+1] {
+			rules := make([]string, cols)
+			for j, w := range widths {
+				rules[j] = strings.Repeat("─", w)
+			}
+			r.emit(border.Render(strings.Join(rules, "─┼─")))
+		}
+	}
+}
+
+// wrapWords lays words out in lines of at most width cells. Words wider
+// than that get a line of their own.
+func wrapWords(words []htmlWord, width int) []string {
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	for _, w := range words {
+		if w.br {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+			continue
+		}
+		if lineWidth > 0 && lineWidth+1+w.width > width {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+		if lineWidth > 0 {
+			line.WriteString(" ")
+			lineWidth++
+		}
+		line.WriteString(w.text)
+		lineWidth += w.width
+	}
+	if lineWidth > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// sum adds up ints.
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
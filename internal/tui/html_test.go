@@ -188,3 +188,128 @@ func BenchmarkStripHTML(b *testing.B) {
 
 
 
+
+func TestStripHTMLDecodesOnce(t *testing.T) {
+	// Entities are decoded by the tokenizer only, so escaped markup stays escaped
+	if got := StripHTML("<p>Use &amp;lt;br&amp;gt; for breaks</p>"); got != "Use &lt;br&gt; for breaks" {
+		t.Errorf("got %q", got)
+	}
+	if got := StripHTML("<p>Bad\x1b[2Jtitle</p>"); got != "Bad[2Jtitle" {
+		t.Errorf("expected control characters removed, got %q", got)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	styles := DefaultStyles()
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  "",
+		},
+		{
+			name:  "paragraphs",
+			input: "<p>First <strong>bold</strong> and <em>italic</em>.</p>\n<p>Second&nbsp;one</p>",
+			want:  "First bold and italic.\n\nSecond\u00a0one", // Non-breaking space kept
+		},
+		{
+			name:  "wrapping",
+			input: "<p>A bright and fruity coffee from the Yirgacheffe region.</p>",
+			width: 24,
+			want:  "A bright and fruity\ncoffee from the\nYirgacheffe region.",
+		},
+		{
+			name:  "line breaks",
+			input: "Line 1<br>Line 2<br/>Line 3",
+			want:  "Line 1\nLine 2\nLine 3",
+		},
+		{
+			name:  "heading",
+			input: "<h2>Tasting notes</h2><p>Blueberry</p>",
+			want:  "Tasting notes\n\nBlueberry",
+		},
+		{
+			name:  "bulleted list with wrapping",
+			input: "<p>Notes:</p><ul><li>Blueberry and lemon zest</li><li>Floral</li></ul><p>Enjoy</p>",
+			width: 20,
+			want:  "Notes:\n\n  • Blueberry and\n    lemon zest\n  • Floral\n\nEnjoy",
+		},
+		{
+			name:  "numbered list",
+			input: `<ol start="9"><li>Grind</li><li>Brew</li></ol>`,
+			want:  "  9. Grind\n  10. Brew",
+		},
+		{
+			name:  "nested list",
+			input: "<ul><li>Origin<ul><li>Ethiopia</li></ul></li><li>Roast</li></ul>",
+			want:  "  • Origin\n    ◦ Ethiopia\n  • Roast",
+		},
+		{
+			name:  "blockquote",
+			input: "<p>They said:</p><blockquote><p>Best cup in town</p><p>Really</p></blockquote>",
+			want:  "They said:\n\n│ Best cup in town\n│\n│ Really",
+		},
+		{
+			name:  "table",
+			input: "<table><thead><tr><th>Origin</th><th>Roast</th></tr></thead><tbody><tr><td>Ethiopia</td><td>Light</td></tr><tr><td>Brazil</td><td>Medium dark</td></tr></tbody></table>",
+			want:  "Origin   │ Roast\n─────────┼────────────\nEthiopia │ Light\nBrazil   │ Medium dark",
+		},
+		{
+			name:  "table without header wraps to fit",
+			input: "<table><tr><td>Notes</td><td>Blueberry, lemon and jasmine</td></tr></table>",
+			width: 24,
+			want:  "Notes │ Blueberry, lemon\n      │ and jasmine",
+		},
+		{
+			name:  "entities and images",
+			input: `<p>Coffee &amp; Tea &hellip; &#39;fresh&#39; <img src="x.png" alt="☕"></p>`,
+			want:  "Coffee & Tea … 'fresh' ☕",
+		},
+		{
+			name:  "scripts skipped",
+			input: "<p>Shown</p><script>alert('x')</script><style>p{}</style>",
+			want:  "Shown",
+		},
+		{
+			name:  "malformed",
+			input: "<p>Unclosed <strong>tags</p><ul><li>Item",
+			want:  "Unclosed tags\n\n  • Item",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width := tt.width
+			if width == 0 {
+				width = 60
+			}
+			if got := RenderHTML(tt.input, width, styles); got != tt.want {
+				t.Errorf("RenderHTML(%q)\ngot:\n%s\nwant:\n%s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderHTMLLinks(t *testing.T) {
+	got := RenderHTML(`<p>See <a href="https://shop.example/brew">our brew guide</a>.</p>`, 60, DefaultStyles())
+
+	// Each word of the link is a hyperlink, so wrapping never splits one
+	link := "\x1b]8;;https://shop.example/brew\x1b\\"
+	if strings.Count(got, link) != 3 {
+		t.Errorf("expected three linked words, got %q", got)
+	}
+	if !strings.HasSuffix(got, "guide\x1b]8;;\x1b\\.") {
+		t.Errorf("expected link closed before the full stop, got %q", got)
+	}
+
+	// Control characters in the target can't end the sequence early
+	got = RenderHTML("<a href=\"https://x.example/\x1b]8;;evil\">x</a>", 60, DefaultStyles())
+	if strings.Contains(got, "\x1b]8;;evil") {
+		t.Errorf("expected control characters stripped from link, got %q", got)
+	}
+}
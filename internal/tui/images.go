@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/thomas/eva-terminal-go/internal/termimg"
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// Size of the product image in the details view, in terminal cells.
+const (
+	productImageCols = 32
+	productImageRows = 12
+)
+
+// imageMarker marks where graphics go in a rendered view. It is an escape
+// sequence terminals ignore, so it takes no room while lipgloss lays the
+// view out, and is taken out again by placeImage.
+const imageMarker = "\x1b_eva-image\x1b\\"
+
+// imageFallback describes a product image for terminals that can't show it.
+// The alt text and name come from the shop, so control characters are
+// taken out and line breaks joined, keeping the description on one line.
+func imageFallback(img *woo.Image, productName string) string {
+	clean := func(s string) string {
+		return strings.Join(strings.Fields(stripControl(s)), " ")
+	}
+	desc := clean(img.Alt)
+	if desc == "" {
+		desc = clean(img.Name)
+	}
+	if desc == "" {
+		desc = productName
+	}
+	return "[Image: " + desc + "]"
+}
+
+// placeImage draws graphics at the marker in a rendered view. The renderer
+// only redraws lines that change, ending each with an erase that would
+// wipe graphics drawn earlier on the line, so the graphics are sent after
+// everything else, from the last line, and drawn relative to it. Views
+// taller than the screen lose their top lines, so graphics are left out
+// when the view doesn't fit in height lines.
+func placeImage(view, graphics string, height int) string {
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		before, after, found := strings.Cut(line, imageMarker)
+		if !found {
+			continue
+		}
+		lines[i] = before + after
+		if height > 0 && len(lines) <= height {
+			lines[len(lines)-1] += termimg.Place(graphics, len(lines)-1-i, lipgloss.Width(before))
+		}
+		break
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+func TestPlaceImage(t *testing.T) {
+	view := "┌──────┐\n│  " + imageMarker + "    │\n│      │\n└──────┘"
+
+	got := placeImage(view, "IMG", 10)
+	want := "┌──────┐\n│      │\n│      │\n└──────┘\x1b7\r\x1b[2A\x1b[3CIMG\x1b8"
+	if got != want {
+		t.Errorf("placeImage = %q, want %q", got, want)
+	}
+
+	// Too tall for the screen: the marker goes, the graphics aren't drawn
+	got = placeImage(view, "IMG", 3)
+	if want := "┌──────┐\n│      │\n│      │\n└──────┘"; got != want {
+		t.Errorf("placeImage on a short screen = %q, want %q", got, want)
+	}
+}
+
+func TestImageFallback(t *testing.T) {
+	tests := []struct {
+		img  woo.Image
+		want string
+	}{
+		{woo.Image{Alt: "A white mug", Name: "mug-1"}, "[Image: A white mug]"},
+		{woo.Image{Name: "mug-1"}, "[Image: mug-1]"},
+		{woo.Image{Alt: "  "}, "[Image: Eva Mug]"},
+		{woo.Image{Alt: "\x1b[2JA white\nmug\x07"}, "[Image: [2JA white mug]"},
+		{woo.Image{Name: "\x1b\u009b"}, "[Image: Eva Mug]"},
+	}
+	for _, tt := range tests {
+		if got := imageFallback(&tt.img, "Eva Mug"); got != tt.want {
+			t.Errorf("imageFallback(%+v) = %q, want %q", tt.img, got, tt.want)
+		}
+	}
+}
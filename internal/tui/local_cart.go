@@ -2,54 +2,61 @@ package tui
 
 import (
 	"fmt"
+	"maps"
+	"time"
 
 	"github.com/thomas/eva-terminal-go/internal/woo"
 )
 
-// ShippingConfig holds the shipping calculation settings.
-// Since we only ship to Italy with simple flat rate / free shipping threshold.
-type ShippingConfig struct {
-	FlatRateCost    float64 // e.g., 5.00
-	FreeShippingMin float64 // e.g., 50.00 (free if subtotal >= this)
-}
-
-// DefaultShippingConfig returns the default shipping configuration.
-func DefaultShippingConfig() ShippingConfig {
-	return ShippingConfig{
-		FlatRateCost:    5.00,
-		FreeShippingMin: 50.00,
-	}
-}
-
 // LocalCart manages cart state locally per SSH session.
 type LocalCart struct {
 	// Items in the cart
 	Items []LocalCartItem
 
-	// Shipping configuration
-	ShippingConfig ShippingConfig
+	// Store currency and price format; every price in the cart is in this currency
+	Settings woo.StoreSettings
+
+	// Shipping method chosen at checkout, nil until then. It is cleared
+	// whenever the items change, since the rate depends on them.
+	Shipping *woo.ShippingRate
+
+	// Coupon applied in the cart, nil if none. It stays applied when the
+	// items change and is checked again before checkout.
+	Coupon *woo.Coupon
+
+	// Estimated tax for the chosen shipping method and address, nil until
+	// worked out at review or when the store doesn't charge tax.
+	Tax *woo.TaxEstimate
 
 	// UI state
 	SelectedIdx int
+
+	// Counts changes to the items and coupon, to tell when the cart needs saving
+	revision int
 }
 
 // LocalCartItem represents an item in the local cart.
 type LocalCartItem struct {
 	ProductID   int
 	VariationID int
-	Name        string            // Display name
-	Price       float64           // Unit price
+	Name        string    // Display name
+	Price       woo.Money // Unit price
 	Quantity    int
-	GrindSize   string            // Selected grind size (e.g., "Fine", "Whole Beans")
+	GrindSize   string // Selected grind size (e.g., "Fine", "Whole Beans")
+	TaxClass    string // "" for the standard class
+	TaxStatus   string // "taxable", "shipping" or "none"
+	CategoryIDs []int  // For coupon category restrictions
+	OnSale      bool
+	Stock       Stock             // As last seen, to cap the quantity
 	Meta        map[string]string // Additional metadata
 }
 
-// NewLocalCart creates a new empty local cart.
-func NewLocalCart() *LocalCart {
+// NewLocalCart creates a new empty local cart for a store.
+func NewLocalCart(settings woo.StoreSettings) *LocalCart {
 	return &LocalCart{
-		Items:          make([]LocalCartItem, 0),
-		ShippingConfig: DefaultShippingConfig(),
-		SelectedIdx:    0,
+		Items:       make([]LocalCartItem, 0),
+		Settings:    settings,
+		SelectedIdx: 0,
 	}
 }
 
@@ -58,20 +65,38 @@ func NewLocalCart() *LocalCart {
 // ============================================
 
 // AddItem adds an item to the cart.
-// If the same product/variation/grind exists, it increments quantity.
-func (c *LocalCart) AddItem(item LocalCartItem) {
+// If the same product/variation/grind/options exists, it increments quantity.
+// Items priced in another currency than the store's are refused, which is
+// what lets the price calculations below add amounts up without failing.
+func (c *LocalCart) AddItem(item LocalCartItem) error {
+	if err := c.checkCurrency(item); err != nil {
+		return err
+	}
+
 	// Check for existing item
 	for i := range c.Items {
 		if c.Items[i].ProductID == item.ProductID &&
 			c.Items[i].VariationID == item.VariationID &&
-			c.Items[i].GrindSize == item.GrindSize {
+			c.Items[i].GrindSize == item.GrindSize &&
+			maps.Equal(c.Items[i].Meta, item.Meta) {
 			c.Items[i].Quantity += item.Quantity
-			return
+			c.changed()
+			return nil
 		}
 	}
 
 	// Add new item
 	c.Items = append(c.Items, item)
+	c.changed()
+	return nil
+}
+
+// checkCurrency refuses items priced in another currency than the store's.
+func (c *LocalCart) checkCurrency(item LocalCartItem) error {
+	if currency := item.Price.Currency(); currency != "" && currency != c.Settings.Currency {
+		return fmt.Errorf("%s: priced in %s: %w", item.Name, currency, woo.ErrCurrencyMismatch)
+	}
+	return nil
 }
 
 // UpdateQuantity updates the quantity of an item by index.
@@ -85,6 +110,7 @@ func (c *LocalCart) UpdateQuantity(index int, quantity int) bool {
 	}
 
 	c.Items[index].Quantity = quantity
+	c.changed()
 	return true
 }
 
@@ -95,6 +121,7 @@ func (c *LocalCart) RemoveItem(index int) bool {
 	}
 
 	c.Items = append(c.Items[:index], c.Items[index+1:]...)
+	c.changed()
 
 	// Adjust selected index
 	if c.SelectedIdx >= len(c.Items) && len(c.Items) > 0 {
@@ -106,13 +133,66 @@ func (c *LocalCart) RemoveItem(index int) bool {
 // Clear removes all items from the cart.
 func (c *LocalCart) Clear() {
 	c.Items = make([]LocalCartItem, 0)
+	c.Coupon = nil
+	c.changed()
 	c.SelectedIdx = 0
 }
 
+// ApplyCoupon checks a coupon against the cart and applies it,
+// replacing any coupon applied before.
+func (c *LocalCart) ApplyCoupon(coupon *woo.Coupon, now time.Time) error {
+	if err := coupon.Validate(c.couponItems(), now); err != nil {
+		return err
+	}
+	if _, err := coupon.Discounts(c.couponItems()); err != nil {
+		return err
+	}
+	c.Coupon = coupon
+	c.changed()
+	return nil
+}
+
+// RemoveCoupon removes the applied coupon.
+func (c *LocalCart) RemoveCoupon() {
+	c.Coupon = nil
+	c.changed()
+}
+
+// ValidateCoupon checks the applied coupon against the current items,
+// including that its discount can be worked out for them.
+// It returns nil if there is no coupon.
+func (c *LocalCart) ValidateCoupon(now time.Time) error {
+	if c.Coupon == nil {
+		return nil
+	}
+	if err := c.Coupon.Validate(c.couponItems(), now); err != nil {
+		return err
+	}
+	_, err := c.lineDiscounts()
+	return err
+}
+
+// changed records a change to the items or coupon.
+func (c *LocalCart) changed() {
+	c.revision++
+	c.resetCheckout()
+}
+
+// resetCheckout forgets the shipping method and tax, which depend on the items.
+func (c *LocalCart) resetCheckout() {
+	c.Shipping = nil
+	c.Tax = nil
+}
+
 // ============================================
 // Query Methods
 // ============================================
 
+// Revision returns a number that changes whenever the items or coupon do.
+func (c *LocalCart) Revision() int {
+	return c.revision
+}
+
 // IsEmpty returns true if the cart has no items.
 func (c *LocalCart) IsEmpty() bool {
 	return len(c.Items) == 0
@@ -158,64 +238,151 @@ func (c *LocalCart) MoveDown() {
 // Price Calculations
 // ============================================
 
+// Every amount in the cart is in the store currency: AddItem refuses other
+// currencies, and coupon discounts, shipping and tax are worked out from
+// the item prices. So the sums below can't fail and ignore the errors.
+
 // Subtotal returns the cart subtotal (sum of line totals).
-func (c *LocalCart) Subtotal() float64 {
-	var total float64
+func (c *LocalCart) Subtotal() woo.Money {
+	total := woo.ZeroMoney(c.Settings.Currency)
 	for _, item := range c.Items {
-		total += item.Price * float64(item.Quantity)
+		total, _ = total.Add(item.LineTotal())
 	}
 	return total
 }
 
-// CalculateShipping returns the shipping cost based on config.
-// Returns 0 if subtotal >= free shipping threshold.
-func (c *LocalCart) CalculateShipping() float64 {
-	if c.IsEmpty() {
-		return 0
+// couponItems describes the items for coupon restrictions.
+func (c *LocalCart) couponItems() []woo.CouponItem {
+	items := make([]woo.CouponItem, len(c.Items))
+	for i, item := range c.Items {
+		items[i] = woo.CouponItem{
+			ProductID:   item.ProductID,
+			VariationID: item.VariationID,
+			CategoryIDs: item.CategoryIDs,
+			OnSale:      item.OnSale,
+			Price:       item.Price,
+			Quantity:    item.Quantity,
+		}
 	}
-	subtotal := c.Subtotal()
-	if subtotal >= c.ShippingConfig.FreeShippingMin {
-		return 0
+	return items
+}
+
+// lineDiscounts returns the coupon discount on each item, all zero
+// without a coupon.
+func (c *LocalCart) lineDiscounts() ([]woo.Money, error) {
+	if c.Coupon != nil {
+		return c.Coupon.Discounts(c.couponItems())
+	}
+	discounts := make([]woo.Money, len(c.Items))
+	for i := range discounts {
+		discounts[i] = woo.ZeroMoney(c.Settings.Currency)
+	}
+	return discounts, nil
+}
+
+// Discount returns the coupon discount on the items. A coupon whose
+// discount can't be worked out gives none; ValidateCoupon reports why,
+// and refuses it before checkout.
+func (c *LocalCart) Discount() woo.Money {
+	total := woo.ZeroMoney(c.Settings.Currency)
+	discounts, err := c.lineDiscounts()
+	if err != nil {
+		return total
+	}
+	for _, discount := range discounts {
+		total, _ = total.Add(discount)
+	}
+	return total
+}
+
+// CalculateShipping returns the cost of the chosen shipping method,
+// or 0 if none has been chosen yet.
+func (c *LocalCart) CalculateShipping() woo.Money {
+	if c.IsEmpty() || c.Shipping == nil {
+		return woo.ZeroMoney(c.Settings.Currency)
+	}
+	return c.Shipping.Cost
+}
+
+// CalculateTax returns the estimated tax, or 0 if it hasn't been worked out.
+func (c *LocalCart) CalculateTax() woo.Money {
+	if c.IsEmpty() || c.Tax == nil {
+		return woo.ZeroMoney(c.Settings.Currency)
 	}
-	return c.ShippingConfig.FlatRateCost
+	return c.Tax.Total
 }
 
-// CalculateTotal returns the cart total (subtotal + shipping).
-func (c *LocalCart) CalculateTotal() float64 {
-	return c.Subtotal() + c.CalculateShipping()
+// CalculateTotal returns the cart total (subtotal - discount + shipping +
+// tax not already included in the prices).
+func (c *LocalCart) CalculateTotal() woo.Money {
+	total, _ := c.Subtotal().Sub(c.Discount())
+	total, _ = total.Add(c.CalculateShipping())
+	if c.IsEmpty() || c.Tax == nil {
+		return total
+	}
+	excluded, _ := c.Tax.Total.Sub(c.Tax.Included)
+	total, _ = total.Add(excluded)
+	return total
 }
 
-// QualifiesForFreeShipping returns true if free shipping applies.
-func (c *LocalCart) QualifiesForFreeShipping() bool {
-	return c.Subtotal() >= c.ShippingConfig.FreeShippingMin
+// EstimateTax works out the tax on the discounted items and the chosen
+// shipping method for an address, and keeps it in Tax.
+func (c *LocalCart) EstimateTax(table *woo.TaxTable, loc woo.TaxLocation) error {
+	discounts, err := c.lineDiscounts()
+	if err != nil {
+		return fmt.Errorf("estimating tax: %w", err)
+	}
+	lines := make([]woo.TaxableLine, len(c.Items))
+	for i, item := range c.Items {
+		total, _ := item.LineTotal().Sub(discounts[i])
+		lines[i] = woo.TaxableLine{Total: total, TaxClass: item.TaxClass, TaxStatus: item.TaxStatus}
+	}
+	estimate, err := table.Estimate(loc, lines, c.Shipping, c.Settings.Decimals)
+	if err != nil {
+		return fmt.Errorf("estimating tax: %w", err)
+	}
+	c.Tax = estimate
+	return nil
 }
 
-// AmountUntilFreeShipping returns how much more is needed for free shipping.
-func (c *LocalCart) AmountUntilFreeShipping() float64 {
-	remaining := c.ShippingConfig.FreeShippingMin - c.Subtotal()
-	if remaining < 0 {
-		return 0
+// ShippingPackage describes the cart contents for a destination,
+// for working out which shipping methods apply.
+func (c *LocalCart) ShippingPackage(country, state, postcode string) woo.ShippingPackage {
+	return woo.ShippingPackage{
+		Country:  country,
+		State:    state,
+		Postcode: postcode,
+		Contents: c.Subtotal(),
+		Quantity: c.ItemCount(),
+
+		Discount:           c.Discount(),
+		FreeShippingCoupon: c.Coupon != nil && c.Coupon.FreeShipping,
+
+		DecimalSeparator:  c.Settings.DecimalSeparator,
+		ThousandSeparator: c.Settings.ThousandSeparator,
 	}
-	return remaining
 }
 
 // GetSubtotal returns the formatted subtotal string.
 func (c *LocalCart) GetSubtotal() string {
-	return fmt.Sprintf("$%.2f", c.Subtotal())
+	return c.Settings.Format(c.Subtotal())
 }
 
 // GetTotal returns the formatted total string.
 func (c *LocalCart) GetTotal() string {
-	return fmt.Sprintf("$%.2f", c.CalculateTotal())
+	return c.Settings.Format(c.CalculateTotal())
 }
 
 // GetShippingFormatted returns the formatted shipping cost string.
 func (c *LocalCart) GetShippingFormatted() string {
+	if c.Shipping == nil {
+		return "calculated at checkout"
+	}
 	shipping := c.CalculateShipping()
-	if shipping == 0 {
+	if shipping.IsZero() {
 		return "FREE"
 	}
-	return fmt.Sprintf("$%.2f", shipping)
+	return c.Settings.Format(shipping)
 }
 
 // ============================================
@@ -231,14 +398,29 @@ func (item *LocalCartItem) GetDisplayName() string {
 	return name
 }
 
+// LineTotal returns the unit price times the quantity.
+func (item *LocalCartItem) LineTotal() woo.Money {
+	return item.Price.Mul(item.Quantity)
+}
+
 // GetFormattedPrice returns formatted unit price.
-func (item *LocalCartItem) GetFormattedPrice() string {
-	return fmt.Sprintf("$%.2f", item.Price)
+func (item *LocalCartItem) GetFormattedPrice(settings woo.StoreSettings) string {
+	return settings.Format(item.Price)
 }
 
 // GetFormattedTotal returns formatted line total.
-func (item *LocalCartItem) GetFormattedTotal() string {
-	return fmt.Sprintf("$%.2f", item.Price*float64(item.Quantity))
+func (item *LocalCartItem) GetFormattedTotal(settings woo.StoreSettings) string {
+	return settings.Format(item.LineTotal())
+}
+
+// formatPrice formats a WooCommerce price string in the store format.
+// Prices that do not parse are shown as unavailable rather than as zero.
+func formatPrice(settings woo.StoreSettings, price string) string {
+	m, err := settings.ParsePrice(price)
+	if err != nil {
+		return "n/a"
+	}
+	return settings.Format(m)
 }
 
 // ============================================
@@ -246,35 +428,51 @@ func (item *LocalCartItem) GetFormattedTotal() string {
 // ============================================
 
 // NewLocalCartItemFromProduct creates a LocalCartItem from product data.
-func NewLocalCartItemFromProduct(product *woo.Product, variation *woo.Variation, quantity int, grindSize string) LocalCartItem {
+// It fails if the product or variation has no valid price.
+func NewLocalCartItemFromProduct(product *woo.Product, variation *woo.Variation, quantity int, grindSize string, currency string) (LocalCartItem, error) {
 	item := LocalCartItem{
 		ProductID: product.ID,
 		Quantity:  quantity,
 		GrindSize: grindSize,
+		TaxClass:  product.TaxClass,
+		TaxStatus: product.TaxStatus,
+		OnSale:    product.OnSale,
+		Stock:     productStock(product, variation),
 		Meta:      make(map[string]string),
 	}
+	for _, category := range product.Categories {
+		item.CategoryIDs = append(item.CategoryIDs, category.ID)
+	}
 
 	if variation != nil {
 		item.VariationID = variation.ID
 		// Build display name with variant info
-		variantName := variation.GetAttributeValue("Size")
-		if variantName != "" {
-			item.Name = fmt.Sprintf("%s (%s)", product.Name, variantName)
-		} else {
-			item.Name = product.Name
+		options := make([]string, len(variation.Attributes))
+		for i, attr := range variation.Attributes {
+			options[i] = attr.Option
+		}
+		item.Name = variationName(product, options)
+		price, err := variation.DisplayPrice(currency)
+		if err != nil {
+			return LocalCartItem{}, fmt.Errorf("variation %d: %w", variation.ID, err)
+		}
+		item.Price = price
+		item.OnSale = variation.OnSale
+		// Variations may override the tax settings of the product
+		if variation.TaxClass != "parent" {
+			item.TaxClass = variation.TaxClass
+		}
+		if variation.TaxStatus != "" {
+			item.TaxStatus = variation.TaxStatus
 		}
-		item.Price = parsePrice(variation.GetDisplayPrice())
 	} else {
 		item.Name = product.Name
-		item.Price = parsePrice(product.GetDisplayPrice())
+		price, err := product.DisplayPrice(currency)
+		if err != nil {
+			return LocalCartItem{}, fmt.Errorf("product %d: %w", product.ID, err)
+		}
+		item.Price = price
 	}
 
-	return item
-}
-
-// parsePrice converts a price string to float64.
-func parsePrice(priceStr string) float64 {
-	var price float64
-	fmt.Sscanf(priceStr, "%f", &price)
-	return price
+	return item, nil
 }
@@ -0,0 +1,232 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// italianStore formats prices the way our Italian shop does.
+var italianStore = woo.StoreSettings{
+	Currency:          "EUR",
+	CurrencySymbol:    "€",
+	CurrencyPosition:  woo.CurrencyPosRightSpace,
+	ThousandSeparator: ".",
+	DecimalSeparator:  ",",
+	Decimals:          2,
+}
+
+func eur(minor int64) woo.Money {
+	return woo.NewMoney(minor, 2, "EUR")
+}
+
+func TestLocalCartTotalsAreExact(t *testing.T) {
+	cart := NewLocalCart(italianStore)
+
+	// 3 x 0.10 + 0.20 is 0.5 exactly, not 0.5000000000000001
+	cart.AddItem(LocalCartItem{ProductID: 1, Name: "Sample", Price: eur(10), Quantity: 3})
+	cart.AddItem(LocalCartItem{ProductID: 2, Name: "Sticker", Price: eur(20), Quantity: 1})
+
+	if got := cart.Subtotal().String(); got != "0.50" {
+		t.Errorf("expected subtotal 0.50, got %s", got)
+	}
+
+	cart.Shipping = &woo.ShippingRate{MethodID: "flat_rate", Title: "Corriere", Cost: eur(500)}
+	if got := cart.CalculateTotal().String(); got != "5.50" {
+		t.Errorf("expected total 5.50 with flat rate shipping, got %s", got)
+	}
+	if got := cart.GetTotal(); got != "5,50 €" {
+		t.Errorf("expected formatted total 5,50 €, got %s", got)
+	}
+}
+
+func TestLocalCartRefusesOtherCurrencies(t *testing.T) {
+	cart := NewLocalCart(italianStore)
+
+	err := cart.AddItem(LocalCartItem{ProductID: 1, Name: "Sample", Price: woo.NewMoney(10, 2, "USD"), Quantity: 1})
+	if !errors.Is(err, woo.ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if !cart.IsEmpty() {
+		t.Error("expected the item not to be added")
+	}
+}
+
+func TestLocalCartShipping(t *testing.T) {
+	cart := NewLocalCart(italianStore)
+	cart.AddItem(LocalCartItem{ProductID: 1, Name: "Bag", Price: eur(2500), Quantity: 2})
+
+	// No method chosen yet
+	if !cart.CalculateShipping().IsZero() {
+		t.Errorf("expected no shipping cost before checkout, got %s", cart.CalculateShipping())
+	}
+	if got := cart.GetShippingFormatted(); got != "calculated at checkout" {
+		t.Errorf("expected shipping to be calculated at checkout, got %s", got)
+	}
+
+	pkg := cart.ShippingPackage("IT", "RM", "00186")
+	if pkg.Contents.String() != "50.00" || pkg.Quantity != 2 || pkg.Country != "IT" {
+		t.Errorf("unexpected shipping package %+v", pkg)
+	}
+
+	cart.Shipping = &woo.ShippingRate{MethodID: "free_shipping", Title: "Free shipping", Cost: woo.ZeroMoney("EUR")}
+	if got := cart.GetShippingFormatted(); got != "FREE" {
+		t.Errorf("expected FREE, got %s", got)
+	}
+
+	// Changing the items invalidates the chosen rate
+	cart.UpdateQuantity(0, 1)
+	if cart.Shipping != nil {
+		t.Error("expected the shipping method to be cleared when the cart changes")
+	}
+}
+
+func TestLocalCartTax(t *testing.T) {
+	table := &woo.TaxTable{
+		PricesIncludeTax: true,
+		ShippingTaxClass: "inherit",
+		Rates: []woo.TaxRate{
+			{ID: 1, Country: "IT", Rate: "22.0000", Name: "IVA", Priority: 1, Shipping: true, Class: "standard"},
+		},
+	}
+	cart := NewLocalCart(italianStore)
+	cart.AddItem(LocalCartItem{ProductID: 1, Name: "Bag", Price: eur(1220), Quantity: 1, TaxStatus: woo.TaxStatusTaxable})
+	cart.Shipping = &woo.ShippingRate{Title: "Corriere", Cost: eur(500), Taxable: true}
+
+	if err := cart.EstimateTax(table, woo.TaxLocation{Country: "IT"}); err != nil {
+		t.Fatalf("EstimateTax failed: %v", err)
+	}
+	if got := cart.CalculateTax().String(); got != "3.30" {
+		t.Errorf("expected 3.30 of tax, got %s", got)
+	}
+	// The 2.20 on the bag is already in its price; only shipping tax is added
+	if got := cart.CalculateTotal().String(); got != "18.30" {
+		t.Errorf("expected total 18.30, got %s", got)
+	}
+
+	cart.RemoveItem(0)
+	if cart.Tax != nil {
+		t.Error("expected the tax estimate to be cleared when the cart changes")
+	}
+}
+
+func TestLocalCartCoupon(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cart := NewLocalCart(italianStore)
+	cart.AddItem(LocalCartItem{ProductID: 1, Name: "Bag", Price: eur(1500), Quantity: 2, CategoryIDs: []int{15}})
+
+	welcome := &woo.Coupon{Code: "benvenuto10", DiscountType: woo.DiscountPercent, Amount: "10", MinimumAmount: "25.00", FreeShipping: true}
+	if err := cart.ApplyCoupon(welcome, now); err != nil {
+		t.Fatalf("ApplyCoupon failed: %v", err)
+	}
+	if got := cart.Discount().String(); got != "3.00" {
+		t.Errorf("expected 3.00 off, got %s", got)
+	}
+
+	cart.Shipping = &woo.ShippingRate{Title: "Corriere", Cost: eur(500)}
+	if got := cart.CalculateTotal().String(); got != "32.00" {
+		t.Errorf("expected 30.00 - 3.00 + 5.00 = 32.00, got %s", got)
+	}
+
+	pkg := cart.ShippingPackage("IT", "", "")
+	if pkg.Discount.String() != "3.00" || !pkg.FreeShippingCoupon {
+		t.Errorf("expected the discount and free shipping coupon in the package, got %+v", pkg)
+	}
+
+	// The coupon survives cart changes but is checked again at checkout
+	cart.UpdateQuantity(0, 1)
+	if cart.Coupon == nil {
+		t.Fatal("expected the coupon to stay applied")
+	}
+	if err := cart.ValidateCoupon(now); !errors.Is(err, woo.ErrCouponMinimumSpend) {
+		t.Errorf("expected ErrCouponMinimumSpend below the minimum, got %v", err)
+	}
+
+	decaf := &woo.Coupon{Code: "decaf", DiscountType: woo.DiscountFixedCart, Amount: "5", ProductCategories: []int{17}}
+	if err := cart.ApplyCoupon(decaf, now); !errors.Is(err, woo.ErrCouponNotApplicable) {
+		t.Errorf("expected ErrCouponNotApplicable, got %v", err)
+	}
+	if cart.Coupon != welcome {
+		t.Error("expected a rejected coupon to leave the applied one in place")
+	}
+
+	cart.Clear()
+	if cart.Coupon != nil {
+		t.Error("expected Clear to remove the coupon")
+	}
+}
+
+func TestLocalCartBrokenCoupon(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cart := NewLocalCart(italianStore)
+	cart.AddItem(LocalCartItem{ProductID: 1, Name: "Bag", Price: eur(1500), Quantity: 1})
+	// E.g. restored with the cart after the shop edited it
+	cart.Coupon = &woo.Coupon{Code: "broken", DiscountType: woo.DiscountFixedCart, Amount: "five"}
+
+	if err := cart.ValidateCoupon(now); !errors.Is(err, woo.ErrInvalidMoney) {
+		t.Errorf("expected the discount error from ValidateCoupon, got %v", err)
+	}
+	if err := cart.EstimateTax(&woo.TaxTable{}, woo.TaxLocation{Country: "IT"}); !errors.Is(err, woo.ErrInvalidMoney) {
+		t.Errorf("expected the discount error from EstimateTax, got %v", err)
+	}
+	if !cart.Discount().IsZero() {
+		t.Errorf("expected no discount, got %s", cart.Discount())
+	}
+}
+
+func TestNewLocalCartItemFromProduct(t *testing.T) {
+	product := &woo.Product{ID: 1, Name: "Ethiopian", Price: "18.99", RegularPrice: "18.99", TaxStatus: "taxable", TaxClass: "reduced-rate"}
+
+	item, err := NewLocalCartItemFromProduct(product, nil, 3, "Espresso", "EUR")
+	if err != nil {
+		t.Fatalf("NewLocalCartItemFromProduct failed: %v", err)
+	}
+	if item.Price.String() != "18.99" || item.Price.Currency() != "EUR" {
+		t.Errorf("expected 18.99 EUR, got %s %s", item.Price, item.Price.Currency())
+	}
+	if got := item.LineTotal().String(); got != "56.97" {
+		t.Errorf("expected line total 56.97, got %s", got)
+	}
+
+	variation := &woo.Variation{ID: 1011, Price: "49.99", TaxClass: "parent", Attributes: []woo.VariationAttribute{{Name: "Size", Option: "1kg"}}}
+	item, err = NewLocalCartItemFromProduct(product, variation, 1, "", "EUR")
+	if err != nil {
+		t.Fatalf("NewLocalCartItemFromProduct with variation failed: %v", err)
+	}
+	if item.Price.String() != "49.99" || item.VariationID != 1011 {
+		t.Errorf("expected variation 1011 at 49.99, got %d at %s", item.VariationID, item.Price)
+	}
+	if item.TaxClass != "reduced-rate" || item.TaxStatus != "taxable" {
+		t.Errorf("expected the parent tax class, got %q/%q", item.TaxClass, item.TaxStatus)
+	}
+
+	// "" is the standard class, not "same as parent"
+	variation.TaxClass = ""
+	item, _ = NewLocalCartItemFromProduct(product, variation, 1, "", "EUR")
+	if item.TaxClass != "" {
+		t.Errorf("expected the standard tax class, got %q", item.TaxClass)
+	}
+}
+
+func TestNewLocalCartItemRejectsInvalidPrice(t *testing.T) {
+	for _, price := range []string{"", "free", "1,99"} {
+		product := &woo.Product{ID: 1, Name: "Broken", Price: price}
+		if _, err := NewLocalCartItemFromProduct(product, nil, 1, "", "EUR"); !errors.Is(err, woo.ErrInvalidMoney) {
+			t.Errorf("price %q: expected ErrInvalidMoney, got %v", price, err)
+		}
+	}
+}
+
+func TestFormatPrice(t *testing.T) {
+	if got := formatPrice(woo.DefaultStoreSettings(), "18.5"); got != "$18.50" {
+		t.Errorf("expected $18.50, got %s", got)
+	}
+	if got := formatPrice(italianStore, "1234.5"); got != "1.234,50 €" {
+		t.Errorf("expected 1.234,50 €, got %s", got)
+	}
+	if got := formatPrice(italianStore, ""); got != "n/a" {
+		t.Errorf("expected n/a for an empty price, got %s", got)
+	}
+}
@@ -2,8 +2,13 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -13,6 +18,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/thomas/eva-terminal-go/internal/cache"
+	"github.com/thomas/eva-terminal-go/internal/carts"
+	"github.com/thomas/eva-terminal-go/internal/termimg"
 	"github.com/thomas/eva-terminal-go/internal/woo"
 )
 
@@ -27,22 +34,107 @@ const (
 	ViewAddress // Address entry
 	ViewReview  // Review order with calculated totals
 	ViewOrderConfirmation
+	ViewCategoryPicker // Choose a category to filter the product list
+	ViewFilterPanel    // Price range, on sale, featured, SKU and other filters
+	ViewPayment        // Choose a payment gateway, between address and review
+	ViewShipping       // Choose a shipping method for the entered address
+	ViewOrders         // Order history
+	ViewOrderDetail    // One order with its items, address and notes
+	ViewReorder        // Differences between a past order and the shop today
+	ViewTracking       // Live status of the order just placed
+	ViewReviewForm     // Rate and review the selected product
 )
 
 // ProductListCacheKey is the cache key for product lists.
+// It must cover every field of woo.GetProductsParams that changes the result.
 type ProductListCacheKey struct {
 	Page        int
 	PerPage     int
 	Search      string
 	InStockOnly bool
+	Category    int
+	Tag         int
+	OrderBy     string
+	Order       string
+	MinPrice    string
+	MaxPrice    string
+	OnSale      bool
+	Featured    bool
+	SKU         string
+	Include     string // Comma-separated product IDs
+	Exclude     string // Comma-separated product IDs
 }
 
+// newProductListCacheKey builds the cache key for a product list request.
+func newProductListCacheKey(params woo.GetProductsParams) ProductListCacheKey {
+	return ProductListCacheKey{
+		Page:        params.Page,
+		PerPage:     params.PerPage,
+		Search:      params.Search,
+		InStockOnly: params.InStockOnly,
+		Category:    params.Category,
+		Tag:         params.Tag,
+		OrderBy:     params.OrderBy,
+		Order:       params.Order,
+		MinPrice:    params.MinPrice,
+		MaxPrice:    params.MaxPrice,
+		OnSale:      params.OnSale,
+		Featured:    params.Featured,
+		SKU:         params.SKU,
+		Include:     woo.JoinIDs(params.Include),
+		Exclude:     woo.JoinIDs(params.Exclude),
+	}
+}
+
+// sortOption is one entry of the product list sort toggle.
+type sortOption struct {
+	label   string
+	orderBy string // Empty uses the API default (newest first)
+	order   string
+}
+
+// sortOptions are cycled with the sort key on the product list.
+var sortOptions = []sortOption{
+	{label: "Newest", orderBy: "", order: ""},
+	{label: "Price: low to high", orderBy: woo.OrderByPrice, order: "asc"},
+	{label: "Price: high to low", orderBy: woo.OrderByPrice, order: "desc"},
+	{label: "Most popular", orderBy: woo.OrderByPopularity, order: "desc"},
+	{label: "Top rated", orderBy: woo.OrderByRating, order: "desc"},
+}
+
+// priceRange is one preset of the filter panel price row.
+type priceRange struct {
+	label    string
+	minPrice string
+	maxPrice string
+}
+
+// priceRanges are cycled on the price row of the filter panel.
+var priceRanges = []priceRange{
+	{label: "Any price"},
+	{label: "Under 15", maxPrice: "15"},
+	{label: "15 to 25", minPrice: "15", maxPrice: "25"},
+	{label: "25 and up", minPrice: "25"},
+}
+
+// Rows of the filter panel, in display order.
+const (
+	filterRowPrice = iota
+	filterRowOnSale
+	filterRowFeatured
+	filterRowInStock
+	filterRowHideInCart
+	filterRowSKU
+	filterRowCount
+)
+
 // Model is the main Bubble Tea model for the TUI.
 type Model struct {
 	// Dependencies
 	wooClient       *woo.Client
-	productsCache   *cache.Cache[ProductListCacheKey, []woo.Product]
+	productsCache   *cache.Cache[ProductListCacheKey, woo.ProductPage]
 	variationsCache *cache.Cache[int, []woo.Variation]
+	storeSettings   woo.StoreSettings
 
 	// View state
 	viewState ViewState
@@ -58,51 +150,191 @@ type Model struct {
 	inStockOnly     bool
 	currentPage     int
 	perPage         int
+	totalPages      int
+	totalProducts   int
 	loadingProducts bool
 	listSpinner     spinner.Model
 
+	// Category picker view
+	categories        []woo.Category
+	categoryIdx       int           // 0 is "All Coffees", i+1 is categories[i]
+	selectedCategory  *woo.Category // nil when not filtering by category
+	loadingCategories bool
+
+	// Sorting and filter panel
+	sortIdx       int // Index into sortOptions
+	priceRangeIdx int // Index into priceRanges
+	onSaleOnly    bool
+	featuredOnly  bool
+	hideInCart    bool // Exclude products already in the cart
+	skuInput      textinput.Model
+	filterRow     int
+
 	// Product details view
 	selectedProduct   *woo.Product
 	productVariations []woo.Variation
 	loadingVariations bool
 
+	// Product images, nil loader if they aren't shown
+	imageLoader     *termimg.Loader
+	imageProtocol   termimg.Protocol
+	productImage    string // Main image of selectedProduct, rendered
+	productImageURL string // Image being loaded or rendered
+	loadingImage    bool
+	imageErr        error
+
+	// Reviews of selectedProduct, a page at a time
+	reviews           []woo.ProductReview
+	reviewsPage       int
+	reviewsTotalPages int
+	reviewsScroll     int // First line of the review list shown
+	loadingReviews    bool
+	reviewsErr        error
+
+	// Writing a review, for customers signed in with their SSH key
+	reviewDraft      *reviewDraft // Bound to reviewForm
+	reviewForm       *huh.Form
+	submittingReview bool
+	reviewErr        error  // Submitting failed; the form is shown again
+	reviewNotice     string // Thanks shown in the details once sent
+
 	// Configurator view
 	selectedVariation *woo.Variation
 	selectedGrindSize string
+	configChoices     *configChoices // Bound to configForm's selects
 	configForm        *huh.Form
 	configCompleted   bool
 
 	// Local cart (per SSH session)
 	localCart *LocalCart
 
+	// Coupon entry in the cart
+	couponInput     textinput.Model
+	showCouponInput bool
+	checkingCoupon  bool
+	couponErr       error
+	cartErr         error // Quantity change refused
+
 	// Review/Checkout
 	addressForm   *huh.Form
 	customerInfo  *CustomerInfo
 	creatingOrder bool
+	checkingStock bool          // Stock checked again before the order is placed
+	stockIssues   []StockIssue  // Lines the shop can no longer fulfil
+	priceChanges  []PriceChange // Lines repriced by the stock check
+
+	// Safe retries when placing the order. The token goes in the order's
+	// meta_data, so an order saved by a request that failed midway can be
+	// found instead of being placed again.
+	orderToken         string
+	orderTokenRevision int       // Cart revision the token was made for
+	orderAttemptedAt   time.Time // When the token was first sent
+	orderAttempts      int       // Attempts since enter was pressed
+	checkingOrder      bool      // Looking up an order whose creation may have failed
+	orderUncertain     bool      // The lookup failed too; enter checks again
+
+	// Shipping step
+	shippingTable   *woo.ShippingTable
+	shippingRates   []woo.ShippingRate // Rates for the entered address
+	shippingIdx     int
+	loadingShipping bool
+
+	// Payment step
+	paymentGateways []woo.PaymentGateway
+	paymentIdx      int
+	selectedGateway *woo.PaymentGateway
+	loadingGateways bool
+
+	// Tax estimate shown at review
+	taxTable   *woo.TaxTable
+	loadingTax bool
+	orderTax   *woo.TaxEstimate // Estimate the last order was placed with
+
+	// Context of the SSH session, cancelled when it ends
+	ctx context.Context
+
+	// Fingerprint of the session's SSH key, "" if unknown
+	keyFingerprint string
+
+	// Reports problems with the store setup the customer isn't shown,
+	// nil to drop them
+	logf func(format string, args ...any)
+
+	// Customer account linked to the session's SSH key
+	customerLinks CustomerLinker
+	customerID    int           // Zero for guest checkout
+	customer      *woo.Customer // nil until loaded
+
+	// Cart saved between sessions, nil if carts are not kept
+	cartStore         CartStore
+	cartSaver         *cartSaver
+	savedCart         carts.Cart // Last snapshot saved
+	savedCartRevision int
+	cartSaves         int       // Numbers the saves, to keep them in order
+	offerCartResume   bool      // Show the welcome back banner for a restored cart
+	cartSavedAt       time.Time // When the restored cart was last changed
 
 	// Order confirmation
 	orderResponse *woo.OrderResponse
 
+	// Order history. Guests only see the orders placed in this session,
+	// since an email address doesn't prove who they are.
+	placedOrderIDs     []int
+	orders             []woo.OrderResponse
+	orderIdx           int
+	ordersPage         int
+	ordersTotalPages   int
+	loadingOrders      bool
+	selectedOrder      *woo.OrderResponse
+	orderNotes         []woo.OrderNote
+	loadingOrderDetail bool
+
+	// Reorder from a past order
+	reorderLines    []ReorderLine
+	checkingReorder bool
+
+	// Live tracking of the order just placed. Each poll carries the
+	// session it belongs to; leaving the view starts a new session, so
+	// polls already scheduled are dropped and nothing more is scheduled.
+	// Each session's requests run in its context, cancelled when it ends.
+	trackingSteps   []trackingStep // Statuses seen, oldest first
+	trackingNotes   []woo.OrderNote
+	trackingPolls   int // Polls in a row without a change, for the backoff
+	trackingSession int
+	trackingCtx     context.Context
+	trackingCancel  context.CancelFunc
+	trackingErr     error // Last poll failure; polling goes on
+
 	// Error handling
 	err error
 }
 
+// CustomerLinker remembers which WooCommerce customer an SSH key belongs to.
+// It is shared by all sessions.
+type CustomerLinker interface {
+	CustomerID(fingerprint string) (int, bool)
+	Link(fingerprint string, customerID int) error
+}
+
 // CustomerInfo holds customer information for checkout.
 type CustomerInfo struct {
-	FirstName string
-	LastName  string
-	Email     string
-	Address   string
-	City      string
-	Postcode  string
-	Country   string
+	FirstName        string
+	LastName         string
+	Email            string
+	Address          string
+	City             string
+	State            string // State or province code, e.g. "RM"
+	Postcode         string
+	Country          string
 	AddressConfirmed bool
+	CreateAccount    bool // Open an account for the key with this address
 }
 
 // productItem implements list.Item for products.
 type productItem struct {
-	product woo.Product
-	styles  Styles
+	product  woo.Product
+	styles   Styles
+	settings woo.StoreSettings
 }
 
 func (i productItem) Title() string {
@@ -110,7 +342,7 @@ func (i productItem) Title() string {
 }
 
 func (i productItem) Description() string {
-	price := i.product.GetDisplayPrice()
+	price := formatPrice(i.settings, i.product.GetDisplayPrice())
 	stock := "In Stock"
 	if !i.product.IsInStock() {
 		stock = "Out of Stock"
@@ -119,7 +351,7 @@ func (i productItem) Description() string {
 	if i.product.IsVariable() {
 		typeLabel = " [Variable]"
 	}
-	return fmt.Sprintf("$%s • %s%s", price, stock, typeLabel)
+	return fmt.Sprintf("%s • %s%s", price, stock, typeLabel)
 }
 
 func (i productItem) FilterValue() string {
@@ -129,21 +361,99 @@ func (i productItem) FilterValue() string {
 // Messages
 type (
 	productsLoadedMsg struct {
-		products []woo.Product
+		key  ProductListCacheKey // Request the page answers
+		page woo.ProductPage
 	}
 	variationsLoadedMsg struct {
 		variations []woo.Variation
 	}
-	orderCreatedMsg struct {
+	categoriesLoadedMsg struct {
+		categories []woo.Category
+	}
+	paymentGatewaysLoadedMsg struct {
+		gateways []woo.PaymentGateway
+	}
+	shippingTableLoadedMsg struct {
+		table *woo.ShippingTable
+	}
+	taxTableLoadedMsg struct {
+		table *woo.TaxTable
+	}
+	couponCheckedMsg struct {
+		coupon *woo.Coupon
+		err    error
+	}
+	ordersLoadedMsg struct {
+		page *woo.OrderPage
+	}
+	orderDetailLoadedMsg struct {
 		order *woo.OrderResponse
+		notes []woo.OrderNote
+	}
+	trackingTickMsg struct {
+		session int
+	}
+	orderTrackedMsg struct {
+		session int
+		order   *woo.OrderResponse
+		notes   []woo.OrderNote
+		err     error
+	}
+	reorderCheckedMsg struct {
+		lines []ReorderLine
+	}
+	customerLoadedMsg struct {
+		customer *woo.Customer
+		err      error
+	}
+	orderUncertainMsg struct {
+		err      error         // Why placing the order may have failed
+		customer *woo.Customer // Account created before the order was sent
+	}
+	cartSavedMsg struct {
+		err error
+	}
+	orderFailedMsg struct {
+		err      error         // Why the store refused the order
+		customer *woo.Customer // Account created before the order was sent
+	}
+	orderNotPlacedMsg struct {
+		customer *woo.Customer // Account the order is placed for, nil for a guest
+	}
+	orderLookupFailedMsg struct {
+		err error
+	}
+	imageLoadedMsg struct {
+		url      string
+		rendered string
+		err      error
+	}
+	reviewsLoadedMsg struct {
+		productID int
+		page      *woo.ReviewPage
+		err       error
+	}
+	reviewSubmittedMsg struct {
+		review *woo.ProductReview
+		err    error
+	}
+	stockCheckedMsg struct {
+		products   map[int]woo.Product
+		variations map[int][]woo.Variation
+	}
+	orderCreatedMsg struct {
+		order       *woo.OrderResponse
+		customer    *woo.Customer // Account created or updated with the order
+		customerErr error         // Account failure; the order itself went through
 	}
 	errMsg struct {
 		err error
 	}
 )
 
-// NewModel creates a new TUI model.
-func NewModel(wooClient *woo.Client, productsCache *cache.Cache[ProductListCacheKey, []woo.Product], variationsCache *cache.Cache[int, []woo.Variation]) Model {
+// NewModel creates a new TUI model. Prices are formatted with the store settings,
+// which are fetched once at startup and shared by every session.
+func NewModel(wooClient *woo.Client, productsCache *cache.Cache[ProductListCacheKey, woo.ProductPage], variationsCache *cache.Cache[int, []woo.Variation], storeSettings woo.StoreSettings) Model {
 	styles := DefaultStyles()
 
 	// Initialize spinner
@@ -157,6 +467,18 @@ func NewModel(wooClient *woo.Client, productsCache *cache.Cache[ProductListCache
 	ti.CharLimit = 50
 	ti.Width = 30
 
+	// Initialize SKU input for the filter panel
+	skuInput := textinput.New()
+	skuInput.Placeholder = "Any SKU"
+	skuInput.CharLimit = 40
+	skuInput.Width = 20
+
+	// Initialize coupon input for the cart
+	couponInput := textinput.New()
+	couponInput.Placeholder = "Coupon code"
+	couponInput.CharLimit = 40
+	couponInput.Width = 20
+
 	// Initialize product list
 	delegate := list.NewDefaultDelegate()
 	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
@@ -173,31 +495,139 @@ func NewModel(wooClient *woo.Client, productsCache *cache.Cache[ProductListCache
 	productList.Styles.Title = styles.ListTitle
 
 	return Model{
+		ctx:             context.Background(),
 		wooClient:       wooClient,
 		productsCache:   productsCache,
 		variationsCache: variationsCache,
+		storeSettings:   storeSettings,
 		viewState:       ViewProductList,
 		styles:          styles,
 		productList:     productList,
 		searchInput:     ti,
+		skuInput:        skuInput,
+		couponInput:     couponInput,
 		listSpinner:     sp,
 		currentPage:     1,
 		perPage:         20,
-		localCart:       NewLocalCart(),
+		totalPages:      1,
+		localCart:       NewLocalCart(storeSettings),
 		customerInfo:    &CustomerInfo{},
 	}
 }
 
 // Init initializes the model.
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		m.listSpinner.Tick,
 		m.loadProducts(),
-	)
+	}
+	if m.customerID != 0 {
+		cmds = append(cmds, m.loadCustomer())
+	}
+	return tea.Batch(cmds...)
+}
+
+// WithCustomerLinks enables customer accounts for a session. The session's
+// key fingerprint is looked up in links: a linked customer has the checkout
+// form pre-filled and their orders placed on their account. An unlinked key
+// gets a new account, linked to it, when it places its first order.
+func (m Model) WithCustomerLinks(links CustomerLinker, fingerprint string) Model {
+	m.customerLinks = links
+	m.keyFingerprint = fingerprint
+	m.customerID, _ = links.CustomerID(fingerprint)
+	return m
+}
+
+// WithImages shows product images, downloaded with loader, in the details
+// view. The way they are drawn is picked from the client's TERM; dumb
+// terminals get a description instead.
+func (m Model) WithImages(loader *termimg.Loader, term string) Model {
+	m.imageLoader = loader
+	m.imageProtocol = termimg.DetectProtocol(term)
+	return m
+}
+
+// WithContext ties the session's long-running requests, such as order
+// tracking, to ctx, so they stop when the session ends.
+func (m Model) WithContext(ctx context.Context) Model {
+	m.ctx = ctx
+	return m
+}
+
+// WithLogger reports problems with the store setup that are worked around
+// without telling the customer, such as shipping methods left out because
+// their cost can't be worked out, to logf.
+func (m Model) WithLogger(logf func(format string, args ...any)) Model {
+	m.logf = logf
+	return m
+}
+
+// WithCartStore keeps the session's cart in store under the key
+// fingerprint. The cart saved by an earlier session is restored, with a
+// banner offering to resume it, and the cart is saved again after every
+// change, in the background; see FlushCart.
+func (m Model) WithCartStore(store CartStore, fingerprint string) Model {
+	m.cartStore = store
+	m.cartSaver = &cartSaver{store: store}
+	m.keyFingerprint = fingerprint
+
+	saved, err := store.Load(fingerprint)
+	if err != nil {
+		m.err = fmt.Errorf("restoring your cart: %w", err)
+		return m
+	}
+	m.localCart = RestoreLocalCart(saved, m.storeSettings)
+	m.savedCart = m.localCart.Snapshot(time.Now())
+	m.savedCartRevision = m.localCart.Revision()
+	if !m.localCart.IsEmpty() {
+		m.offerCartResume = true
+		m.cartSavedAt = saved.UpdatedAt
+	}
+	return m
+}
+
+// FlushCart waits until the cart saves started so far are written, for
+// reading the saved cart once the session is over.
+func (m Model) FlushCart() {
+	if m.cartSaver != nil {
+		m.cartSaver.wait()
+	}
 }
 
 // Update handles messages and updates the model.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if m, ok := next.(Model); ok {
+		m, save := m.saveCart()
+		return m, tea.Batch(cmd, save)
+	}
+	return next, cmd
+}
+
+// saveCart saves the cart in the background if it changed since it was
+// last saved, and returns a command reporting how it went. The revision
+// tells cheaply that nothing was touched; the snapshot comparison skips
+// changes that were undone.
+func (m Model) saveCart() (Model, tea.Cmd) {
+	if m.cartStore == nil || m.localCart.Revision() == m.savedCartRevision {
+		return m, nil
+	}
+	m.savedCartRevision = m.localCart.Revision()
+	m.offerCartResume = false
+	snapshot := m.localCart.Snapshot(time.Now())
+	if sameCart(snapshot, m.savedCart) {
+		return m, nil
+	}
+	// A failed save is retried on the next change, not on every message
+	m.savedCart = snapshot
+	m.cartSaves++
+	done := m.cartSaver.save(m.keyFingerprint, m.cartSaves, snapshot)
+	return m, func() tea.Msg {
+		return cartSavedMsg{err: <-done}
+	}
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -216,10 +646,91 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 
 	case productsLoadedMsg:
+		// Drop a page asked for before the page, search or filters changed
+		if msg.key != newProductListCacheKey(m.productsParams()) {
+			break
+		}
 		m.loadingProducts = false
-		m.products = msg.products
+		m.err = nil
+		m.products = msg.page.Products
+		m.currentPage = msg.page.Page
+		m.totalPages = msg.page.TotalPages
+		m.totalProducts = msg.page.TotalItems
 		m.updateProductList()
 
+	case categoriesLoadedMsg:
+		m.loadingCategories = false
+		m.categories = msg.categories
+
+	case shippingTableLoadedMsg:
+		m.loadingShipping = false
+		m.shippingTable = msg.table
+		m.updateShippingRates()
+
+	case couponCheckedMsg:
+		m.checkingCoupon = false
+		m.couponErr = msg.err
+		if msg.err == nil {
+			m.couponErr = m.localCart.ApplyCoupon(msg.coupon, time.Now())
+		}
+
+	case taxTableLoadedMsg:
+		m.loadingTax = false
+		m.taxTable = msg.table
+		if m.viewState == ViewReview {
+			m.estimateTax()
+		}
+
+	case paymentGatewaysLoadedMsg:
+		m.loadingGateways = false
+		m.paymentGateways = msg.gateways
+		m.paymentIdx = 0
+
+	case imageLoadedMsg:
+		// Ignore images of products no longer shown
+		if msg.url == m.productImageURL {
+			m.loadingImage = false
+			m.productImage = msg.rendered
+			m.imageErr = msg.err
+		}
+
+	case reviewsLoadedMsg:
+		// Ignore reviews of products no longer shown
+		if m.selectedProduct == nil || msg.productID != m.selectedProduct.ID {
+			break
+		}
+		m.loadingReviews = false
+		m.reviewsErr = msg.err
+		if msg.err == nil {
+			m.reviews = msg.page.Reviews
+			m.reviewsPage = msg.page.Page
+			m.reviewsTotalPages = msg.page.TotalPages
+			m.reviewsScroll = 0
+		}
+
+	case reviewSubmittedMsg:
+		m.submittingReview = false
+		if msg.err != nil {
+			// Show the form again with what was written
+			m.reviewErr = msg.err
+			m.initReviewForm()
+			cmds = append(cmds, m.reviewForm.Init())
+			break
+		}
+		m.reviewErr = nil
+		m.reviewDraft = nil
+		m.reviewForm = nil
+		m.viewState = ViewProductDetails
+		m.reviewNotice = "Thanks for your review! It will show up once the shop has approved it."
+		if m.selectedProduct == nil {
+			break
+		}
+		if msg.review.Status == "approved" {
+			m.reviewNotice = "Thanks for your review!"
+			addRating(m.selectedProduct, msg.review.Rating)
+		}
+		cmds = append(cmds, m.loadReviews(1))
+
 	case variationsLoadedMsg:
 		m.loadingVariations = false
 		m.productVariations = msg.variations
@@ -227,17 +738,154 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.initConfigurator()
 		}
 
+	case cartSavedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		}
+
+	case customerLoadedMsg:
+		if msg.err != nil {
+			// A deleted account is replaced by a new one on the next order;
+			// otherwise orders still go on the account, just without pre-filling
+			if errors.Is(msg.err, woo.ErrNotFound) {
+				m.customerID = 0
+			}
+			break
+		}
+		m.customer = msg.customer
+		if *m.customerInfo == (CustomerInfo{}) {
+			*m.customerInfo = customerInfoFrom(msg.customer)
+		}
+
+	case ordersLoadedMsg:
+		m.loadingOrders = false
+		m.orders = msg.page.Orders
+		m.ordersTotalPages = msg.page.TotalPages
+		m.orderIdx = 0
+
+	case orderDetailLoadedMsg:
+		m.loadingOrderDetail = false
+		m.selectedOrder = msg.order
+		m.orderNotes = msg.notes
+
+	case trackingTickMsg:
+		if msg.session == m.trackingSession && m.viewState == ViewTracking {
+			cmds = append(cmds, m.pollTrackedOrder())
+		}
+
+	case orderTrackedMsg:
+		if msg.session != m.trackingSession || m.viewState != ViewTracking {
+			break
+		}
+		changed := false
+		m.trackingErr = msg.err
+		if msg.err == nil {
+			m.orderResponse = msg.order
+			m.trackingSteps, changed = trackStatus(m.trackingSteps, msg.order.Status, time.Now())
+			if len(msg.notes) != len(m.trackingNotes) {
+				changed = true
+			}
+			m.trackingNotes = msg.notes
+		}
+		if changed {
+			m.trackingPolls = 0
+		} else {
+			m.trackingPolls++
+		}
+		if !isFinalOrderStatus(m.orderResponse.Status) {
+			session := m.trackingSession
+			cmds = append(cmds, tea.Tick(trackingDelay(m.trackingPolls), func(time.Time) tea.Msg {
+				return trackingTickMsg{session: session}
+			}))
+		}
+
+	case reorderCheckedMsg:
+		m.checkingReorder = false
+		m.reorderLines = msg.lines
+		if m.viewState == ViewOrderDetail {
+			m.viewState = ViewReorder
+		}
+
+	case orderUncertainMsg:
+		// The order may be in the store already; look before trying again
+		m.creatingOrder = false
+		m.checkingOrder = true
+		m.err = msg.err
+		if msg.customer != nil {
+			m.customer = msg.customer
+			m.customerID = msg.customer.ID
+		}
+		cmds = append(cmds, m.findPlacedOrder())
+
+	case orderFailedMsg:
+		// The account stays opened and linked, so a retry uses it
+		m.creatingOrder = false
+		m.checkingOrder = false
+		m.err = msg.err
+		if msg.customer != nil {
+			m.customer = msg.customer
+			m.customerID = msg.customer.ID
+		}
+
+	case orderNotPlacedMsg:
+		// Try again for the same account, so the order isn't placed as a guest
+		m.checkingOrder = false
+		m.orderUncertain = false
+		if msg.customer != nil {
+			m.customer = msg.customer
+			m.customerID = msg.customer.ID
+		}
+		if m.orderAttempts < maxOrderAttempts {
+			m.orderAttempts++
+			m.creatingOrder = true
+			m.err = nil
+			cmds = append(cmds, m.createOrder())
+		}
+
+	case orderLookupFailedMsg:
+		m.checkingOrder = false
+		m.orderUncertain = true
+		m.err = msg.err
+
+	case stockCheckedMsg:
+		m.checkingStock = false
+		m.stockIssues = m.localCart.CheckStock(msg.products, msg.variations)
+		m.priceChanges = m.localCart.UpdatePrices(msg.products, msg.variations)
+		if len(m.stockIssues) == 0 && len(m.priceChanges) == 0 && m.viewState == ViewReview {
+			cmds = append(cmds, m.placeOrder())
+		}
+
 	case orderCreatedMsg:
 		m.creatingOrder = false
+		m.checkingOrder = false
+		m.orderUncertain = false
+		m.orderToken = ""
 		m.orderResponse = msg.order
+		m.placedOrderIDs = append(m.placedOrderIDs, msg.order.ID)
 		m.viewState = ViewOrderConfirmation
 		m.localCart.Clear()
+		if msg.customer != nil {
+			m.customer = msg.customer
+			m.customerID = msg.customer.ID
+		}
+		if msg.customerErr != nil {
+			m.err = msg.customerErr
+		}
 
 	case errMsg:
 		m.err = msg.err
 		m.loadingProducts = false
 		m.loadingVariations = false
+		m.loadingCategories = false
+		m.loadingGateways = false
+		m.loadingShipping = false
+		m.loadingTax = false
+		m.loadingOrders = false
+		m.loadingOrderDetail = false
+		m.checkingReorder = false
+		m.checkingStock = false
 		m.creatingOrder = false
+		m.checkingOrder = false
 	}
 
 	// Update sub-models based on view state
@@ -260,6 +908,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.configForm = f
 				if m.configForm.State == huh.StateCompleted {
 					m.configCompleted = true
+					m.extractConfigFormValues()
 				}
 			}
 			cmds = append(cmds, cmd)
@@ -273,6 +922,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			cmds = append(cmds, cmd)
 		}
+
+	case ViewReviewForm:
+		if m.reviewForm != nil && !m.submittingReview {
+			form, cmd := m.reviewForm.Update(msg)
+			if f, ok := form.(*huh.Form); ok {
+				m.reviewForm = f
+				if m.reviewForm.State == huh.StateCompleted {
+					m.submittingReview = true
+					m.reviewErr = nil
+					cmd = m.submitReview()
+				}
+			}
+			cmds = append(cmds, cmd)
+		}
 	}
 
 	return m, tea.Batch(cmds...)
@@ -304,6 +967,24 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleReviewKeys(msg)
 	case ViewOrderConfirmation:
 		return m.handleOrderConfirmationKeys(msg)
+	case ViewCategoryPicker:
+		return m.handleCategoryPickerKeys(msg)
+	case ViewFilterPanel:
+		return m.handleFilterPanelKeys(msg)
+	case ViewPayment:
+		return m.handlePaymentKeys(msg)
+	case ViewShipping:
+		return m.handleShippingKeys(msg)
+	case ViewOrders:
+		return m.handleOrdersKeys(msg)
+	case ViewOrderDetail:
+		return m.handleOrderDetailKeys(msg)
+	case ViewReorder:
+		return m.handleReorderKeys(msg)
+	case ViewTracking:
+		return m.handleTrackingKeys(msg)
+	case ViewReviewForm:
+		return m.handleReviewFormKeys(msg)
 	}
 
 	return m, nil
@@ -317,6 +998,7 @@ func (m Model) handleProductListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "enter":
 			m.showSearch = false
 			m.searchInput.Blur()
+			m.currentPage = 1
 			return m, m.loadProducts()
 		case "esc":
 			m.showSearch = false
@@ -337,16 +1019,70 @@ func (m Model) handleProductListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "f":
 		m.inStockOnly = !m.inStockOnly
+		m.currentPage = 1
+		return m, m.loadProducts()
+
+	case "s":
+		// Cycle the sort order
+		m.sortIdx = (m.sortIdx + 1) % len(sortOptions)
+		m.currentPage = 1
 		return m, m.loadProducts()
 
+	case "F":
+		// Open the filter panel
+		m.viewState = ViewFilterPanel
+		m.filterRow = filterRowPrice
+		return m, nil
+
 	case "r":
 		return m, m.loadProducts()
 
+	case "b":
+		// Browse by category
+		m.viewState = ViewCategoryPicker
+		m.err = nil
+		if m.categories == nil && !m.loadingCategories {
+			m.loadingCategories = true
+			return m, m.loadCategories()
+		}
+		return m, nil
+
+	case "n":
+		// Next page
+		if m.currentPage < m.totalPages {
+			m.currentPage++
+			return m, m.loadProducts()
+		}
+		return m, nil
+
+	case "p":
+		// Previous page
+		if m.currentPage > 1 {
+			m.currentPage--
+			return m, m.loadProducts()
+		}
+		return m, nil
+
 	case "c":
 		m.viewState = ViewCart
 		m.localCart.SelectedIdx = 0
+		m.offerCartResume = false
+		return m, nil
+
+	case "x":
+		// Start fresh instead of resuming the cart from the last visit
+		if m.offerCartResume {
+			m.localCart.Clear()
+			m.offerCartResume = false
+		}
 		return m, nil
 
+	case "o":
+		// My orders
+		m.viewState = ViewOrders
+		m.ordersPage = 1
+		return m.reloadOrders()
+
 	case "enter":
 		if item, ok := m.productList.SelectedItem().(productItem); ok {
 			m.selectedProduct = &item.product
@@ -354,15 +1090,28 @@ func (m Model) handleProductListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.configCompleted = false
 			m.selectedVariation = nil
 			m.selectedGrindSize = ""
+			imageCmd := m.loadProductImage()
+
+			m.reviews = nil
+			m.reviewsPage = 1
+			m.reviewsTotalPages = 0
+			m.reviewDraft = nil
+			m.reviewErr = nil
+			m.reviewNotice = ""
+			var reviewsCmd tea.Cmd
+			if showsReviews(m.selectedProduct) {
+				reviewsCmd = m.loadReviews(1)
+			}
 
 			if m.selectedProduct.IsVariable() {
 				m.loadingVariations = true
-				return m, m.loadVariations(m.selectedProduct.ID)
+				return m, tea.Batch(m.loadVariations(m.selectedProduct.ID), imageCmd, reviewsCmd)
 			}
 			// For simple products, go directly to configurator if grind options exist
-			if attr := m.selectedProduct.GetAttribute("Grind Size"); attr != nil && len(attr.Options) > 0 {
-				m.initSimpleConfigurator()
+			if len(grindOptions(m.selectedProduct)) > 0 {
+				m.initConfigurator()
 			}
+			return m, tea.Batch(imageCmd, reviewsCmd)
 		}
 	}
 
@@ -371,6 +1120,110 @@ func (m Model) handleProductListKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m Model) handleCategoryPickerKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+
+	switch key {
+	case "esc", "backspace":
+		// A failed category load belongs to the picker only
+		m.viewState = ViewProductList
+		m.err = nil
+		return m, nil
+
+	case "up", "k":
+		if m.categoryIdx > 0 {
+			m.categoryIdx--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.categoryIdx < len(m.categories) {
+			m.categoryIdx++
+		}
+		return m, nil
+
+	case "enter":
+		if m.loadingCategories {
+			return m, nil
+		}
+		if m.categoryIdx == 0 {
+			m.selectedCategory = nil
+		} else {
+			category := m.categories[m.categoryIdx-1]
+			m.selectedCategory = &category
+		}
+		m.viewState = ViewProductList
+		m.currentPage = 1
+		return m, m.loadProducts()
+	}
+
+	return m, nil
+}
+
+func (m Model) handleFilterPanelKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+
+	switch key {
+	case "enter", "esc":
+		// Apply the filters and go back to the first page
+		m.skuInput.Blur()
+		m.viewState = ViewProductList
+		m.currentPage = 1
+		return m, m.loadProducts()
+
+	case "up", "shift+tab":
+		m.filterRow = (m.filterRow + filterRowCount - 1) % filterRowCount
+		return m, m.focusFilterRow()
+
+	case "down", "tab":
+		m.filterRow = (m.filterRow + 1) % filterRowCount
+		return m, m.focusFilterRow()
+	}
+
+	// The SKU row takes free text
+	if m.filterRow == filterRowSKU {
+		var cmd tea.Cmd
+		m.skuInput, cmd = m.skuInput.Update(msg)
+		return m, cmd
+	}
+
+	switch key {
+	case "left", "h":
+		m.cycleFilter(-1)
+	case "right", "l", " ":
+		m.cycleFilter(1)
+	}
+
+	return m, nil
+}
+
+// focusFilterRow focuses the SKU input when its row is selected.
+func (m *Model) focusFilterRow() tea.Cmd {
+	if m.filterRow == filterRowSKU {
+		m.skuInput.Focus()
+		return textinput.Blink
+	}
+	m.skuInput.Blur()
+	return nil
+}
+
+// cycleFilter changes the value of the selected filter row.
+// Toggles ignore the direction; the price row steps through priceRanges.
+func (m *Model) cycleFilter(step int) {
+	switch m.filterRow {
+	case filterRowPrice:
+		m.priceRangeIdx = (m.priceRangeIdx + step + len(priceRanges)) % len(priceRanges)
+	case filterRowOnSale:
+		m.onSaleOnly = !m.onSaleOnly
+	case filterRowFeatured:
+		m.featuredOnly = !m.featuredOnly
+	case filterRowInStock:
+		m.inStockOnly = !m.inStockOnly
+	case filterRowHideInCart:
+		m.hideInCart = !m.hideInCart
+	}
+}
+
 func (m Model) handleProductDetailsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
 
@@ -383,15 +1236,79 @@ func (m Model) handleProductDetailsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "c", "enter":
 		if m.selectedProduct != nil {
-			if m.selectedProduct.IsVariable() && len(m.productVariations) > 0 {
+			if m.canConfigure() {
 				m.initConfigurator()
 				m.viewState = ViewConfigurator
-			} else if attr := m.selectedProduct.GetAttribute("Grind Size"); attr != nil && len(attr.Options) > 0 {
-				m.initSimpleConfigurator()
-				m.viewState = ViewConfigurator
+				if m.configForm != nil {
+					return m, m.configForm.Init()
+				}
 			}
 		}
 		return m, nil
+
+	case "up", "k":
+		if m.reviewsScroll > 0 {
+			m.reviewsScroll--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.reviewsScroll < len(m.reviewListLines())-reviewListRows {
+			m.reviewsScroll++
+		}
+		return m, nil
+
+	case "n":
+		if !m.loadingReviews && m.reviewsPage < m.reviewsTotalPages {
+			return m, m.loadReviews(m.reviewsPage + 1)
+		}
+		return m, nil
+
+	case "p":
+		if !m.loadingReviews && m.reviewsPage > 1 {
+			return m, m.loadReviews(m.reviewsPage - 1)
+		}
+		return m, nil
+
+	case "w":
+		if m.selectedProduct != nil && m.selectedProduct.ReviewsAllowed && m.canReview() {
+			m.reviewNotice = ""
+			m.reviewErr = nil
+			m.initReviewForm()
+			m.viewState = ViewReviewForm
+			return m, m.reviewForm.Init()
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m Model) handleReviewFormKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.submittingReview {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		// The draft is kept in case the customer comes back to it
+		m.viewState = ViewProductDetails
+		m.reviewForm = nil
+		m.reviewErr = nil
+		return m, nil
+	}
+
+	if m.reviewForm != nil {
+		form, cmd := m.reviewForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.reviewForm = f
+			if m.reviewForm.State == huh.StateCompleted {
+				m.submittingReview = true
+				m.reviewErr = nil
+				return m, m.submitReview()
+			}
+		}
+		return m, cmd
 	}
 
 	return m, nil
@@ -410,7 +1327,11 @@ func (m Model) handleConfiguratorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "a":
 		// Add to cart if configuration is complete
 		if m.configCompleted && m.selectedProduct != nil {
-			m.addToCart()
+			if err := m.addToCart(); err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.err = nil
 			m.viewState = ViewCart
 			return m, nil
 		}
@@ -436,12 +1357,35 @@ func (m Model) handleConfiguratorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleCartKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
 
-	switch key {
-	case "esc", "backspace":
-		m.viewState = ViewProductList
-		return m, nil
-
-	case "up", "k":
+	if m.showCouponInput {
+		switch key {
+		case "enter":
+			code := strings.TrimSpace(m.couponInput.Value())
+			if code == "" || m.checkingCoupon {
+				return m, nil
+			}
+			m.showCouponInput = false
+			m.couponInput.Blur()
+			m.checkingCoupon = true
+			m.couponErr = nil
+			return m, m.checkCoupon(code)
+		case "esc":
+			m.showCouponInput = false
+			m.couponInput.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.couponInput, cmd = m.couponInput.Update(msg)
+		return m, cmd
+	}
+
+	m.cartErr = nil
+	switch key {
+	case "esc", "backspace":
+		m.viewState = ViewProductList
+		return m, nil
+
+	case "up", "k":
 		m.localCart.MoveUp()
 		return m, nil
 
@@ -451,6 +1395,10 @@ func (m Model) handleCartKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "+", "=":
 		if item := m.localCart.GetSelectedItem(); item != nil {
+			if err := m.localCart.CanAdd(*item, 1); err != nil {
+				m.cartErr = err
+				return m, nil
+			}
 			m.localCart.UpdateQuantity(m.localCart.SelectedIdx, item.Quantity+1)
 		}
 		return m, nil
@@ -467,12 +1415,34 @@ func (m Model) handleCartKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.localCart.RemoveItem(m.localCart.SelectedIdx)
 		return m, nil
 
+	case "c":
+		// Enter a coupon code
+		if !m.localCart.IsEmpty() && !m.checkingCoupon {
+			m.showCouponInput = true
+			m.couponErr = nil
+			m.couponInput.SetValue("")
+			return m, m.couponInput.Focus()
+		}
+		return m, nil
+
+	case "x":
+		m.localCart.RemoveCoupon()
+		m.couponErr = nil
+		return m, nil
+
 	case "o":
 		// Proceed to checkout - enter address
-		if !m.localCart.IsEmpty() {
-			m.initAddressForm()
-			m.viewState = ViewAddress
+		if m.localCart.IsEmpty() || m.checkingCoupon {
+			return m, nil
+		}
+		// The items may have changed since the coupon was applied
+		if err := m.localCart.ValidateCoupon(time.Now()); err != nil {
+			m.localCart.RemoveCoupon()
+			m.couponErr = err
+			return m, nil
 		}
+		m.initAddressForm()
+		m.viewState = ViewAddress
 		return m, nil
 
 	case "s":
@@ -499,10 +1469,11 @@ func (m Model) handleAddressKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		form, cmd := m.addressForm.Update(msg)
 		if f, ok := form.(*huh.Form); ok {
 			m.addressForm = f
-			// When form is completed, go to review
+			// When form is completed, choose how to ship
 			if m.addressForm.State == huh.StateCompleted {
-				m.viewState = ViewReview
-				return m, nil
+				m.customerInfo.Country = strings.ToUpper(strings.TrimSpace(m.customerInfo.Country))
+				m.customerInfo.State = strings.ToUpper(strings.TrimSpace(m.customerInfo.State))
+				return m.enterShipping()
 			}
 		}
 		return m, cmd
@@ -514,18 +1485,245 @@ func (m Model) handleAddressKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleReviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
 
+	if m.creatingOrder || m.checkingOrder || m.checkingStock {
+		return m, nil
+	}
+
 	switch key {
 	case "esc":
-		m.viewState = ViewAddress
+		m.viewState = ViewPayment
+		return m, nil
+
+	case "c":
+		// Update the cart after the stock check flagged some lines
+		if len(m.stockIssues) > 0 || len(m.priceChanges) > 0 {
+			m.stockIssues = nil
+			m.priceChanges = nil
+			m.viewState = ViewCart
+		}
+		return m, nil
+
+	case "r":
+		// Retry after the tax rates failed to load
+		if m.err != nil && m.storeSettings.TaxesEnabled && m.taxTable == nil && !m.loadingTax {
+			return m.enterReview()
+		}
 		return m, nil
 
 	case "enter", "p":
-		// Create order using WooCommerce v3 API
-		if !m.creatingOrder && !m.localCart.IsEmpty() {
-			m.creatingOrder = true
-			return m, m.createOrder()
+		// Check again whether the last attempt went through before placing anything
+		if m.orderUncertain {
+			m.checkingOrder = true
+			m.orderAttempts = 0
+			return m, m.findPlacedOrder()
+		}
+
+		// Check the stock and prices are unchanged, then create the order
+		if !m.loadingTax && !m.localCart.IsEmpty() && m.selectedGateway != nil && m.localCart.Shipping != nil {
+			m.checkingStock = true
+			m.stockIssues = nil
+			m.priceChanges = nil
+			m.err = nil
+			return m, m.checkStock()
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// placeOrder creates the order using the WooCommerce v3 API, with a new
+// token unless it is a retry for the same cart. Nothing is sent when no
+// token can be generated, and the error is shown instead.
+func (m *Model) placeOrder() tea.Cmd {
+	if m.orderToken == "" || m.orderTokenRevision != m.localCart.Revision() {
+		token, err := woo.NewOrderToken()
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.orderToken = token
+		m.orderTokenRevision = m.localCart.Revision()
+		m.orderAttemptedAt = time.Now()
+	}
+	m.creatingOrder = true
+	m.orderAttempts = 1
+	m.err = nil
+	m.orderTax = m.localCart.Tax
+	return m.createOrder()
+}
+
+// enterReview switches to the review step and works out the tax, loading
+// the tax rates the first time. Stores that don't charge tax skip this.
+func (m Model) enterReview() (tea.Model, tea.Cmd) {
+	m.viewState = ViewReview
+	m.err = nil
+	m.stockIssues = nil
+	m.priceChanges = nil
+	m.localCart.Tax = nil
+	if !m.storeSettings.TaxesEnabled {
+		return m, nil
+	}
+	if m.taxTable == nil {
+		if !m.loadingTax {
+			m.loadingTax = true
+			return m, m.loadTaxTable()
+		}
+		return m, nil
+	}
+	m.estimateTax()
+	return m, nil
+}
+
+// estimateTax works out the tax for the entered address, or the store
+// address when the store charges tax based on it.
+func (m *Model) estimateTax() {
+	if m.taxTable == nil {
+		return
+	}
+	loc := woo.TaxLocation{
+		Country:  m.customerInfo.Country,
+		State:    m.customerInfo.State,
+		Postcode: m.customerInfo.Postcode,
+		City:     m.customerInfo.City,
+	}
+	if m.taxTable.BasedOn == woo.TaxBasedOnBase {
+		loc = woo.TaxLocation{Country: m.storeSettings.DefaultCountry, State: m.storeSettings.DefaultState}
+	}
+	if err := m.localCart.EstimateTax(m.taxTable, loc); err != nil {
+		m.err = err
+	}
+}
+
+// enterShipping switches to the shipping step. The shipping zones are
+// loaded the first time; after that the rates are worked out locally.
+func (m Model) enterShipping() (tea.Model, tea.Cmd) {
+	m.viewState = ViewShipping
+	m.err = nil
+	if m.shippingTable == nil {
+		if !m.loadingShipping {
+			m.loadingShipping = true
+			return m, m.loadShippingTable()
+		}
+		return m, nil
+	}
+	m.updateShippingRates()
+	return m, nil
+}
+
+// updateShippingRates works out the shipping methods for the entered
+// address, keeping the chosen method selected if it still applies.
+func (m *Model) updateShippingRates() {
+	if m.shippingTable == nil {
+		return
+	}
+	pkg := m.localCart.ShippingPackage(m.customerInfo.Country, m.customerInfo.State, m.customerInfo.Postcode)
+	var skipped []error
+	m.shippingRates, skipped = m.shippingTable.Rates(pkg)
+	if m.logf != nil {
+		for _, err := range skipped {
+			m.logf("WARNING: Skipping %v", err)
+		}
+	}
+	m.shippingIdx = 0
+	if chosen := m.localCart.Shipping; chosen != nil {
+		for i, rate := range m.shippingRates {
+			if rate.InstanceID == chosen.InstanceID && rate.ZoneID == chosen.ZoneID {
+				m.shippingIdx = i
+			}
+		}
+	}
+}
+
+func (m Model) handleShippingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+
+	switch key {
+	case "esc", "backspace":
+		m.viewState = ViewAddress
+		return m, nil
+
+	case "up", "k":
+		if m.shippingIdx > 0 {
+			m.shippingIdx--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.shippingIdx < len(m.shippingRates)-1 {
+			m.shippingIdx++
+		}
+		return m, nil
+
+	case "r":
+		// Retry after a failed load
+		if m.err != nil && !m.loadingShipping {
+			m.err = nil
+			m.loadingShipping = true
+			return m, m.loadShippingTable()
 		}
 		return m, nil
+
+	case "enter":
+		if m.loadingShipping || len(m.shippingRates) == 0 {
+			return m, nil
+		}
+		rate := m.shippingRates[m.shippingIdx]
+		m.localCart.Shipping = &rate
+		return m.enterPayment()
+	}
+
+	return m, nil
+}
+
+// enterPayment switches to the payment step, loading the gateways the
+// first time it is shown.
+func (m Model) enterPayment() (tea.Model, tea.Cmd) {
+	m.viewState = ViewPayment
+	m.err = nil
+	if m.paymentGateways == nil && !m.loadingGateways {
+		m.loadingGateways = true
+		return m, m.loadPaymentGateways()
+	}
+	return m, nil
+}
+
+func (m Model) handlePaymentKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+
+	switch key {
+	case "esc", "backspace":
+		m.viewState = ViewShipping
+		return m, nil
+
+	case "up", "k":
+		if m.paymentIdx > 0 {
+			m.paymentIdx--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.paymentIdx < len(m.paymentGateways)-1 {
+			m.paymentIdx++
+		}
+		return m, nil
+
+	case "r":
+		// Retry after a failed load
+		if m.err != nil && !m.loadingGateways {
+			m.err = nil
+			m.loadingGateways = true
+			return m, m.loadPaymentGateways()
+		}
+		return m, nil
+
+	case "enter":
+		if m.loadingGateways || len(m.paymentGateways) == 0 {
+			return m, nil
+		}
+		gateway := m.paymentGateways[m.paymentIdx]
+		m.selectedGateway = &gateway
+		return m.enterReview()
 	}
 
 	return m, nil
@@ -536,81 +1734,322 @@ func (m Model) handleOrderConfirmationKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 
 	switch key {
 	case "enter", "esc", "q":
+		return m.continueShopping()
+
+	case "t":
+		// Watch the order's status
+		if m.orderResponse != nil && !isFinalOrderStatus(m.orderResponse.Status) {
+			m.viewState = ViewTracking
+			m.trackingSteps, _ = trackStatus(nil, m.orderResponse.Status, time.Now())
+			m.trackingNotes = nil
+			m.trackingErr = nil
+			return m.restartTracking()
+		}
+	}
+
+	return m, nil
+}
+
+// continueShopping leaves the order confirmation for the product list.
+func (m Model) continueShopping() (tea.Model, tea.Cmd) {
+	m.viewState = ViewProductList
+	m.orderResponse = nil
+	m.orderTax = nil
+	m.couponErr = nil
+	m.localCart.Clear()
+	m.err = nil
+	return m, nil
+}
+
+func (m Model) handleTrackingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+
+	switch key {
+	case "esc", "backspace":
+		// Stop polling
+		m.stopTracking()
+		m.viewState = ViewOrderConfirmation
+		return m, nil
+
+	case "r":
+		// Check now, and quickly again after
+		if !isFinalOrderStatus(m.orderResponse.Status) {
+			return m.restartTracking()
+		}
+
+	case "enter", "q":
+		m.stopTracking()
+		return m.continueShopping()
+	}
+
+	return m, nil
+}
+
+// restartTracking polls the order straight away, dropping any poll
+// already scheduled, and resets the backoff.
+func (m Model) restartTracking() (tea.Model, tea.Cmd) {
+	m.stopTracking()
+	m.trackingCtx, m.trackingCancel = context.WithCancel(m.ctx)
+	m.trackingPolls = 0
+	return m, m.pollTrackedOrder()
+}
+
+// stopTracking ends the tracking session, cancelling its requests.
+func (m *Model) stopTracking() {
+	m.trackingSession++
+	if m.trackingCancel != nil {
+		m.trackingCancel()
+		m.trackingCancel = nil
+	}
+}
+
+func (m Model) handleOrdersKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+
+	switch key {
+	case "esc", "backspace":
 		m.viewState = ViewProductList
-		m.orderResponse = nil
-		m.localCart.Clear()
 		m.err = nil
 		return m, nil
+
+	case "up", "k":
+		if m.orderIdx > 0 {
+			m.orderIdx--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.orderIdx < len(m.orders)-1 {
+			m.orderIdx++
+		}
+		return m, nil
+
+	case "n":
+		if !m.loadingOrders && m.ordersPage < m.ordersTotalPages {
+			m.ordersPage++
+			return m.reloadOrders()
+		}
+		return m, nil
+
+	case "p":
+		if !m.loadingOrders && m.ordersPage > 1 {
+			m.ordersPage--
+			return m.reloadOrders()
+		}
+		return m, nil
+
+	case "r":
+		return m.reloadOrders()
+
+	case "enter":
+		if m.loadingOrders || m.orderIdx >= len(m.orders) {
+			return m, nil
+		}
+		order := m.orders[m.orderIdx]
+		m.viewState = ViewOrderDetail
+		m.selectedOrder = &order
+		m.orderNotes = nil
+		m.loadingOrderDetail = true
+		m.err = nil
+		return m, m.loadOrderDetail(order.ID)
 	}
 
 	return m, nil
 }
 
-func (m *Model) extractConfigFormValues() {
-	if m.configForm == nil || m.selectedProduct == nil {
-		return
+func (m Model) handleOrderDetailKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+
+	switch key {
+	case "esc", "backspace":
+		m.viewState = ViewOrders
+		m.selectedOrder = nil
+		m.orderNotes = nil
+		m.err = nil
+		return m, nil
+
+	case "r":
+		if m.selectedOrder != nil && !m.loadingOrderDetail {
+			m.loadingOrderDetail = true
+			m.err = nil
+			return m, m.loadOrderDetail(m.selectedOrder.ID)
+		}
+
+	case "a":
+		// Order again
+		if m.selectedOrder != nil && !m.loadingOrderDetail && !m.checkingReorder && len(m.selectedOrder.LineItems) > 0 {
+			m.checkingReorder = true
+			m.err = nil
+			return m, m.checkReorder(*m.selectedOrder)
+		}
 	}
 
-	// Get form values - this is a bit tricky with huh forms
-	// The values are bound to the variables we passed in initConfigurator
-	// For now, we'll re-extract from the form's groups
+	return m, nil
+}
 
-	// Find the selected variation based on size
-	if m.selectedProduct.IsVariable() && len(m.productVariations) > 0 {
-		// Try to find selected size from form
-		for _, v := range m.productVariations {
-			// Default to first variation if we can't determine selection
-			if m.selectedVariation == nil {
-				m.selectedVariation = &v
+func (m Model) handleReorderKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+
+	switch key {
+	case "esc", "backspace":
+		m.viewState = ViewOrderDetail
+		m.reorderLines = nil
+		return m, nil
+
+	case "enter":
+		// Add what can still be bought and show the cart before checkout.
+		// Nothing is added unless all of it fits with what the cart holds.
+		var items []LocalCartItem
+		for _, line := range m.reorderLines {
+			if line.CanReorder() && line.Item.Quantity > 0 {
+				items = append(items, *line.Item)
 			}
 		}
+		if len(items) == 0 {
+			return m, nil
+		}
+		if err := m.localCart.CanAddAll(items); err != nil {
+			m.err = err
+			return m, nil
+		}
+		for _, item := range items {
+			// Checked above, so adding can't fail
+			_ = m.localCart.AddItem(item)
+		}
+		m.err = nil
+		m.reorderLines = nil
+		m.viewState = ViewCart
+		m.localCart.SelectedIdx = 0
+		return m, nil
 	}
+
+	return m, nil
 }
 
-func (m *Model) addToCart() {
-	if m.selectedProduct == nil {
+// reloadOrders fetches the current page of order history. Without an
+// account or an order placed in this session there is nothing to fetch.
+func (m Model) reloadOrders() (tea.Model, tea.Cmd) {
+	m.err = nil
+	if m.customerID == 0 && len(m.placedOrderIDs) == 0 {
+		m.orders = nil
+		m.ordersTotalPages = 0
+		return m, nil
+	}
+	m.loadingOrders = true
+	return m, m.loadOrders()
+}
+
+// extractConfigFormValues resolves the choices made in the configurator
+// to the variation and grind size that go in the cart.
+func (m *Model) extractConfigFormValues() {
+	c := m.configChoices
+	if c == nil || m.selectedProduct == nil {
 		return
 	}
 
+	m.selectedGrindSize = c.GrindSize
+	m.selectedVariation = nil
+	if m.selectedProduct.IsVariable() {
+		m.selectedVariation = matchVariation(m.productVariations, c.Attributes, c.Options)
+	}
+}
+
+func (m *Model) addToCart() error {
+	if m.selectedProduct == nil {
+		return nil
+	}
+
+	if m.selectedProduct.IsVariable() && m.selectedVariation == nil {
+		return fmt.Errorf("adding to cart: no variation for the options chosen: %w", woo.ErrNotFound)
+	}
+
+	quantity := 1
+	if c := m.configChoices; c != nil && c.Quantity != "" {
+		q, err := parseQuantity(c.Quantity)
+		if err != nil {
+			return err
+		}
+		quantity = q
+	}
+
 	// Create local cart item with grind size
-	item := NewLocalCartItemFromProduct(m.selectedProduct, m.selectedVariation, 1, m.selectedGrindSize)
-	m.localCart.AddItem(item)
+	item, err := NewLocalCartItemFromProduct(m.selectedProduct, m.selectedVariation, quantity, m.selectedGrindSize, m.storeSettings.Currency)
+	if err != nil {
+		return fmt.Errorf("adding to cart: %w", err)
+	}
+	if c := m.configChoices; c != nil && m.selectedVariation != nil {
+		item.Name = variationName(m.selectedProduct, c.Options)
+		// Options the variation accepts any of are only known from the
+		// choice; they are keyed like WooCommerce keys them in the order
+		for i, attr := range c.Attributes {
+			if variationOption(m.selectedVariation, attr) == "" {
+				item.Meta[attr.Key()] = c.Options[i]
+			}
+		}
+	}
+	if err := m.localCart.CanAdd(item, quantity); err != nil {
+		return fmt.Errorf("adding to cart: %w", err)
+	}
+	if err := m.localCart.AddItem(item); err != nil {
+		return fmt.Errorf("adding to cart: %w", err)
+	}
+	return nil
+}
+
+// canOpenAccount reports whether checkout can open an account for the
+// session: its key is known and not linked to an account yet.
+func (m Model) canOpenAccount() bool {
+	return m.customerID == 0 && m.customerLinks != nil && m.keyFingerprint != ""
 }
 
 func (m *Model) initAddressForm() {
+	if m.customerInfo.Country == "" {
+		m.customerInfo.Country = m.storeSettings.DefaultCountry
+	}
+
+	contact := []huh.Field{
+		huh.NewInput().
+			Title("First Name").
+			Value(&m.customerInfo.FirstName).
+			Validate(func(s string) error {
+				if s == "" {
+					return fmt.Errorf("first name is required")
+				}
+				return nil
+			}),
+		huh.NewInput().
+			Title("Last Name").
+			Value(&m.customerInfo.LastName).
+			Validate(func(s string) error {
+				if s == "" {
+					return fmt.Errorf("last name is required")
+				}
+				return nil
+			}),
+		huh.NewInput().
+			Title("Email").
+			Value(&m.customerInfo.Email).
+			Validate(func(s string) error {
+				if s == "" {
+					return fmt.Errorf("email is required")
+				}
+				if !strings.Contains(s, "@") {
+					return fmt.Errorf("invalid email format")
+				}
+				return nil
+			}),
+	}
+	if m.canOpenAccount() {
+		contact = append(contact, huh.NewConfirm().
+			Title("Create an account?").
+			Description("Your orders and address are kept for your SSH key.").
+			Value(&m.customerInfo.CreateAccount).
+			Affirmative("Yes").
+			Negative("No"))
+	}
+
 	m.addressForm = huh.NewForm(
-		huh.NewGroup(
-			huh.NewInput().
-				Title("First Name").
-				Value(&m.customerInfo.FirstName).
-				Validate(func(s string) error {
-					if s == "" {
-						return fmt.Errorf("first name is required")
-					}
-					return nil
-				}),
-			huh.NewInput().
-				Title("Last Name").
-				Value(&m.customerInfo.LastName).
-				Validate(func(s string) error {
-					if s == "" {
-						return fmt.Errorf("last name is required")
-					}
-					return nil
-				}),
-			huh.NewInput().
-				Title("Email").
-				Value(&m.customerInfo.Email).
-				Validate(func(s string) error {
-					if s == "" {
-						return fmt.Errorf("email is required")
-					}
-					if !strings.Contains(s, "@") {
-						return fmt.Errorf("invalid email format")
-					}
-					return nil
-				}),
-		),
+		huh.NewGroup(contact...),
 		huh.NewGroup(
 			huh.NewInput().
 				Title("Street Address").
@@ -618,13 +2057,18 @@ func (m *Model) initAddressForm() {
 			huh.NewInput().
 				Title("City").
 				Value(&m.customerInfo.City),
+			huh.NewInput().
+				Title("State / Province code (optional)").
+				Value(&m.customerInfo.State).
+				Placeholder("e.g. RM"),
 			huh.NewInput().
 				Title("Postcode").
 				Value(&m.customerInfo.Postcode),
 			huh.NewInput().
 				Title("Country (2-letter code)").
 				Value(&m.customerInfo.Country).
-				Placeholder("US"),
+				Placeholder(m.storeSettings.DefaultCountry).
+				Validate(validateCountry),
 			huh.NewConfirm().
 				Key("enter").
 				Value(&m.customerInfo.AddressConfirmed).
@@ -641,17 +2085,70 @@ func (m *Model) initAddressForm() {
 	).WithShowHelp(true).WithShowErrors(true)
 }
 
+// canReview reports whether the session's customer can write reviews.
+// Reviews are signed with the account linked to the SSH key, so guests,
+// whose email address proves nothing, can't write them.
+func (m Model) canReview() bool {
+	return m.customerID != 0 && m.customer != nil && m.customer.Email != ""
+}
+
+func (m *Model) initReviewForm() {
+	if m.reviewDraft == nil {
+		m.reviewDraft = &reviewDraft{Rating: 5}
+	}
+
+	m.reviewForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[int]().
+				Title("Rating").
+				Options(
+					huh.NewOption("★★★★★ Excellent", 5),
+					huh.NewOption("★★★★☆ Good", 4),
+					huh.NewOption("★★★☆☆ Average", 3),
+					huh.NewOption("★★☆☆☆ Poor", 2),
+					huh.NewOption("★☆☆☆☆ Terrible", 1),
+				).
+				Value(&m.reviewDraft.Rating),
+			huh.NewText().
+				Title("Your review").
+				CharLimit(2000).
+				Value(&m.reviewDraft.Review).
+				Validate(func(s string) error {
+					if strings.TrimSpace(s) == "" {
+						return fmt.Errorf("please write a few words about it")
+					}
+					return nil
+				}),
+		),
+	).WithShowHelp(true).WithShowErrors(true)
+}
+
+// validateCountry accepts ISO 3166-1 alpha-2 codes such as "IT".
+// Shipping zones are matched on the code, so it is required.
+func validateCountry(s string) error {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) != 2 || s[0] < 'A' || s[0] > 'Z' || s[1] < 'A' || s[1] > 'Z' {
+		return fmt.Errorf("enter a 2-letter country code, e.g. IT")
+	}
+	return nil
+}
+
 // Order commands
 
+// maxOrderAttempts is how many times placing an order is tried before the
+// error is shown, when the store may not have received it.
+const maxOrderAttempts = 3
+
 func (m Model) createOrder() tea.Cmd {
 	return func() tea.Msg {
 		if m.localCart.IsEmpty() {
 			return errMsg{err: fmt.Errorf("cart is empty")}
 		}
-
-		country := m.customerInfo.Country
-		if country == "" {
-			country = "IT" // Default to Italy
+		if m.selectedGateway == nil {
+			return errMsg{err: fmt.Errorf("no payment method selected")}
+		}
+		if m.localCart.Shipping == nil {
+			return errMsg{err: fmt.Errorf("no shipping method selected")}
 		}
 
 		address := woo.BillingAddress{
@@ -660,8 +2157,9 @@ func (m Model) createOrder() tea.Cmd {
 			Email:     m.customerInfo.Email,
 			Address1:  m.customerInfo.Address,
 			City:      m.customerInfo.City,
+			State:     m.customerInfo.State,
 			Postcode:  m.customerInfo.Postcode,
-			Country:   country,
+			Country:   m.customerInfo.Country,
 		}
 
 		// Build line items from local cart
@@ -675,48 +2173,242 @@ func (m Model) createOrder() tea.Cmd {
 			// Add grind size metadata if present
 			if item.GrindSize != "" {
 				lineItems[i].MetaData = []woo.OrderLineItemMetaData{
-					{Key: "pa_grind-size", Value: item.GrindSize},
+					woo.StringLineItemMetaData("pa_grind-size", item.GrindSize),
 				}
 			}
+			// Options picked for attributes the variation takes any of
+			keys := make([]string, 0, len(item.Meta))
+			for key := range item.Meta {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				lineItems[i].MetaData = append(lineItems[i].MetaData, woo.StringLineItemMetaData(key, item.Meta[key]))
+			}
 		}
 
-		// Calculate shipping locally
-		shippingCost := m.localCart.CalculateShipping()
-		shippingMethodID := "flat_rate"
-		shippingMethodTitle := "Flat Rate"
-		if shippingCost == 0 {
-			shippingMethodID = "free_shipping"
-			shippingMethodTitle = "Free Shipping"
+		// Shipping method chosen for this address
+		shipping := m.localCart.Shipping
+		itemNames := make([]string, len(m.localCart.Items))
+		for i, item := range m.localCart.Items {
+			itemNames[i] = fmt.Sprintf("%s × %d", item.GetDisplayName(), item.Quantity)
 		}
 
 		req := woo.OrderRequest{
-			PaymentMethod:      "bacs",
-			PaymentMethodTitle: "Bank Transfer",
+			PaymentMethod:      m.selectedGateway.ID,
+			PaymentMethodTitle: m.selectedGateway.Title,
 			SetPaid:            false,
 			Billing:            address,
 			Shipping:           &address,
 			LineItems:          lineItems,
 			ShippingLines: []woo.ShippingLine{
 				{
-					MethodID:    shippingMethodID,
-					MethodTitle: shippingMethodTitle,
-					Total:       fmt.Sprintf("%.2f", shippingCost),
+					MethodID:    shipping.MethodID,
+					MethodTitle: shipping.Title,
+					InstanceID:  strconv.Itoa(shipping.InstanceID),
+					Total:       shipping.Cost.Round(m.storeSettings.Decimals).String(),
+					MetaData: []woo.OrderLineItemMetaData{
+						woo.StringLineItemMetaData("Items", strings.Join(itemNames, ", ")),
+					},
 				},
 			},
 		}
+		if coupon := m.localCart.Coupon; coupon != nil {
+			req.CouponLines = []woo.CouponLine{{Code: coupon.Code}}
+		}
+		req.MetaData = []woo.OrderMetaData{woo.StringMetaData(woo.OrderTokenMetaKey, m.orderToken)}
+
+		// A first order from an unlinked key opens an account for it, if asked
+		var customer *woo.Customer
+		var customerErr error
+		req.CustomerID = m.customerID
+		if req.CustomerID == 0 && m.customerInfo.CreateAccount && m.canOpenAccount() {
+			customer, customerErr = m.createCustomer(address)
+			if customer != nil {
+				req.CustomerID = customer.ID
+			}
+		}
 
 		order, err := m.wooClient.CreateOrder(context.Background(), req)
 		if err != nil {
-			return errMsg{err: fmt.Errorf("creating order: %w", err)}
+			if woo.MayHaveSucceeded(err) {
+				return orderUncertainMsg{err: fmt.Errorf("creating order: %w", err), customer: customer}
+			}
+			return orderFailedMsg{err: fmt.Errorf("creating order: %w", err), customer: customer}
+		}
+
+		// Keep the account's addresses in step with the latest order
+		if m.customer != nil {
+			customer, customerErr = m.updateCustomerAddress(address)
 		}
-		return orderCreatedMsg{order: order}
+		return orderCreatedMsg{order: order, customer: customer, customerErr: customerErr}
 	}
 }
 
+// checkStock fetches the current stock of the products in the cart,
+// bypassing the caches.
+func (m Model) checkStock() tea.Cmd {
+	var ids, variable []int
+	seen := make(map[int]bool)
+	for _, item := range m.localCart.Items {
+		if seen[item.ProductID] {
+			continue
+		}
+		seen[item.ProductID] = true
+		ids = append(ids, item.ProductID)
+		if item.VariationID != 0 {
+			variable = append(variable, item.ProductID)
+		}
+	}
+
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		products := make(map[int]woo.Product)
+		it := m.wooClient.AllProducts(ctx, woo.GetProductsParams{Include: ids})
+		defer it.Close()
+		for it.Next() {
+			products[it.Value().ID] = it.Value()
+		}
+		if err := it.Err(); err != nil {
+			return errMsg{err: fmt.Errorf("checking stock: %w", err)}
+		}
+
+		variations := make(map[int][]woo.Variation)
+		for _, id := range variable {
+			if _, ok := products[id]; !ok {
+				continue
+			}
+			fetched, err := m.wooClient.GetVariations(ctx, id)
+			if err != nil && !errors.Is(err, woo.ErrNotFound) {
+				return errMsg{err: fmt.Errorf("checking stock: %w", err)}
+			}
+			if err == nil {
+				m.variationsCache.Set(id, fetched)
+			}
+			variations[id] = fetched
+		}
+
+		return stockCheckedMsg{products: products, variations: variations}
+	}
+}
+
+// findPlacedOrder looks for the order placed with the current token,
+// among the customer's or billing email's orders since it was first sent.
+func (m Model) findPlacedOrder() tea.Cmd {
+	params := woo.ListOrdersParams{After: m.orderAttemptedAt}
+	if m.customerID != 0 {
+		params.Customer = m.customerID
+	} else {
+		params.BillingEmail = m.customerInfo.Email
+	}
+	token := m.orderToken
+	customer := m.customer
+
+	return func() tea.Msg {
+		order, err := m.wooClient.FindOrderByToken(context.Background(), token, params)
+		if errors.Is(err, woo.ErrNotFound) {
+			return orderNotPlacedMsg{customer: customer}
+		}
+		if err != nil {
+			return orderLookupFailedMsg{err: err}
+		}
+		// The address is saved to the account on the next order instead
+		return orderCreatedMsg{order: order, customer: customer}
+	}
+}
+
+// Customer account commands
+
+func (m Model) loadCustomer() tea.Cmd {
+	id := m.customerID
+	return func() tea.Msg {
+		customer, err := m.wooClient.GetCustomer(context.Background(), id)
+		return customerLoadedMsg{customer: customer, err: err}
+	}
+}
+
+// createCustomer opens an account with the checkout address and links it to
+// the session's key. An email address that already has an account is not
+// linked, since the key holder can't be shown to own it; the order is then
+// placed as a guest.
+func (m Model) createCustomer(address woo.BillingAddress) (*woo.Customer, error) {
+	shipping := address
+	shipping.Email = ""
+	customer, err := m.wooClient.CreateCustomer(context.Background(), woo.CustomerRequest{
+		Email:     address.Email,
+		FirstName: address.FirstName,
+		LastName:  address.LastName,
+		Billing:   &address,
+		Shipping:  &shipping,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating your account: %w", err)
+	}
+	if err := m.customerLinks.Link(m.keyFingerprint, customer.ID); err != nil {
+		return nil, fmt.Errorf("linking your account: %w", err)
+	}
+	return customer, nil
+}
+
+// updateCustomerAddress saves the checkout address to the linked account
+// if it changed. Fields the form doesn't ask for, like the phone number,
+// are kept.
+func (m Model) updateCustomerAddress(address woo.BillingAddress) (*woo.Customer, error) {
+	billing := address
+	billing.Phone = m.customer.Billing.Phone
+	billing.Address2 = m.customer.Billing.Address2
+	shipping := address
+	shipping.Email = ""
+	shipping.Phone = m.customer.Shipping.Phone
+	shipping.Address2 = m.customer.Shipping.Address2
+	if billing == m.customer.Billing && shipping == m.customer.Shipping {
+		return nil, nil
+	}
+
+	customer, err := m.wooClient.UpdateCustomer(context.Background(), m.customer.ID, woo.CustomerRequest{
+		Billing:  &billing,
+		Shipping: &shipping,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("saving your address: %w", err)
+	}
+	return customer, nil
+}
+
+// customerInfoFrom pre-fills checkout from a customer account, preferring
+// the shipping address since that is where the order goes.
+func customerInfoFrom(c *woo.Customer) CustomerInfo {
+	address := c.Shipping
+	if address.Address1 == "" && address.City == "" {
+		address = c.Billing
+	}
+	info := CustomerInfo{
+		FirstName: address.FirstName,
+		LastName:  address.LastName,
+		Email:     c.Billing.Email,
+		Address:   address.Address1,
+		City:      address.City,
+		State:     address.State,
+		Postcode:  address.Postcode,
+		Country:   address.Country,
+	}
+	if info.FirstName == "" {
+		info.FirstName = c.FirstName
+	}
+	if info.LastName == "" {
+		info.LastName = c.LastName
+	}
+	if info.Email == "" {
+		info.Email = c.Email
+	}
+	return info
+}
+
 func (m *Model) updateProductList() {
 	items := make([]list.Item, len(m.products))
 	for i, p := range m.products {
-		items[i] = productItem{product: p, styles: m.styles}
+		items[i] = productItem{product: p, styles: m.styles, settings: m.storeSettings}
 	}
 	m.productList.SetItems(items)
 }
@@ -724,39 +2416,328 @@ func (m *Model) updateProductList() {
 func (m Model) loadProducts() tea.Cmd {
 	m.loadingProducts = true
 
+	params := m.productsParams()
+
 	return func() tea.Msg {
-		cacheKey := ProductListCacheKey{
-			Page:        m.currentPage,
-			PerPage:     m.perPage,
-			Search:      m.searchInput.Value(),
-			InStockOnly: m.inStockOnly,
-		}
+		cacheKey := newProductListCacheKey(params)
 
 		// Check cache first
-		if products, ok := m.productsCache.Get(cacheKey); ok {
-			return productsLoadedMsg{products: products}
+		if page, ok := m.productsCache.Get(cacheKey); ok {
+			return productsLoadedMsg{key: cacheKey, page: page}
 		}
 
 		// Fetch from API
-		params := woo.GetProductsParams{
-			Page:        m.currentPage,
-			PerPage:     m.perPage,
-			Search:      m.searchInput.Value(),
-			InStockOnly: m.inStockOnly,
+		page, err := m.wooClient.GetProducts(context.Background(), params)
+		if err != nil {
+			return errMsg{err: err}
+		}
+
+		// Cache the result
+		m.productsCache.Set(cacheKey, *page)
+
+		return productsLoadedMsg{key: cacheKey, page: *page}
+	}
+}
+
+// productsParams builds the product list request from the current page,
+// search, category, sort order and filters.
+func (m Model) productsParams() woo.GetProductsParams {
+	sorting := sortOptions[m.sortIdx]
+	price := priceRanges[m.priceRangeIdx]
+
+	params := woo.GetProductsParams{
+		Page:        m.currentPage,
+		PerPage:     m.perPage,
+		Search:      m.searchInput.Value(),
+		InStockOnly: m.inStockOnly,
+		Category:    m.categoryID(),
+		OrderBy:     sorting.orderBy,
+		Order:       sorting.order,
+		MinPrice:    price.minPrice,
+		MaxPrice:    price.maxPrice,
+		OnSale:      m.onSaleOnly,
+		Featured:    m.featuredOnly,
+		SKU:         strings.TrimSpace(m.skuInput.Value()),
+	}
+	if m.hideInCart {
+		params.Exclude = m.cartProductIDs()
+	}
+	return params
+}
+
+// cartProductIDs returns the distinct product IDs in the cart, sorted.
+func (m Model) cartProductIDs() []int {
+	seen := make(map[int]bool)
+	var ids []int
+	for _, item := range m.localCart.Items {
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			ids = append(ids, item.ProductID)
 		}
+	}
+	sort.Ints(ids)
+	return ids
+}
 
-		products, err := m.wooClient.GetProducts(context.Background(), params)
+// activeFilters describes the search, category, sort order and filters
+// currently applied to the product list, for the status line.
+func (m Model) activeFilters() []string {
+	var filters []string
+	if search := m.searchInput.Value(); search != "" {
+		filters = append(filters, fmt.Sprintf("%q", search))
+	}
+	if m.selectedCategory != nil {
+		filters = append(filters, m.selectedCategory.Name)
+	}
+	if m.sortIdx != 0 {
+		filters = append(filters, "Sort: "+sortOptions[m.sortIdx].label)
+	}
+	if m.priceRangeIdx != 0 {
+		filters = append(filters, priceRanges[m.priceRangeIdx].label)
+	}
+	if m.onSaleOnly {
+		filters = append(filters, "On Sale")
+	}
+	if m.featuredOnly {
+		filters = append(filters, "Featured")
+	}
+	if m.inStockOnly {
+		filters = append(filters, "In Stock Only")
+	}
+	if m.hideInCart {
+		filters = append(filters, "Not In Cart")
+	}
+	if sku := strings.TrimSpace(m.skuInput.Value()); sku != "" {
+		filters = append(filters, "SKU: "+sku)
+	}
+	return filters
+}
+
+// categoryID returns the ID of the selected category, or 0 for all.
+func (m Model) categoryID() int {
+	if m.selectedCategory == nil {
+		return 0
+	}
+	return m.selectedCategory.ID
+}
+
+func (m Model) loadCategories() tea.Cmd {
+	return func() tea.Msg {
+		categories, err := m.wooClient.GetCategories(context.Background(), woo.GetTermsParams{HideEmpty: true})
 		if err != nil {
 			return errMsg{err: err}
 		}
+		return categoriesLoadedMsg{categories: categories}
+	}
+}
 
-		// Cache the result
-		m.productsCache.Set(cacheKey, products)
+func (m Model) loadShippingTable() tea.Cmd {
+	return func() tea.Msg {
+		table, err := m.wooClient.GetShippingTable(context.Background())
+		if err != nil {
+			return errMsg{err: fmt.Errorf("loading shipping methods: %w", err)}
+		}
+		return shippingTableLoadedMsg{table: table}
+	}
+}
+
+func (m Model) checkCoupon(code string) tea.Cmd {
+	return func() tea.Msg {
+		coupon, err := m.wooClient.GetCouponByCode(context.Background(), code)
+		if err != nil {
+			return couponCheckedMsg{err: fmt.Errorf("checking coupon: %w", err)}
+		}
+		return couponCheckedMsg{coupon: coupon}
+	}
+}
 
-		return productsLoadedMsg{products: products}
+func (m Model) loadTaxTable() tea.Cmd {
+	return func() tea.Msg {
+		table, err := m.wooClient.GetTaxTable(context.Background())
+		if err != nil {
+			return errMsg{err: fmt.Errorf("loading tax rates: %w", err)}
+		}
+		return taxTableLoadedMsg{table: table}
 	}
 }
 
+func (m Model) loadOrders() tea.Cmd {
+	params := woo.ListOrdersParams{
+		Page:    m.ordersPage,
+		PerPage: 10,
+	}
+	if m.customerID != 0 {
+		params.Customer = m.customerID
+	} else {
+		params.Include = m.placedOrderIDs
+	}
+
+	return func() tea.Msg {
+		page, err := m.wooClient.ListOrders(context.Background(), params)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("loading orders: %w", err)}
+		}
+		return ordersLoadedMsg{page: page}
+	}
+}
+
+func (m Model) loadOrderDetail(orderID int) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		order, err := m.wooClient.GetOrder(ctx, orderID)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("loading order: %w", err)}
+		}
+		notes, err := m.wooClient.GetCustomerOrderNotes(ctx, orderID)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("loading order notes: %w", err)}
+		}
+		return orderDetailLoadedMsg{order: order, notes: notes}
+	}
+}
+
+// pollTrackedOrder fetches the tracked order and its notes for the customer,
+// in the tracking session's context. Nothing is reported once it ends.
+func (m Model) pollTrackedOrder() tea.Cmd {
+	orderID := m.orderResponse.ID
+	session := m.trackingSession
+	ctx := m.trackingCtx
+	return func() tea.Msg {
+		order, err := m.wooClient.GetOrder(ctx, orderID)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return orderTrackedMsg{session: session, err: err}
+		}
+		notes, err := m.wooClient.GetCustomerOrderNotes(ctx, orderID)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return orderTrackedMsg{session: session, err: err}
+		}
+		return orderTrackedMsg{session: session, order: order, notes: notes}
+	}
+}
+
+// checkReorder fetches the current products and variations of a past
+// order, bypassing the caches, and compares them with what was bought.
+// The variations fetched refresh the cache.
+func (m Model) checkReorder(order woo.OrderResponse) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		var ids []int
+		seen := make(map[int]bool)
+		for _, item := range order.LineItems {
+			if !seen[item.ProductID] {
+				seen[item.ProductID] = true
+				ids = append(ids, item.ProductID)
+			}
+		}
+
+		products := make(map[int]woo.Product)
+		it := m.wooClient.AllProducts(ctx, woo.GetProductsParams{Include: ids})
+		defer it.Close()
+		for it.Next() {
+			products[it.Value().ID] = it.Value()
+		}
+		if err := it.Err(); err != nil {
+			return errMsg{err: fmt.Errorf("checking products: %w", err)}
+		}
+
+		variations := make(map[int][]woo.Variation)
+		for _, item := range order.LineItems {
+			product, ok := products[item.ProductID]
+			if item.VariationID == 0 || !ok {
+				continue
+			}
+			if _, done := variations[product.ID]; done {
+				continue
+			}
+			fetched, err := m.wooClient.GetVariations(ctx, product.ID)
+			if err != nil && !errors.Is(err, woo.ErrNotFound) {
+				return errMsg{err: fmt.Errorf("checking product options: %w", err)}
+			}
+			if err == nil {
+				m.variationsCache.Set(product.ID, fetched)
+			}
+			variations[product.ID] = fetched
+		}
+
+		return reorderCheckedMsg{lines: BuildReorder(&order, products, variations, m.storeSettings)}
+	}
+}
+
+func (m Model) loadPaymentGateways() tea.Cmd {
+	return func() tea.Msg {
+		gateways, err := m.wooClient.GetAvailablePaymentGateways(context.Background())
+		if err != nil {
+			return errMsg{err: fmt.Errorf("loading payment methods: %w", err)}
+		}
+		return paymentGatewaysLoadedMsg{gateways: gateways}
+	}
+}
+
+// loadProductImage starts loading the selected product's main image, if
+// it has one and the terminal can show it.
+func (m *Model) loadProductImage() tea.Cmd {
+	m.productImage = ""
+	m.productImageURL = ""
+	m.loadingImage = false
+	m.imageErr = nil
+
+	img := m.selectedProduct.MainImage()
+	if img == nil || m.imageLoader == nil || m.imageProtocol == termimg.ProtocolText {
+		return nil
+	}
+	m.productImageURL = img.Src
+	m.loadingImage = true
+
+	loader, protocol, url := m.imageLoader, m.imageProtocol, img.Src
+	return func() tea.Msg {
+		rendered, err := loader.Render(context.Background(), url, productImageCols, productImageRows, protocol)
+		return imageLoadedMsg{url: url, rendered: rendered, err: err}
+	}
+}
+
+// loadReviews fetches a page of the selected product's reviews. The page
+// shown only changes once it has loaded, so a failure keeps the last one.
+func (m *Model) loadReviews(page int) tea.Cmd {
+	m.loadingReviews = true
+	m.reviewsErr = nil
+
+	params := woo.ListReviewsParams{
+		Product: m.selectedProduct.ID,
+		Page:    page,
+		PerPage: reviewsPerPage,
+	}
+	return func() tea.Msg {
+		page, err := m.wooClient.ListProductReviews(context.Background(), params)
+		return reviewsLoadedMsg{productID: params.Product, page: page, err: err}
+	}
+}
+
+func (m Model) submitReview() tea.Cmd {
+	req := woo.ProductReviewRequest{
+		ProductID:     m.selectedProduct.ID,
+		Review:        strings.TrimSpace(m.reviewDraft.Review),
+		Reviewer:      reviewerName(m.customer),
+		ReviewerEmail: m.customer.Email,
+		Rating:        m.reviewDraft.Rating,
+	}
+	return func() tea.Msg {
+		review, err := m.wooClient.CreateProductReview(context.Background(), req)
+		return reviewSubmittedMsg{review: review, err: err}
+	}
+}
+
+// reviewListLines lays out the loaded reviews for the product details.
+func (m Model) reviewListLines() []string {
+	return reviewLines(m.reviews, m.descriptionWidth(), m.styles)
+}
+
 func (m Model) loadVariations(productID int) tea.Cmd {
 	return func() tea.Msg {
 		// Check cache first
@@ -777,99 +2758,96 @@ func (m Model) loadVariations(productID int) tea.Cmd {
 	}
 }
 
-func (m *Model) initConfigurator() {
-	if m.selectedProduct == nil || !m.selectedProduct.IsVariable() {
-		return
-	}
-
-	// Build size options from variations
-	var sizeOptions []huh.Option[string]
-	sizeAttr := m.selectedProduct.GetAttribute("Size")
-	if sizeAttr != nil {
-		for _, opt := range sizeAttr.Options {
-			// Find the variation with this size to get its price
-			var price string
-			for _, v := range m.productVariations {
-				if v.GetAttributeValue("Size") == opt {
-					price = v.GetDisplayPrice()
-					break
-				}
-			}
-			label := opt
-			if price != "" {
-				label = fmt.Sprintf("%s ($%s)", opt, price)
-			}
-			sizeOptions = append(sizeOptions, huh.NewOption(label, opt))
-		}
+// canConfigure reports whether the selected product has anything to pick
+// in the configurator: a variation, once they are loaded, or a grind size.
+func (m Model) canConfigure() bool {
+	if m.selectedProduct.IsVariable() {
+		return len(m.productVariations) > 0
 	}
+	return len(grindOptions(m.selectedProduct)) > 0
+}
 
-	// Build grind options
-	var grindOptions []huh.Option[string]
-	grindAttr := m.selectedProduct.GetAttribute("Grind Size")
-	if grindAttr != nil {
-		for _, opt := range grindAttr.Options {
-			grindOptions = append(grindOptions, huh.NewOption(opt, opt))
-		}
-	} else {
-		grindOptions = []huh.Option[string]{huh.NewOption("Whole Beans", "Whole Beans")}
+// initConfigurator builds the configurator form for the selected product:
+// a select for each attribute its variations differ by, the grind size if
+// it offers one, and the quantity. Options no variation can be ordered
+// with, and quantities over the stock, are refused.
+func (m *Model) initConfigurator() {
+	m.configForm = nil
+	m.configCompleted = false
+	m.selectedVariation = nil
+	m.selectedGrindSize = ""
+	if m.selectedProduct == nil {
+		return
 	}
 
-	var selectedSize string
-	var selectedGrind string
+	c := &configChoices{Quantity: "1"}
+	if m.selectedProduct.IsVariable() {
+		c.Attributes = variationAttributes(m.selectedProduct)
+		c.Options = make([]string, len(c.Attributes))
+	}
+	m.configChoices = c
+	product, variations, cart := m.selectedProduct, m.productVariations, m.localCart
 
-	// Build form groups
 	var groups []*huh.Group
+	for i, attr := range c.Attributes {
+		i := i
+		var options []huh.Option[string]
+		for _, opt := range attr.Options {
+			options = append(options, huh.NewOption(optionLabel(m.storeSettings, variations, c.Attributes, i, opt), opt))
+			// Start on the first option that can be ordered
+			if c.Options[i] == "" && len(optionVariations(variations, c.Attributes, i, opt)) > 0 {
+				c.Options[i] = opt
+			}
+		}
+		groups = append(groups, huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Select "+attr.Name).
+				Options(options...).
+				Value(&c.Options[i]).
+				Validate(func(opt string) error {
+					if len(optionVariations(variations, c.Attributes, i, opt)) == 0 {
+						return fmt.Errorf("%s is out of stock", opt)
+					}
+					options := slices.Clone(c.Options)
+					options[i] = opt
+					return combinationError(variations, c.Attributes, options, i)
+				}),
+		))
+	}
 
-	if len(sizeOptions) > 0 {
+	if grinds := grindOptions(m.selectedProduct); len(grinds) > 0 {
 		groups = append(groups, huh.NewGroup(
 			huh.NewSelect[string]().
-				Title("Select Size").
-				Options(sizeOptions...).
-				Value(&selectedSize),
+				Title("Select Grind Size").
+				Options(huh.NewOptions(grinds...)...).
+				Value(&c.GrindSize),
 		))
 	}
 
 	groups = append(groups, huh.NewGroup(
-		huh.NewSelect[string]().
-			Title("Select Grind Size").
-			Options(grindOptions...).
-			Value(&selectedGrind),
+		huh.NewInput().
+			Title("Quantity").
+			Value(&c.Quantity).
+			CharLimit(4).
+			Validate(func(s string) error {
+				quantity, err := parseQuantity(s)
+				if err != nil {
+					return err
+				}
+				var variation *woo.Variation
+				variationID := 0
+				if product.IsVariable() {
+					if variation = matchVariation(variations, c.Attributes, c.Options); variation != nil {
+						variationID = variation.ID
+					}
+				}
+				return quantityError(productStock(product, variation), cart.QuantityOf(product.ID, variationID), quantity)
+			}),
 	))
 
 	m.configForm = huh.NewForm(groups...).
 		WithShowHelp(true).
 		WithShowErrors(true)
-
-	// Store pointers for later access
-	m.selectedGrindSize = ""
-}
-
-func (m *Model) initSimpleConfigurator() {
-	if m.selectedProduct == nil {
-		return
-	}
-
-	// Build grind options
-	var grindOptions []huh.Option[string]
-	grindAttr := m.selectedProduct.GetAttribute("Grind Size")
-	if grindAttr != nil {
-		for _, opt := range grindAttr.Options {
-			grindOptions = append(grindOptions, huh.NewOption(opt, opt))
-		}
-	} else {
-		grindOptions = []huh.Option[string]{huh.NewOption("Whole Beans", "Whole Beans")}
-	}
-
-	var selectedGrind string
-
-	m.configForm = huh.NewForm(
-		huh.NewGroup(
-			huh.NewSelect[string]().
-				Title("Select Grind Size").
-				Options(grindOptions...).
-				Value(&selectedGrind),
-		),
-	).WithShowHelp(true).WithShowErrors(true)
 }
 
 // View renders the current view.
@@ -895,9 +2873,34 @@ func (m Model) View() string {
 		content = m.viewReview()
 	case ViewOrderConfirmation:
 		content = m.viewOrderConfirmation()
+	case ViewCategoryPicker:
+		content = m.viewCategoryPicker()
+	case ViewFilterPanel:
+		content = m.viewFilterPanel()
+	case ViewPayment:
+		content = m.viewPayment()
+	case ViewShipping:
+		content = m.viewShipping()
+	case ViewOrders:
+		content = m.viewOrders()
+	case ViewOrderDetail:
+		content = m.viewOrderDetail()
+	case ViewReorder:
+		content = m.viewReorder()
+	case ViewTracking:
+		content = m.viewTracking()
+	case ViewReviewForm:
+		content = m.viewReviewForm()
 	}
 
-	return m.styles.App.Render(content)
+	view := m.styles.App.Render(content)
+	if m.imageProtocol.IsGraphics() {
+		if m.viewState == ViewProductDetails && m.productImage != "" {
+			return placeImage(view, m.productImage, m.height)
+		}
+		view += termimg.Clear(m.imageProtocol)
+	}
+	return view
 }
 
 func (m Model) viewProductList() string {
@@ -905,12 +2908,27 @@ func (m Model) viewProductList() string {
 
 	// Header
 	header := m.styles.HeaderTitle.Render("☕ WooCommerce Coffee Browser")
-	if m.inStockOnly {
-		header += m.styles.Highlight.Render(" [In Stock Only]")
+	for _, filter := range m.activeFilters() {
+		header += m.styles.Highlight.Render(fmt.Sprintf(" [%s]", filter))
+	}
+	if m.totalPages > 1 {
+		header += m.styles.Subtle.Render(fmt.Sprintf("  page %d of %d (%d products)", m.currentPage, m.totalPages, m.totalProducts))
 	}
 	sb.WriteString(m.styles.Header.Render(header))
 	sb.WriteString("\n")
 
+	// Welcome back banner for a cart left in an earlier session
+	if m.offerCartResume {
+		items := "items"
+		if m.localCart.ItemCount() == 1 {
+			items = "item"
+		}
+		banner := fmt.Sprintf("🛒 Welcome back! You left %d %s (%s) in your cart %s.", m.localCart.ItemCount(), items, m.localCart.GetSubtotal(), carts.FormatAge(time.Since(m.cartSavedAt)))
+		sb.WriteString(m.styles.Highlight.Render(banner))
+		sb.WriteString(m.styles.Subtle.Render("  c resume • x start fresh"))
+		sb.WriteString("\n\n")
+	}
+
 	// Search bar
 	if m.showSearch {
 		sb.WriteString("Search: ")
@@ -923,7 +2941,7 @@ func (m Model) viewProductList() string {
 		sb.WriteString(m.listSpinner.View())
 		sb.WriteString(" Loading products...")
 	} else if m.err != nil {
-		sb.WriteString(m.styles.Error.Render(fmt.Sprintf("Error: %v", m.err)))
+		sb.WriteString(m.styles.Error.Render("Error: " + userMessage(m.err)))
 	} else {
 		sb.WriteString(m.productList.View())
 	}
@@ -933,13 +2951,105 @@ func (m Model) viewProductList() string {
 	if m.localCart.ItemCount() > 0 {
 		cartInfo = fmt.Sprintf(" • 🛒 %d items (%s)", m.localCart.ItemCount(), m.localCart.GetSubtotal())
 	}
-	help := "/ search • f filter in-stock • r refresh • enter select • c cart • q quit" + cartInfo
+	help := "/ search • b browse categories • s sort • F filters • f filter in-stock • n/p next/prev page • r refresh • enter select • c cart • o my orders • q quit" + cartInfo
 	sb.WriteString("\n")
 	sb.WriteString(m.styles.HelpBar.Render(help))
 
 	return sb.String()
 }
 
+func (m Model) viewCategoryPicker() string {
+	var sb strings.Builder
+
+	sb.WriteString(m.styles.HeaderTitle.Render("🏷  Browse by Category"))
+	sb.WriteString("\n\n")
+
+	if m.loadingCategories {
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Loading categories...")
+		return m.styles.Box.Render(sb.String())
+	}
+
+	if m.err != nil {
+		sb.WriteString(m.styles.Error.Render("Error: " + userMessage(m.err)))
+		sb.WriteString("\n\n")
+		sb.WriteString(m.styles.HelpBar.Render("esc back"))
+		return m.styles.Box.Render(sb.String())
+	}
+
+	labels := []string{"All Coffees"}
+	for _, c := range m.categories {
+		labels = append(labels, fmt.Sprintf("%s (%d)", StripHTML(c.Name), c.Count))
+	}
+
+	for i, label := range labels {
+		if i == m.categoryIdx {
+			sb.WriteString(m.styles.Highlight.Render("▸ " + label))
+		} else {
+			sb.WriteString("  " + label)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(m.styles.HelpBar.Render("↑/↓ select • enter filter • esc back"))
+
+	return m.styles.Box.Render(sb.String())
+}
+
+func (m Model) viewFilterPanel() string {
+	var sb strings.Builder
+
+	sb.WriteString(m.styles.HeaderTitle.Render("🔎 Filter Products"))
+	sb.WriteString("\n\n")
+
+	onOff := func(on bool) string {
+		if on {
+			return "on"
+		}
+		return "off"
+	}
+
+	rows := []struct {
+		label string
+		value string
+	}{
+		{"Price", "◂ " + priceRanges[m.priceRangeIdx].label + " ▸"},
+		{"On sale", onOff(m.onSaleOnly)},
+		{"Featured", onOff(m.featuredOnly)},
+		{"In stock", onOff(m.inStockOnly)},
+		{"Hide cart items", onOff(m.hideInCart)},
+		{"SKU", m.skuInput.View()},
+	}
+
+	for i, row := range rows {
+		line := fmt.Sprintf("%-16s %s", row.label, row.value)
+		if i == m.filterRow {
+			sb.WriteString(m.styles.Highlight.Render("▸ " + line))
+		} else {
+			sb.WriteString("  " + line)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(m.styles.Subtle.Render("Sort: " + sortOptions[m.sortIdx].label + " (press s on the product list to change)"))
+	sb.WriteString("\n\n")
+	sb.WriteString(m.styles.HelpBar.Render("↑/↓ select • ←/→/space change • enter/esc apply"))
+
+	return m.styles.Box.Render(sb.String())
+}
+
+// maxDescriptionWidth keeps product descriptions readable on wide terminals.
+const maxDescriptionWidth = 76
+
+// descriptionWidth returns how wide product descriptions are wrapped: the
+// width of the details box, inside its border and padding.
+func (m Model) descriptionWidth() int {
+	frame := m.styles.App.GetHorizontalFrameSize() + m.styles.Box.GetHorizontalFrameSize()
+	return min(m.width-frame, maxDescriptionWidth)
+}
+
 func (m Model) viewProductDetails() string {
 	if m.selectedProduct == nil {
 		return "No product selected"
@@ -948,21 +3058,47 @@ func (m Model) viewProductDetails() string {
 	var sb strings.Builder
 	p := m.selectedProduct
 
+	// Main image
+	if img := p.MainImage(); img != nil {
+		switch {
+		case m.imageLoader == nil || m.imageProtocol == termimg.ProtocolText || m.imageErr != nil:
+			sb.WriteString(m.styles.Subtle.Render(imageFallback(img, p.Name)))
+		case m.loadingImage:
+			sb.WriteString(m.listSpinner.View())
+			sb.WriteString(" Loading image...")
+		case m.imageProtocol.IsGraphics():
+			// Blank cells for the image, drawn over them by View
+			sb.WriteString(imageMarker)
+			sb.WriteString(strings.TrimSuffix(strings.Repeat(strings.Repeat(" ", productImageCols)+"\n", productImageRows), "\n"))
+		default:
+			sb.WriteString(m.productImage)
+		}
+		sb.WriteString("\n\n")
+	}
+
 	// Product name
 	sb.WriteString(m.styles.ProductName.Render(p.Name))
 	sb.WriteString("\n\n")
 
 	// Price
-	price := p.GetDisplayPrice()
+	price := formatPrice(m.storeSettings, p.GetDisplayPrice())
 	if p.SalePrice != "" && p.SalePrice != p.RegularPrice {
-		sb.WriteString(m.styles.ProductSalePrice.Render(fmt.Sprintf("$%s", price)))
+		sb.WriteString(m.styles.ProductSalePrice.Render(price))
 		sb.WriteString(" ")
-		sb.WriteString(m.styles.Subtle.Render(fmt.Sprintf("(was $%s)", p.RegularPrice)))
+		sb.WriteString(m.styles.Subtle.Render(fmt.Sprintf("(was %s)", formatPrice(m.storeSettings, p.RegularPrice))))
 	} else {
-		sb.WriteString(m.styles.ProductPrice.Render(fmt.Sprintf("$%s", price)))
+		sb.WriteString(m.styles.ProductPrice.Render(price))
 	}
 	sb.WriteString("\n")
 
+	// Rating
+	if showsReviews(p) {
+		sb.WriteString(m.styles.ProductRating.Render(ratingStars(p.Rating())))
+		sb.WriteString(" ")
+		sb.WriteString(m.styles.Subtle.Render(ratingSummary(p)))
+		sb.WriteString("\n")
+	}
+
 	// Stock status
 	if p.IsInStock() {
 		sb.WriteString(m.styles.ProductInStock.Render("✓ In Stock"))
@@ -978,7 +3114,7 @@ func (m Model) viewProductDetails() string {
 	sb.WriteString("\n")
 
 	// Description
-	desc := StripHTML(p.Description)
+	desc := RenderHTML(p.Description, m.descriptionWidth(), m.styles)
 	if desc != "" {
 		sb.WriteString("\n")
 		sb.WriteString(m.styles.ProductDescription.Render(desc))
@@ -1006,19 +3142,78 @@ func (m Model) viewProductDetails() string {
 		}
 	}
 
+	// Reviews
+	lines := m.reviewListLines()
+	if showsReviews(p) {
+		sb.WriteString("\n\n")
+		sb.WriteString(m.viewReviewList(lines))
+	}
+
 	// Help bar
 	sb.WriteString("\n\n")
 	helpText := "esc/backspace back"
 	if p.IsVariable() && len(m.productVariations) > 0 {
 		helpText += " • c/enter configure"
-	} else if grindAttr := p.GetAttribute("Grind Size"); grindAttr != nil {
+	} else if m.canConfigure() {
 		helpText += " • c/enter select grind"
 	}
+	if len(lines) > reviewListRows {
+		helpText += " • ↑/↓ scroll reviews"
+	}
+	if m.reviewsTotalPages > 1 {
+		helpText += " • n/p more reviews"
+	}
+	if p.ReviewsAllowed && m.canReview() {
+		helpText += " • w write a review"
+	}
 	sb.WriteString(m.styles.HelpBar.Render(helpText))
 
 	return m.styles.Box.Render(sb.String())
 }
 
+// viewReviewList shows a window of the review list in the product details.
+func (m Model) viewReviewList(lines []string) string {
+	var sb strings.Builder
+
+	header := m.styles.Subtle.Render("Customer Reviews")
+	if m.reviewsTotalPages > 1 {
+		header += m.styles.Subtle.Render(fmt.Sprintf("  page %d of %d", m.reviewsPage, m.reviewsTotalPages))
+	}
+	sb.WriteString(header)
+	sb.WriteString("\n")
+
+	if m.reviewNotice != "" {
+		sb.WriteString(m.styles.Success.Render(m.reviewNotice))
+		sb.WriteString("\n")
+	}
+
+	switch {
+	case m.loadingReviews:
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Loading reviews...")
+	case m.reviewsErr != nil:
+		sb.WriteString(m.styles.Error.Render("Couldn't load reviews: " + userMessage(m.reviewsErr)))
+	case len(lines) == 0:
+		sb.WriteString(m.styles.Subtle.Render("No reviews yet."))
+	default:
+		// Resizing the terminal rewraps the reviews into fewer lines
+		start := min(m.reviewsScroll, max(len(lines)-reviewListRows, 0))
+		end := min(start+reviewListRows, len(lines))
+		sb.WriteString(strings.Join(lines[start:end], "\n"))
+		if len(lines) > reviewListRows {
+			sb.WriteString("\n")
+			sb.WriteString(m.styles.Subtle.Render(fmt.Sprintf("lines %d-%d of %d", start+1, end, len(lines))))
+		}
+	}
+
+	if m.selectedProduct.ReviewsAllowed && !m.canReview() {
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.Subtle.Render("Sign in to write a review: your first order links this SSH key to an account."))
+	}
+
+	return sb.String()
+}
+
 func (m Model) viewConfigurator() string {
 	if m.selectedProduct == nil {
 		return "No product selected"
@@ -1035,12 +3230,34 @@ func (m Model) viewConfigurator() string {
 		sb.WriteString(m.configForm.View())
 	}
 
+	// Price and stock of the variation picked so far
+	if c := m.configChoices; !m.configCompleted && c != nil && len(c.Attributes) > 0 {
+		sb.WriteString("\n")
+		if v := matchVariation(m.productVariations, c.Attributes, c.Options); v != nil {
+			sb.WriteString(m.styles.ProductPrice.Render(formatPrice(m.storeSettings, v.GetDisplayPrice())))
+			sb.WriteString("  ")
+			if variationAvailable(v) {
+				sb.WriteString(m.styles.ProductInStock.Render(variationStock(v)))
+			} else {
+				sb.WriteString(m.styles.ProductOutOfStock.Render(variationStock(v)))
+			}
+		} else {
+			sb.WriteString(m.styles.ProductOutOfStock.Render("Not available in this combination"))
+		}
+		sb.WriteString("\n")
+	}
+
 	// Summary (if completed)
 	if m.configCompleted {
 		sb.WriteString("\n")
 		sb.WriteString(m.styles.ConfigSummary.Render(m.renderConfigSummary()))
 	}
 
+	if m.err != nil {
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.Error.Render("Error: " + userMessage(m.err)))
+	}
+
 	// Help bar
 	sb.WriteString("\n\n")
 	helpText := "esc back • enter/tab navigate • space select"
@@ -1062,96 +3279,280 @@ func (m Model) renderConfigSummary() string {
 	}
 
 	if m.selectedVariation != nil {
-		sb.WriteString(fmt.Sprintf("Variation ID: %d\n", m.selectedVariation.ID))
-		sb.WriteString(fmt.Sprintf("Price: $%s\n", m.selectedVariation.GetDisplayPrice()))
+		if c := m.configChoices; c != nil {
+			for i, attr := range c.Attributes {
+				sb.WriteString(fmt.Sprintf("%s: %s\n", attr.Name, c.Options[i]))
+			}
+		}
+		sb.WriteString(fmt.Sprintf("Price: %s\n", formatPrice(m.storeSettings, m.selectedVariation.GetDisplayPrice())))
+		sb.WriteString(fmt.Sprintf("Stock: %s\n", variationStock(m.selectedVariation)))
 	} else if m.selectedProduct != nil {
-		sb.WriteString(fmt.Sprintf("Price: $%s\n", m.selectedProduct.GetDisplayPrice()))
+		sb.WriteString(fmt.Sprintf("Price: %s\n", formatPrice(m.storeSettings, m.selectedProduct.GetDisplayPrice())))
 	}
 
 	if m.selectedGrindSize != "" {
 		sb.WriteString(fmt.Sprintf("Grind: %s\n", m.selectedGrindSize))
 	}
 
+	if c := m.configChoices; c != nil && c.Quantity != "" {
+		sb.WriteString(fmt.Sprintf("Quantity: %s\n", strings.TrimSpace(c.Quantity)))
+	}
+
 	return sb.String()
 }
 
 func (m Model) viewCart() string {
 	var sb strings.Builder
 
-	// Header
-	sb.WriteString(m.styles.HeaderTitle.Render("🛒 Shopping Cart"))
-	sb.WriteString("\n\n")
+	// Header
+	sb.WriteString(m.styles.HeaderTitle.Render("🛒 Shopping Cart"))
+	sb.WriteString("\n\n")
+
+	if m.localCart.IsEmpty() {
+		sb.WriteString(m.styles.Subtle.Render("Your cart is empty"))
+		sb.WriteString("\n\n")
+		sb.WriteString(m.styles.HelpBar.Render("esc back to products"))
+		return m.styles.Box.Render(sb.String())
+	}
+
+	// Cart items (local)
+	for i, item := range m.localCart.Items {
+		prefix := "  "
+		if i == m.localCart.SelectedIdx {
+			prefix = m.styles.Highlight.Render("▸ ")
+		}
+
+		name := item.GetDisplayName()
+		price := item.GetFormattedPrice(m.storeSettings)
+		qty := fmt.Sprintf("x%d", item.Quantity)
+		total := item.GetFormattedTotal(m.storeSettings)
+
+		line := fmt.Sprintf("%s%s  %s  %s  = %s", prefix, name, price, qty, total)
+		if i == m.localCart.SelectedIdx {
+			sb.WriteString(m.styles.Highlight.Render(line))
+		} else {
+			sb.WriteString(line)
+		}
+		sb.WriteString("\n")
+
+		// Stock as last seen: checked again before the order is placed
+		if limit, ok := item.Stock.Limit(); ok && item.Quantity > limit {
+			if limit == 0 {
+				sb.WriteString(m.styles.Error.Render("    ✗ Out of stock"))
+			} else {
+				sb.WriteString(m.styles.Error.Render(fmt.Sprintf("    ✗ Only %d in stock", limit)))
+			}
+			sb.WriteString("\n")
+		} else if backordered := item.Stock.Backordered(item.Quantity); backordered > 0 {
+			sb.WriteString(m.styles.Warning.Render(fmt.Sprintf("    ⚠ %d on backorder: ships when the shop restocks", backordered)))
+			sb.WriteString("\n")
+		}
+	}
+
+	// Totals (local estimate with shipping)
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("Subtotal: %s\n", m.localCart.GetSubtotal()))
+	if coupon := m.localCart.Coupon; coupon != nil {
+		if _, err := m.localCart.lineDiscounts(); err != nil {
+			sb.WriteString(m.styles.Error.Render(fmt.Sprintf("Coupon %s can't be applied: %s", strings.ToUpper(coupon.Code), userMessage(err))))
+		} else {
+			sb.WriteString(m.styles.Success.Render(fmt.Sprintf("Coupon %s: -%s", strings.ToUpper(coupon.Code), m.storeSettings.Format(m.localCart.Discount()))))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("Shipping: %s\n", m.localCart.GetShippingFormatted()))
+	sb.WriteString(m.styles.ProductPrice.Render(fmt.Sprintf("Total: %s", m.localCart.GetTotal())))
+	sb.WriteString(fmt.Sprintf(" (%d items)", m.localCart.ItemCount()))
+	sb.WriteString("\n")
+
+	// Coupon entry
+	switch {
+	case m.showCouponInput:
+		sb.WriteString("\n")
+		sb.WriteString(m.couponInput.View())
+		sb.WriteString("\n")
+	case m.checkingCoupon:
+		sb.WriteString("\n")
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Checking coupon...\n")
+	}
+	if m.couponErr != nil {
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.Error.Render(userMessage(m.couponErr)))
+		sb.WriteString("\n")
+	}
+	if m.cartErr != nil {
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.Error.Render(userMessage(m.cartErr)))
+		sb.WriteString("\n")
+	}
+
+	// Help bar
+	sb.WriteString("\n")
+	if m.showCouponInput {
+		sb.WriteString(m.styles.HelpBar.Render("enter apply • esc cancel"))
+	} else {
+		sb.WriteString(m.styles.HelpBar.Render("↑/↓ select • +/- quantity • d delete • c coupon • x remove coupon • o checkout • s continue shopping • esc back"))
+	}
+
+	return m.styles.Box.Render(sb.String())
+}
+
+func (m Model) viewAddress() string {
+	var sb strings.Builder
+
+	// Header with progress
+	sb.WriteString(m.styles.HeaderTitle.Render("📦 Shipping Address"))
+	sb.WriteString("  ")
+	sb.WriteString(m.styles.Subtle.Render("Step 1 of 4"))
+	sb.WriteString("\n\n")
+
+	if m.err != nil {
+		sb.WriteString(m.styles.Error.Render("Error: " + userMessage(m.err)))
+		sb.WriteString("\n\n")
+	}
+
+	// Address form
+	if m.addressForm != nil {
+		sb.WriteString(m.addressForm.View())
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.HelpBar.Render("esc back • tab navigate • enter submit"))
+	}
+
+	return m.styles.Box.Render(sb.String())
+}
+
+func (m Model) viewReviewForm() string {
+	var sb strings.Builder
+
+	sb.WriteString(m.styles.HeaderTitle.Render("✎ Review: " + m.selectedProduct.Name))
+	sb.WriteString("\n")
+	sb.WriteString(m.styles.Subtle.Render("Posted as " + reviewerName(m.customer)))
+	sb.WriteString("\n\n")
+
+	if m.reviewErr != nil {
+		sb.WriteString(m.styles.Error.Render("Error: " + userMessage(m.reviewErr)))
+		sb.WriteString("\n\n")
+	}
+
+	if m.submittingReview {
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Sending your review...")
+		return m.styles.Box.Render(sb.String())
+	}
+
+	if m.reviewForm != nil {
+		sb.WriteString(m.reviewForm.View())
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.HelpBar.Render("esc back • tab navigate • enter submit"))
+	}
+
+	return m.styles.Box.Render(sb.String())
+}
+
+func (m Model) viewShipping() string {
+	var sb strings.Builder
+
+	// Header with progress
+	sb.WriteString(m.styles.HeaderTitle.Render("🚚 Shipping Method"))
+	sb.WriteString("  ")
+	sb.WriteString(m.styles.Subtle.Render("Step 2 of 4"))
+	sb.WriteString("\n\n")
+
+	if m.loadingShipping {
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Loading shipping methods...")
+		return m.styles.Box.Render(sb.String())
+	}
+
+	if m.err != nil {
+		sb.WriteString(m.styles.Error.Render("Error: " + userMessage(m.err)))
+		sb.WriteString("\n\n")
+		sb.WriteString(m.styles.HelpBar.Render("r retry • esc back"))
+		return m.styles.Box.Render(sb.String())
+	}
+
+	destination := m.customerInfo.Country
+	if m.customerInfo.Postcode != "" {
+		destination = m.customerInfo.Postcode + " " + destination
+	}
 
-	if m.localCart.IsEmpty() {
-		sb.WriteString(m.styles.Subtle.Render("Your cart is empty"))
+	if len(m.shippingRates) == 0 {
+		sb.WriteString(m.styles.Error.Render(fmt.Sprintf("Sorry, we don't ship to %s.", destination)))
 		sb.WriteString("\n\n")
-		sb.WriteString(m.styles.HelpBar.Render("esc back to products"))
+		sb.WriteString(m.styles.HelpBar.Render("esc change address"))
 		return m.styles.Box.Render(sb.String())
 	}
 
-	// Cart items (local)
-	for i, item := range m.localCart.Items {
-		prefix := "  "
-		if i == m.localCart.SelectedIdx {
-			prefix = m.styles.Highlight.Render("▸ ")
-		}
-
-		name := item.GetDisplayName()
-		price := item.GetFormattedPrice()
-		qty := fmt.Sprintf("x%d", item.Quantity)
-		total := item.GetFormattedTotal()
+	sb.WriteString(m.styles.Subtle.Render("Shipping to " + destination + ":"))
+	sb.WriteString("\n\n")
 
-		line := fmt.Sprintf("%s%s  %s  %s  = %s", prefix, name, price, qty, total)
-		if i == m.localCart.SelectedIdx {
-			sb.WriteString(m.styles.Highlight.Render(line))
+	for i, rate := range m.shippingRates {
+		cost := m.storeSettings.Format(rate.Cost)
+		if rate.Cost.IsZero() {
+			cost = "FREE"
+		}
+		line := fmt.Sprintf("%s - %s", StripHTML(rate.Title), cost)
+		if i == m.shippingIdx {
+			sb.WriteString(m.styles.Highlight.Render("▸ " + line))
 		} else {
-			sb.WriteString(line)
+			sb.WriteString("  " + line)
 		}
 		sb.WriteString("\n")
 	}
 
-	// Totals (local estimate with shipping)
-	sb.WriteString("\n")
-	sb.WriteString(fmt.Sprintf("Subtotal: %s\n", m.localCart.GetSubtotal()))
-	sb.WriteString(fmt.Sprintf("Shipping: %s\n", m.localCart.GetShippingFormatted()))
-	sb.WriteString(m.styles.ProductPrice.Render(fmt.Sprintf("Total: %s", m.localCart.GetTotal())))
-	sb.WriteString(fmt.Sprintf(" (%d items)", m.localCart.ItemCount()))
-	sb.WriteString("\n")
-	if remaining := m.localCart.AmountUntilFreeShipping(); remaining > 0 {
-		sb.WriteString(m.styles.Subtle.Render(fmt.Sprintf("Add $%.2f more for free shipping", remaining)))
-	} else {
-		sb.WriteString(m.styles.Success.Render("✓ Qualifies for free shipping!"))
-	}
-	sb.WriteString("\n")
-
-	// Help bar
 	sb.WriteString("\n")
-	sb.WriteString(m.styles.HelpBar.Render("↑/↓ select • +/- quantity • d delete • o checkout • s continue shopping • esc back"))
+	sb.WriteString(m.styles.HelpBar.Render("↑/↓ select • enter continue • esc back"))
 
 	return m.styles.Box.Render(sb.String())
 }
 
-func (m Model) viewAddress() string {
+func (m Model) viewPayment() string {
 	var sb strings.Builder
 
 	// Header with progress
-	sb.WriteString(m.styles.HeaderTitle.Render("📦 Shipping Address"))
+	sb.WriteString(m.styles.HeaderTitle.Render("💳 Payment Method"))
 	sb.WriteString("  ")
-	sb.WriteString(m.styles.Subtle.Render("Step 1 of 2"))
+	sb.WriteString(m.styles.Subtle.Render("Step 3 of 4"))
 	sb.WriteString("\n\n")
 
+	if m.loadingGateways {
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Loading payment methods...")
+		return m.styles.Box.Render(sb.String())
+	}
+
 	if m.err != nil {
-		sb.WriteString(m.styles.Error.Render(fmt.Sprintf("Error: %v", m.err)))
+		sb.WriteString(m.styles.Error.Render("Error: " + userMessage(m.err)))
 		sb.WriteString("\n\n")
+		sb.WriteString(m.styles.HelpBar.Render("r retry • esc back"))
+		return m.styles.Box.Render(sb.String())
 	}
 
-	// Address form
-	if m.addressForm != nil {
-		sb.WriteString(m.addressForm.View())
+	if len(m.paymentGateways) == 0 {
+		sb.WriteString(m.styles.Error.Render("No payment methods are available right now."))
+		sb.WriteString("\n\n")
+		sb.WriteString(m.styles.HelpBar.Render("esc back"))
+		return m.styles.Box.Render(sb.String())
+	}
+
+	for i, g := range m.paymentGateways {
+		title := StripHTML(g.Title)
+		if i == m.paymentIdx {
+			sb.WriteString(m.styles.Highlight.Render("▸ " + title))
+		} else {
+			sb.WriteString("  " + title)
+		}
 		sb.WriteString("\n")
-		sb.WriteString(m.styles.HelpBar.Render("esc back • tab navigate • enter submit"))
+		if desc := StripHTML(g.Description); desc != "" {
+			sb.WriteString(m.styles.Subtle.Render("    " + desc))
+			sb.WriteString("\n")
+		}
 	}
 
+	sb.WriteString("\n")
+	sb.WriteString(m.styles.HelpBar.Render("↑/↓ select • enter continue • esc back"))
+
 	return m.styles.Box.Render(sb.String())
 }
 
@@ -1161,7 +3562,7 @@ func (m Model) viewReview() string {
 	// Header with progress
 	sb.WriteString(m.styles.HeaderTitle.Render("📋 Review Order"))
 	sb.WriteString("  ")
-	sb.WriteString(m.styles.Subtle.Render("Step 2 of 2"))
+	sb.WriteString(m.styles.Subtle.Render("Step 4 of 4"))
 	sb.WriteString("\n\n")
 
 	if m.creatingOrder {
@@ -1170,8 +3571,26 @@ func (m Model) viewReview() string {
 		return m.styles.Box.Render(sb.String())
 	}
 
-	if m.err != nil {
-		sb.WriteString(m.styles.Error.Render(fmt.Sprintf("Error: %v", m.err)))
+	if m.checkingStock {
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Checking stock...")
+		return m.styles.Box.Render(sb.String())
+	}
+
+	if m.checkingOrder {
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Order may have been placed, checking…\n\n")
+		sb.WriteString(m.styles.Subtle.Render("The shop didn't answer in time. We'll check before trying again, so it won't be placed twice."))
+		return m.styles.Box.Render(sb.String())
+	}
+
+	if m.orderUncertain {
+		sb.WriteString(m.styles.Error.Render("We couldn't check whether your order was placed: " + userMessage(m.err)))
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.Subtle.Render("Press enter to check again. It won't be placed twice."))
+		sb.WriteString("\n\n")
+	} else if m.err != nil {
+		sb.WriteString(m.styles.Error.Render("Error: " + userMessage(m.err)))
 		sb.WriteString("\n\n")
 	}
 
@@ -1189,42 +3608,104 @@ func (m Model) viewReview() string {
 	// Items
 	sb.WriteString(m.styles.Subtle.Render("Items:"))
 	sb.WriteString("\n")
-	for _, item := range m.localCart.Items {
-		itemTotal := item.Price * float64(item.Quantity)
-		sb.WriteString(fmt.Sprintf("  • %s x%d = $%.2f\n", item.Name, item.Quantity, itemTotal))
+	issues := make(map[int]StockIssue, len(m.stockIssues))
+	for _, issue := range m.stockIssues {
+		issues[issue.Index] = issue
+	}
+	repriced := make(map[int]PriceChange, len(m.priceChanges))
+	for _, change := range m.priceChanges {
+		repriced[change.Index] = change
+	}
+	for i, item := range m.localCart.Items {
+		sb.WriteString(fmt.Sprintf("  • %s x%d = %s\n", item.Name, item.Quantity, item.GetFormattedTotal(m.storeSettings)))
+		if issue, ok := issues[i]; ok {
+			if issue.Available == 0 {
+				sb.WriteString(m.styles.Error.Render("    ✗ No longer available"))
+			} else {
+				sb.WriteString(m.styles.Error.Render(fmt.Sprintf("    ✗ Only %d left", issue.Available)))
+			}
+			sb.WriteString("\n")
+		} else if change, ok := repriced[i]; ok {
+			sb.WriteString(m.styles.Warning.Render(fmt.Sprintf("    ~ Now %s each (was %s)", m.storeSettings.Format(item.Price), m.storeSettings.Format(change.Was))))
+			sb.WriteString("\n")
+		} else if backordered := item.Stock.Backordered(item.Quantity); backordered > 0 {
+			sb.WriteString(m.styles.Warning.Render(fmt.Sprintf("    ⚠ %d on backorder", backordered)))
+			sb.WriteString("\n")
+		}
+	}
+	if len(m.stockIssues) > 0 {
+		sb.WriteString(m.styles.Error.Render("Some items sold out since you added them. Update your cart to place the order."))
+		sb.WriteString("\n")
+	} else if len(m.priceChanges) > 0 {
+		sb.WriteString(m.styles.Warning.Render("Some prices changed since you added the items. Check your cart to place the order."))
+		sb.WriteString("\n")
 	}
 	sb.WriteString("\n")
 
-	// Shipping method (calculated locally)
+	// Shipping method chosen in the previous step
 	shippingCost := m.localCart.CalculateShipping()
-	shippingLabel := "Flat Rate"
-	if shippingCost == 0 {
-		shippingLabel = "Free Shipping"
+	if m.localCart.Shipping != nil {
+		sb.WriteString(m.styles.Subtle.Render("Shipping Method:"))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("  %s - %s\n\n", StripHTML(m.localCart.Shipping.Title), m.storeSettings.Format(shippingCost)))
 	}
-	sb.WriteString(m.styles.Subtle.Render("Shipping Method:"))
-	sb.WriteString("\n")
-	sb.WriteString(fmt.Sprintf("  %s - $%.2f\n\n", shippingLabel, shippingCost))
 
 	// Totals
 	subtotal := m.localCart.Subtotal()
 	total := m.localCart.CalculateTotal()
-	sb.WriteString(fmt.Sprintf("Subtotal: $%.2f\n", subtotal))
-	if shippingCost > 0 {
-		sb.WriteString(fmt.Sprintf("Shipping: $%.2f\n", shippingCost))
+	sb.WriteString(fmt.Sprintf("Subtotal: %s\n", m.storeSettings.Format(subtotal)))
+	if coupon := m.localCart.Coupon; coupon != nil {
+		sb.WriteString(m.styles.Success.Render(fmt.Sprintf("Coupon %s: -%s", strings.ToUpper(coupon.Code), m.storeSettings.Format(m.localCart.Discount()))))
+		sb.WriteString("\n")
+	}
+	if !shippingCost.IsZero() {
+		sb.WriteString(fmt.Sprintf("Shipping: %s\n", m.storeSettings.Format(shippingCost)))
 	} else {
 		sb.WriteString(m.styles.Success.Render("Shipping: FREE\n"))
 	}
-	sb.WriteString(m.styles.ProductPrice.Render(fmt.Sprintf("\nTotal: $%.2f", total)))
+
+	// Estimated tax, one line per rate
+	tax := m.localCart.Tax
+	switch {
+	case m.loadingTax:
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Calculating tax...\n")
+	case tax != nil && !tax.Included.IsZero():
+		for _, rate := range tax.Rates {
+			sb.WriteString(m.styles.Subtle.Render(fmt.Sprintf("incl. %s: %s", rate.Label, m.storeSettings.Format(rate.Amount))))
+			sb.WriteString("\n")
+		}
+	case tax != nil:
+		for _, rate := range tax.Rates {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", rate.Label, m.storeSettings.Format(rate.Amount)))
+		}
+	}
+
+	sb.WriteString(m.styles.ProductPrice.Render(fmt.Sprintf("\nTotal: %s", m.storeSettings.Format(total))))
 	sb.WriteString("\n")
+	if tax != nil && !tax.Total.IsZero() {
+		sb.WriteString(m.styles.Subtle.Render("Tax is estimated; the store confirms the final amount."))
+		sb.WriteString("\n")
+	}
 
 	// Payment note
 	sb.WriteString("\n")
-	sb.WriteString(m.styles.Subtle.Render("Payment: Bank Transfer (BACS)"))
-	sb.WriteString("\n")
+	if m.selectedGateway != nil {
+		sb.WriteString(m.styles.Subtle.Render("Payment: " + StripHTML(m.selectedGateway.Title)))
+		sb.WriteString("\n")
+	}
 
 	// Help bar
 	sb.WriteString("\n")
-	sb.WriteString(m.styles.HelpBar.Render("p/enter place order • esc back"))
+	if m.orderUncertain {
+		sb.WriteString(m.styles.HelpBar.Render("enter check again • esc back"))
+	} else if len(m.stockIssues) > 0 {
+		sb.WriteString(m.styles.HelpBar.Render("c update cart • p/enter check again • esc back"))
+	} else if len(m.priceChanges) > 0 {
+		sb.WriteString(m.styles.HelpBar.Render("c check cart • esc back"))
+	} else {
+		sb.WriteString(m.styles.HelpBar.Render("p/enter place order • esc back"))
+	}
 
 	return m.styles.Box.Render(sb.String())
 }
@@ -1239,8 +3720,23 @@ func (m Model) viewOrderConfirmation() string {
 	if m.orderResponse != nil {
 		sb.WriteString(fmt.Sprintf("Order #%d\n", m.orderResponse.ID))
 		sb.WriteString(fmt.Sprintf("Status: %s\n", m.orderResponse.Status))
-		sb.WriteString(fmt.Sprintf("Total: %s %s\n", m.orderResponse.Currency, m.orderResponse.Total))
+		if m.orderResponse.Currency == m.storeSettings.Currency {
+			sb.WriteString(fmt.Sprintf("Total: %s\n", formatPrice(m.storeSettings, m.orderResponse.Total)))
+		} else {
+			sb.WriteString(fmt.Sprintf("Total: %s %s\n", m.orderResponse.Currency, m.orderResponse.Total))
+		}
+		if tax, err := m.storeSettings.ParsePrice(m.orderResponse.TotalTax); err == nil && !tax.IsZero() && m.orderResponse.Currency == m.storeSettings.Currency {
+			sb.WriteString(fmt.Sprintf("Tax: %s\n", m.storeSettings.Format(tax)))
+		}
+		if warning := m.taxMismatch(); warning != "" {
+			sb.WriteString(m.styles.Error.Render(warning))
+			sb.WriteString("\n")
+		}
 		sb.WriteString(fmt.Sprintf("Order Key: %s\n", m.orderResponse.OrderKey))
+		if m.customer != nil && m.orderResponse.CustomerID == m.customer.ID {
+			sb.WriteString(m.styles.Subtle.Render(fmt.Sprintf("Order saved to your account (%s)", m.customer.Email)))
+			sb.WriteString("\n")
+		}
 
 		sb.WriteString("\n")
 		sb.WriteString(m.styles.Subtle.Render("Shipping Address:"))
@@ -1257,21 +3753,393 @@ func (m Model) viewOrderConfirmation() string {
 		sb.WriteString("\n")
 		sb.WriteString(m.styles.Subtle.Render("Next Step:"))
 		sb.WriteString("\n")
-		sb.WriteString("  Complete payment via Bank Transfer (BACS)\n")
+		if m.selectedGateway != nil {
+			sb.WriteString(fmt.Sprintf("  Complete payment via %s\n", StripHTML(m.selectedGateway.Title)))
+			if desc := StripHTML(m.selectedGateway.Description); desc != "" {
+				sb.WriteString(m.styles.Subtle.Render("  " + desc))
+				sb.WriteString("\n")
+			}
+		}
 	}
 
 	if m.err != nil {
 		sb.WriteString("\n")
-		sb.WriteString(m.styles.Error.Render(fmt.Sprintf("Note: %v", m.err)))
+		sb.WriteString(m.styles.Error.Render("Note: " + userMessage(m.err)))
 	}
 
 	// Help bar
 	sb.WriteString("\n\n")
-	sb.WriteString(m.styles.HelpBar.Render("Press Enter to continue shopping"))
+	if m.orderResponse != nil && !isFinalOrderStatus(m.orderResponse.Status) {
+		sb.WriteString(m.styles.HelpBar.Render("t track order • enter continue shopping"))
+	} else {
+		sb.WriteString(m.styles.HelpBar.Render("Press Enter to continue shopping"))
+	}
+
+	return m.styles.Box.Render(sb.String())
+}
+
+func (m Model) viewTracking() string {
+	var sb strings.Builder
+
+	order := m.orderResponse
+	if order == nil {
+		return m.styles.Box.Render("No order to track")
+	}
+
+	sb.WriteString(m.styles.HeaderTitle.Render(fmt.Sprintf("🚚 Tracking Order #%d", order.ID)))
+	sb.WriteString("\n\n")
+
+	for _, step := range statusTimeline(m.trackingSteps) {
+		name := orderStatusLabel(step.Status)
+		when := ""
+		if !step.Since.IsZero() {
+			when = "  " + m.styles.Subtle.Render(step.Since.Format("15:04"))
+		}
+		switch {
+		case step.Current && isFinalOrderStatus(step.Status) && step.Status != woo.OrderStatusCompleted:
+			sb.WriteString(m.styles.Error.Render("✗ "+name) + when)
+		case step.Current && step.Done:
+			sb.WriteString(m.styles.Success.Render("✓ "+name) + when)
+		case step.Current:
+			sb.WriteString(m.styles.Highlight.Render("● "+name) + when)
+		case step.Done:
+			sb.WriteString("✓ " + name + when)
+		default:
+			sb.WriteString(m.styles.Subtle.Render("○ " + name))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(m.trackingNotes) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.Subtle.Render("Notes from the shop:"))
+		sb.WriteString("\n")
+		for _, note := range m.trackingNotes {
+			sb.WriteString(fmt.Sprintf("  %s  %s\n", formatOrderDate(note.DateCreated), StripHTML(note.Note)))
+		}
+	}
+
+	sb.WriteString("\n")
+	switch {
+	case isFinalOrderStatus(order.Status):
+		sb.WriteString(m.styles.Subtle.Render("This order won't change any more."))
+	case m.trackingErr != nil:
+		sb.WriteString(m.styles.Error.Render("Couldn't check the order: " + userMessage(m.trackingErr)))
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.Subtle.Render("Trying again shortly..."))
+	default:
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(m.styles.Subtle.Render(" Watching for updates..."))
+	}
+	sb.WriteString("\n\n")
+	sb.WriteString(m.styles.HelpBar.Render("r check now • esc back • enter continue shopping"))
+
+	return m.styles.Box.Render(sb.String())
+}
+
+// taxMismatch returns a warning when the tax charged on the order differs
+// from the estimate shown at review, or "" when they agree.
+func (m Model) taxMismatch() string {
+	if m.orderResponse == nil || m.orderTax == nil || m.orderResponse.Currency != m.storeSettings.Currency {
+		return ""
+	}
+	charged, err := m.storeSettings.ParsePrice(m.orderResponse.TotalTax)
+	if err != nil {
+		return ""
+	}
+	if c, err := charged.Cmp(m.orderTax.Total.Round(m.storeSettings.Decimals)); err != nil || c == 0 {
+		return ""
+	}
+	return fmt.Sprintf("⚠ Tax charged: %s (estimated %s). The order total above is what you'll pay.",
+		m.storeSettings.Format(charged), m.storeSettings.Format(m.orderTax.Total))
+}
+
+func (m Model) viewOrders() string {
+	var sb strings.Builder
+
+	header := m.styles.HeaderTitle.Render("📦 My Orders")
+	if m.ordersTotalPages > 1 {
+		header += m.styles.Subtle.Render(fmt.Sprintf("  page %d of %d", m.ordersPage, m.ordersTotalPages))
+	}
+	sb.WriteString(header)
+	sb.WriteString("\n\n")
+
+	if m.loadingOrders {
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Loading your orders...")
+		return m.styles.Box.Render(sb.String())
+	}
+
+	if m.err != nil {
+		sb.WriteString(m.styles.Error.Render("Error: " + userMessage(m.err)))
+		sb.WriteString("\n\n")
+		sb.WriteString(m.styles.HelpBar.Render("r retry • esc back"))
+		return m.styles.Box.Render(sb.String())
+	}
+
+	if len(m.orders) == 0 {
+		sb.WriteString("No orders yet.\n")
+		if m.customerID == 0 {
+			sb.WriteString(m.styles.Subtle.Render("Orders you place in this session will show up here."))
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.HelpBar.Render("esc back"))
+		return m.styles.Box.Render(sb.String())
+	}
+
+	for i, order := range m.orders {
+		items := "1 item"
+		if count := order.ItemCount(); count != 1 {
+			items = fmt.Sprintf("%d items", count)
+		}
+		line := fmt.Sprintf("#%-6d %-12s %-16s %-9s %s",
+			order.ID,
+			formatOrderDate(order.DateCreated),
+			orderStatusLabel(order.Status),
+			items,
+			m.formatOrderAmount(&order, order.Total))
+		if i == m.orderIdx {
+			sb.WriteString(m.styles.Highlight.Render("▸ " + line))
+		} else {
+			sb.WriteString("  " + line)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+	help := "↑/↓ select • enter details • r refresh • esc back"
+	if m.ordersTotalPages > 1 {
+		help = "↑/↓ select • enter details • n/p next/prev page • r refresh • esc back"
+	}
+	sb.WriteString(m.styles.HelpBar.Render(help))
+
+	return m.styles.Box.Render(sb.String())
+}
+
+func (m Model) viewOrderDetail() string {
+	var sb strings.Builder
+
+	order := m.selectedOrder
+	if order == nil {
+		return m.styles.Box.Render("No order selected")
+	}
+
+	sb.WriteString(m.styles.HeaderTitle.Render(fmt.Sprintf("📦 Order #%d", order.ID)))
+	sb.WriteString("  ")
+	sb.WriteString(m.styles.Highlight.Render(orderStatusLabel(order.Status)))
+	sb.WriteString("\n")
+	if date := formatOrderDate(order.DateCreated); date != "" {
+		sb.WriteString(m.styles.Subtle.Render("Placed " + date))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	if m.loadingOrderDetail {
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Loading order details...")
+		return m.styles.Box.Render(sb.String())
+	}
+
+	if m.err != nil {
+		sb.WriteString(m.styles.Error.Render("Error: " + userMessage(m.err)))
+		sb.WriteString("\n\n")
+		sb.WriteString(m.styles.HelpBar.Render("r retry • esc back"))
+		return m.styles.Box.Render(sb.String())
+	}
+
+	// Line items with their options, e.g. the grind size
+	for _, item := range order.LineItems {
+		name := StripHTML(item.Name)
+		var options []string
+		for _, meta := range item.MetaData {
+			if value := meta.String(); strings.HasPrefix(meta.Key, "pa_") && value != "" {
+				options = append(options, value)
+			}
+		}
+		if len(options) > 0 {
+			name += " (" + strings.Join(options, ", ") + ")"
+		}
+		sb.WriteString(fmt.Sprintf("  %s × %d  %s\n", name, item.Quantity, m.formatOrderAmount(order, item.Total)))
+	}
+	sb.WriteString("\n")
+
+	for _, line := range order.ShippingLines {
+		sb.WriteString(fmt.Sprintf("Shipping (%s): %s\n", StripHTML(line.MethodTitle), m.formatOrderAmount(order, line.Total)))
+	}
+	for _, coupon := range order.CouponLines {
+		sb.WriteString(fmt.Sprintf("Coupon: %s\n", strings.ToUpper(coupon.Code)))
+	}
+	if discount, err := m.storeSettings.ParsePrice(order.DiscountTotal); err == nil && !discount.IsZero() {
+		sb.WriteString(fmt.Sprintf("Discount: -%s\n", m.formatOrderAmount(order, order.DiscountTotal)))
+	}
+	if tax, err := m.storeSettings.ParsePrice(order.TotalTax); err == nil && !tax.IsZero() {
+		sb.WriteString(fmt.Sprintf("Tax: %s\n", m.formatOrderAmount(order, order.TotalTax)))
+	}
+	sb.WriteString(m.styles.ProductPrice.Render(fmt.Sprintf("Total: %s", m.formatOrderAmount(order, order.Total))))
+	sb.WriteString("\n")
+	if order.PaymentMethodTitle != "" {
+		sb.WriteString(fmt.Sprintf("Payment: %s\n", StripHTML(order.PaymentMethodTitle)))
+	}
+
+	// Ship to the shipping address, or the billing address if there is none
+	address := order.Shipping
+	if address.Address1 == "" && address.City == "" {
+		address = order.Billing
+	}
+	sb.WriteString("\n")
+	sb.WriteString(m.styles.Subtle.Render("Shipping Address:"))
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("  %s %s\n", address.FirstName, address.LastName))
+	if address.Address1 != "" {
+		sb.WriteString(fmt.Sprintf("  %s\n", address.Address1))
+	}
+	sb.WriteString(fmt.Sprintf("  %s, %s %s\n", address.City, address.Postcode, address.Country))
+
+	if len(m.orderNotes) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.styles.Subtle.Render("Notes from the shop:"))
+		sb.WriteString("\n")
+		for _, note := range m.orderNotes {
+			sb.WriteString(fmt.Sprintf("  %s  %s\n", formatOrderDate(note.DateCreated), StripHTML(note.Note)))
+		}
+	}
+
+	sb.WriteString("\n")
+	if m.checkingReorder {
+		sb.WriteString(m.listSpinner.View())
+		sb.WriteString(" Checking stock and prices...\n\n")
+	}
+	sb.WriteString(m.styles.HelpBar.Render("a order again • r refresh • esc back to orders"))
+
+	return m.styles.Box.Render(sb.String())
+}
+
+func (m Model) viewReorder() string {
+	var sb strings.Builder
+
+	title := "🔁 Order Again"
+	if m.selectedOrder != nil {
+		title = fmt.Sprintf("🔁 Order Again: #%d", m.selectedOrder.ID)
+	}
+	sb.WriteString(m.styles.HeaderTitle.Render(title))
+	sb.WriteString("\n\n")
+
+	available := 0
+	for _, line := range m.reorderLines {
+		name := line.Name
+		if line.GrindSize != "" {
+			name += " (" + line.GrindSize + ")"
+		}
+		name = fmt.Sprintf("%s × %d", name, line.Quantity)
+
+		switch line.Status {
+		case ReorderUnchanged:
+			sb.WriteString(fmt.Sprintf("✓ %s  %s\n", name, m.storeSettings.Format(line.Item.Price)))
+		case ReorderPriceChanged:
+			sb.WriteString(m.styles.Highlight.Render(fmt.Sprintf("~ %s  %s (price changed)", name, m.priceChange(line))))
+			sb.WriteString("\n")
+		case ReorderLowStock:
+			text := fmt.Sprintf("~ %s  only %d left, quantity reduced", name, line.Item.Quantity)
+			if line.Repriced {
+				text += fmt.Sprintf("; %s (price changed)", m.priceChange(line))
+			}
+			sb.WriteString(m.styles.Highlight.Render(text))
+			sb.WriteString("\n")
+		case ReorderOutOfStock:
+			sb.WriteString(m.styles.Error.Render(fmt.Sprintf("✗ %s  out of stock", name)))
+			sb.WriteString("\n")
+		case ReorderGrindUnavailable:
+			sb.WriteString(m.styles.Error.Render(fmt.Sprintf("✗ %s  %s grind no longer available", name, line.GrindSize)))
+			sb.WriteString("\n")
+		case ReorderOptionUnavailable:
+			sb.WriteString(m.styles.Error.Render(fmt.Sprintf("✗ %s  %s choice no longer available", name, line.Attribute)))
+			sb.WriteString("\n")
+		case ReorderDiscontinued:
+			sb.WriteString(m.styles.Error.Render(fmt.Sprintf("✗ %s  no longer available", name)))
+			sb.WriteString("\n")
+		}
+		if line.CanReorder() {
+			available++
+		}
+	}
+
+	sb.WriteString("\n")
+	if available == 0 {
+		sb.WriteString(m.styles.Error.Render("None of these items can be ordered right now."))
+		sb.WriteString("\n\n")
+		sb.WriteString(m.styles.HelpBar.Render("esc back"))
+		return m.styles.Box.Render(sb.String())
+	}
+	sb.WriteString(m.styles.Subtle.Render(fmt.Sprintf("%d of %d items can be added to your cart.", available, len(m.reorderLines))))
+	sb.WriteString("\n\n")
+	if m.err != nil {
+		sb.WriteString(m.styles.Error.Render("Error: " + userMessage(m.err)))
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString(m.styles.HelpBar.Render("enter add to cart • esc back"))
 
 	return m.styles.Box.Render(sb.String())
 }
 
+// priceChange describes how a reorder line's price changed, e.g.
+// "$45.00 → $49.99".
+func (m Model) priceChange(line ReorderLine) string {
+	change := m.storeSettings.Format(line.Item.Price)
+	if line.PaidPrice != nil {
+		change = fmt.Sprintf("%s → %s", m.formatPaidPrice(*line.PaidPrice), change)
+	}
+	return change
+}
+
+// formatPaidPrice formats a price from a past order, which may have been
+// paid in another currency.
+func (m Model) formatPaidPrice(price woo.Money) string {
+	if price.Currency() != m.storeSettings.Currency {
+		return price.Currency() + " " + price.Round(m.storeSettings.Decimals).String()
+	}
+	return m.storeSettings.Format(price)
+}
+
+// formatOrderAmount formats an amount from an order. Orders placed in
+// another currency keep their own currency code.
+func (m Model) formatOrderAmount(order *woo.OrderResponse, amount string) string {
+	if order.Currency != "" && order.Currency != m.storeSettings.Currency {
+		return order.Currency + " " + amount
+	}
+	return formatPrice(m.storeSettings, amount)
+}
+
+// orderStatusLabels are the names WooCommerce shows customers for each status.
+var orderStatusLabels = map[string]string{
+	woo.OrderStatusPending:    "Pending payment",
+	woo.OrderStatusProcessing: "Processing",
+	woo.OrderStatusOnHold:     "On hold",
+	woo.OrderStatusCompleted:  "Completed",
+	woo.OrderStatusCancelled:  "Cancelled",
+	woo.OrderStatusRefunded:   "Refunded",
+	woo.OrderStatusFailed:     "Failed",
+}
+
+// orderStatusLabel returns the customer-facing name of an order status.
+// Statuses added by plugins are shown as they are.
+func orderStatusLabel(status string) string {
+	if label, ok := orderStatusLabels[status]; ok {
+		return label
+	}
+	return status
+}
+
+// formatOrderDate formats a WooCommerce date like "2006-01-02T15:04:05"
+// as "2 Jan 2006". Dates that don't parse are returned as they are.
+func formatOrderDate(date string) string {
+	t, err := time.Parse("2006-01-02T15:04:05", date)
+	if err != nil {
+		return date
+	}
+	return t.Format("2 Jan 2006")
+}
+
 // GetSelectedProduct returns the currently selected product (for testing).
 func (m Model) GetSelectedProduct() *woo.Product {
 	return m.selectedProduct
@@ -1286,6 +4154,3 @@ func (m Model) GetViewState() ViewState {
 func (m Model) GetConfigCompleted() bool {
 	return m.configCompleted
 }
-
-
-
@@ -1,15 +1,26 @@
 package tui
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/thomas/eva-terminal-go/internal/cache"
+	"github.com/thomas/eva-terminal-go/internal/carts"
+	"github.com/thomas/eva-terminal-go/internal/termimg"
 	"github.com/thomas/eva-terminal-go/internal/woo"
 )
 
@@ -37,10 +48,10 @@ func setupTestModel(t *testing.T, products []woo.Product, variations map[int][]w
 	}))
 
 	client := woo.NewClient(server.URL)
-	productsCache := cache.New[ProductListCacheKey, []woo.Product](time.Minute)
+	productsCache := cache.New[ProductListCacheKey, woo.ProductPage](time.Minute)
 	variationsCache := cache.New[int, []woo.Variation](time.Minute)
 
-	model := NewModel(client, productsCache, variationsCache)
+	model := NewModel(client, productsCache, variationsCache, woo.DefaultStoreSettings())
 	return model, server
 }
 
@@ -196,7 +207,7 @@ func TestSimpleProductGrindSelection(t *testing.T) {
 	}
 
 	// Initialize simple configurator
-	m.initSimpleConfigurator()
+	m.initConfigurator()
 	m.viewState = ViewConfigurator
 
 	if m.GetViewState() != ViewConfigurator {
@@ -237,6 +248,119 @@ func TestFilterToggle(t *testing.T) {
 	}
 }
 
+func TestPaginationKeys(t *testing.T) {
+	model, server := setupTestModel(t, nil, nil)
+	defer server.Close()
+
+	m := model
+
+	// Simulate loading the first of three pages
+	pageOne := productsLoadedMsg{key: newProductListCacheKey(m.productsParams()), page: woo.ProductPage{
+		Products: []woo.Product{{ID: 1, Name: "Page One Coffee"}},
+		PageInfo: woo.PageInfo{Page: 1, PerPage: 20, TotalItems: 45, TotalPages: 3},
+	}}
+	newModel, _ := m.Update(pageOne)
+	m = newModel.(Model)
+
+	if m.totalPages != 3 {
+		t.Fatalf("expected 3 total pages, got %d", m.totalPages)
+	}
+
+	// 'p' on the first page should do nothing
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	m = newModel.(Model)
+	if m.currentPage != 1 {
+		t.Errorf("expected to stay on page 1, got %d", m.currentPage)
+	}
+
+	// 'n' advances to the next page
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = newModel.(Model)
+	if m.currentPage != 2 {
+		t.Errorf("expected page 2 after pressing 'n', got %d", m.currentPage)
+	}
+
+	// A late answer for the first page doesn't replace the second
+	pageOne.page.Products = []woo.Product{{ID: 1, Name: "Late Coffee"}}
+	newModel, _ = m.Update(pageOne)
+	m = newModel.(Model)
+	if m.currentPage != 2 || m.products[0].Name != "Page One Coffee" {
+		t.Errorf("expected the stale page to be dropped, got page %d with %+v", m.currentPage, m.products)
+	}
+
+	// Toggling the filter resets to the first page
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	m = newModel.(Model)
+	if m.currentPage != 1 {
+		t.Errorf("expected page 1 after toggling filter, got %d", m.currentPage)
+	}
+}
+
+func TestCategoryPicker(t *testing.T) {
+	model, server := setupTestModel(t, nil, nil)
+	defer server.Close()
+
+	m := model
+
+	// Open the picker with 'b'
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	m = newModel.(Model)
+	if m.GetViewState() != ViewCategoryPicker {
+		t.Fatalf("expected CategoryPicker view, got %v", m.GetViewState())
+	}
+	if !m.loadingCategories || cmd == nil {
+		t.Error("expected categories to be loaded on first open")
+	}
+
+	newModel, _ = m.Update(categoriesLoadedMsg{categories: []woo.Category{
+		{ID: 15, Name: "Single Origin", Count: 3},
+		{ID: 17, Name: "Decaf", Count: 1},
+	}})
+	m = newModel.(Model)
+
+	// Move to "Decaf" (index 0 is "All Coffees") and select it
+	for i := 0; i < 2; i++ {
+		newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = newModel.(Model)
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+
+	if m.GetViewState() != ViewProductList {
+		t.Errorf("expected ProductList view after selecting a category, got %v", m.GetViewState())
+	}
+	if m.categoryID() != 17 {
+		t.Errorf("expected category 17, got %d", m.categoryID())
+	}
+
+	// Back to "All Coffees"
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	m = newModel.(Model)
+	m.categoryIdx = 0
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+
+	if m.selectedCategory != nil {
+		t.Error("expected category filter to be cleared")
+	}
+
+	// A failed load isn't shown again after leaving the picker
+	m.width = 80
+	m.categories = nil
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	m = newModel.(Model)
+	newModel, _ = m.Update(errMsg{err: fmt.Errorf("categories unavailable")})
+	m = newModel.(Model)
+	if !strings.Contains(m.View(), "categories unavailable") {
+		t.Error("expected the error in the picker")
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+	if m.err != nil || strings.Contains(m.View(), "categories unavailable") {
+		t.Error("expected the error cleared when leaving the picker")
+	}
+}
+
 func TestSearchMode(t *testing.T) {
 	products := []woo.Product{
 		{ID: 1, Name: "Ethiopian Coffee", Type: "simple", Price: "18.00", StockStatus: "instock"},
@@ -359,3 +483,1617 @@ func TestConfigurationSummary(t *testing.T) {
 	}
 }
 
+func TestUserMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"woocommerce_rest_invalid_item","message":"Colombian Supremo is out of stock.","data":{"status":400}}`))
+	}))
+	defer server.Close()
+
+	client := woo.NewClient(server.URL)
+	_, err := client.CreateOrder(context.Background(), woo.OrderRequest{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	msg := userMessage(fmt.Errorf("creating order: %w", err))
+	if msg != "This coffee just sold out. Please update your cart and try again." {
+		t.Errorf("unexpected message %q", msg)
+	}
+
+	if userMessage(nil) != "" {
+		t.Error("expected empty message for nil error")
+	}
+}
+
+func TestSortToggle(t *testing.T) {
+	model, server := setupTestModel(t, nil, nil)
+	defer server.Close()
+
+	m := model
+	m.currentPage = 3
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Error("expected products to be reloaded after changing the sort order")
+	}
+	if m.currentPage != 1 {
+		t.Errorf("expected page 1 after changing the sort order, got %d", m.currentPage)
+	}
+
+	params := m.productsParams()
+	if params.OrderBy != woo.OrderByPrice || params.Order != "asc" {
+		t.Errorf("expected price asc, got %s %s", params.OrderBy, params.Order)
+	}
+
+	// Cycling past the last option wraps around to the API default
+	for i := 1; i < len(sortOptions); i++ {
+		newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+		m = newModel.(Model)
+	}
+	if params := m.productsParams(); params.OrderBy != "" || params.Order != "" {
+		t.Errorf("expected default sort after a full cycle, got %s %s", params.OrderBy, params.Order)
+	}
+}
+
+func TestFilterPanel(t *testing.T) {
+	model, server := setupTestModel(t, nil, nil)
+	defer server.Close()
+
+	m := model
+	m.localCart.AddItem(LocalCartItem{ProductID: 7, Name: "In Cart", Price: woo.NewMoney(1000, 2, "USD"), Quantity: 1})
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+	m = newModel.(Model)
+	if m.GetViewState() != ViewFilterPanel {
+		t.Fatalf("expected FilterPanel view, got %v", m.GetViewState())
+	}
+
+	press := func(msg tea.KeyMsg) {
+		newModel, _ := m.Update(msg)
+		m = newModel.(Model)
+	}
+
+	// Price: step to "15 to 25"
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	// On sale and featured
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	// Skip in stock, hide cart items
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	// SKU
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ETH")})
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	if m.GetViewState() != ViewProductList {
+		t.Errorf("expected ProductList view after applying filters, got %v", m.GetViewState())
+	}
+	if cmd == nil {
+		t.Error("expected products to be reloaded after applying filters")
+	}
+
+	params := m.productsParams()
+	if params.MinPrice != "15" || params.MaxPrice != "25" {
+		t.Errorf("expected price 15-25, got %q-%q", params.MinPrice, params.MaxPrice)
+	}
+	if !params.OnSale || !params.Featured {
+		t.Error("expected on sale and featured filters")
+	}
+	if params.InStockOnly {
+		t.Error("expected in-stock filter to be untouched")
+	}
+	if params.SKU != "ETH" {
+		t.Errorf("expected SKU ETH, got %q", params.SKU)
+	}
+	if len(params.Exclude) != 1 || params.Exclude[0] != 7 {
+		t.Errorf("expected cart product 7 to be excluded, got %v", params.Exclude)
+	}
+
+	filters := strings.Join(m.activeFilters(), ", ")
+	for _, want := range []string{"15 to 25", "On Sale", "Featured", "Not In Cart", "SKU: ETH"} {
+		if !strings.Contains(filters, want) {
+			t.Errorf("expected active filters to contain %q, got %q", want, filters)
+		}
+	}
+}
+
+func TestProductListCacheKeyCoversFilters(t *testing.T) {
+	base := woo.GetProductsParams{Page: 1, PerPage: 20}
+	variants := []woo.GetProductsParams{
+		{Page: 1, PerPage: 20, Category: 15},
+		{Page: 1, PerPage: 20, Tag: 4},
+		{Page: 1, PerPage: 20, OrderBy: woo.OrderByPrice},
+		{Page: 1, PerPage: 20, Order: "asc"},
+		{Page: 1, PerPage: 20, MinPrice: "10"},
+		{Page: 1, PerPage: 20, MaxPrice: "10"},
+		{Page: 1, PerPage: 20, OnSale: true},
+		{Page: 1, PerPage: 20, Featured: true},
+		{Page: 1, PerPage: 20, SKU: "ETH"},
+		{Page: 1, PerPage: 20, Include: []int{1}},
+		{Page: 1, PerPage: 20, Exclude: []int{1}},
+	}
+
+	baseKey := newProductListCacheKey(base)
+	for _, params := range variants {
+		if newProductListCacheKey(params) == baseKey {
+			t.Errorf("expected params %+v to change the cache key", params)
+		}
+	}
+}
+
+func TestPaymentStep(t *testing.T) {
+	var placed woo.OrderRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/payment_gateways":
+			json.NewEncoder(w).Encode([]woo.PaymentGateway{
+				{ID: "bacs", Title: "Direct bank transfer", Description: "<p>Pay into our <b>bank account</b>.</p>", Enabled: true},
+				{ID: "stripe", Title: "Credit card", Enabled: true, NeedsSetup: true},
+				{ID: "cod", Title: "Cash on delivery", Order: 1, Enabled: true},
+			})
+		case "/wp-json/wc/v3/orders":
+			json.NewDecoder(r.Body).Decode(&placed)
+			json.NewEncoder(w).Encode(woo.OrderResponse{ID: 42, Status: "pending", PaymentMethod: placed.PaymentMethod})
+		case "/wp-json/wc/v3/products":
+			json.NewEncoder(w).Encode([]woo.Product{{ID: 1, Name: "Coffee", StockStatus: "instock"}})
+		default:
+			json.NewEncoder(w).Encode([]woo.Product{})
+		}
+	}))
+	defer server.Close()
+
+	client := woo.NewClient(server.URL)
+	m := NewModel(client,
+		cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+		cache.New[int, []woo.Variation](time.Minute),
+		woo.DefaultStoreSettings())
+	m.localCart.AddItem(LocalCartItem{ProductID: 1, Name: "Coffee", Price: woo.NewMoney(1000, 2, "USD"), Quantity: 1})
+	m.localCart.Shipping = &woo.ShippingRate{InstanceID: 3, MethodID: "flat_rate", Title: "Courier", Cost: woo.NewMoney(500, 2, "USD")}
+
+	// Choosing a shipping method leads to the payment step
+	newModel, cmd := m.enterPayment()
+	m = newModel.(Model)
+	if m.GetViewState() != ViewPayment {
+		t.Fatalf("expected Payment view, got %v", m.GetViewState())
+	}
+	if !m.loadingGateways || cmd == nil {
+		t.Fatal("expected payment gateways to be loaded")
+	}
+
+	newModel, _ = m.Update(cmd())
+	m = newModel.(Model)
+	if len(m.paymentGateways) != 2 {
+		t.Fatalf("expected gateways needing setup to be hidden, got %d gateways", len(m.paymentGateways))
+	}
+
+	m.width = 80
+	if view := m.View(); !strings.Contains(view, "Pay into our bank account.") || strings.Contains(view, "<b>") {
+		t.Errorf("expected gateway description with HTML stripped, got:\n%s", view)
+	}
+
+	// Choose cash on delivery
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+
+	if m.GetViewState() != ViewReview {
+		t.Fatalf("expected Review view after choosing a gateway, got %v", m.GetViewState())
+	}
+	if m.selectedGateway == nil || m.selectedGateway.ID != "cod" {
+		t.Fatalf("expected cod to be selected, got %+v", m.selectedGateway)
+	}
+
+	// The chosen gateway goes into the order, once the stock is checked
+	newModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("expected the stock to be checked")
+	}
+	newModel, cmd = m.Update(cmd())
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatalf("expected order to be created, got stock issues %+v and err %v", m.stockIssues, m.err)
+	}
+	newModel, _ = m.Update(cmd())
+	m = newModel.(Model)
+
+	if placed.PaymentMethod != "cod" || placed.PaymentMethodTitle != "Cash on delivery" {
+		t.Errorf("expected cod payment in the order, got %q/%q", placed.PaymentMethod, placed.PaymentMethodTitle)
+	}
+	if len(placed.ShippingLines) != 1 {
+		t.Fatalf("expected one shipping line, got %+v", placed.ShippingLines)
+	}
+	if line := placed.ShippingLines[0]; line.MethodID != "flat_rate" || line.InstanceID != "3" || line.Total != "5.00" {
+		t.Errorf("expected the chosen shipping method in the order, got %+v", line)
+	}
+	if m.GetViewState() != ViewOrderConfirmation {
+		t.Errorf("expected OrderConfirmation view, got %v", m.GetViewState())
+	}
+}
+
+func TestShippingStep(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/shipping/zones":
+			w.Write([]byte(`[{"id":1,"name":"Italy","order":0},{"id":0,"name":"Everywhere else","order":0}]`))
+		case "/wp-json/wc/v3/shipping/zones/1/locations":
+			w.Write([]byte(`[{"code":"IT","type":"country"}]`))
+		case "/wp-json/wc/v3/shipping/zones/1/methods":
+			w.Write([]byte(`[
+				{"instance_id":1,"title":"Courier","order":1,"enabled":true,"method_id":"flat_rate","settings":{"cost":{"value":"5.00"}}},
+				{"instance_id":2,"title":"Free shipping","order":2,"enabled":true,"method_id":"free_shipping","settings":{"requires":{"value":"min_amount"},"min_amount":{"value":"50"}}}
+			]`))
+		case "/wp-json/wc/v3/shipping/zones/0/methods", "/wp-json/wc/v3/data/continents":
+			w.Write([]byte(`[]`))
+		case "/wp-json/wc/v3/payment_gateways":
+			w.Write([]byte(`[]`))
+		default:
+			json.NewEncoder(w).Encode([]woo.Product{})
+		}
+	}))
+	defer server.Close()
+
+	client := woo.NewClient(server.URL)
+	m := NewModel(client,
+		cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+		cache.New[int, []woo.Variation](time.Minute),
+		woo.DefaultStoreSettings())
+	m.width = 80
+	m.localCart.AddItem(LocalCartItem{ProductID: 1, Name: "Coffee", Price: woo.NewMoney(1800, 2, "USD"), Quantity: 1})
+	m.customerInfo.Country = "IT"
+
+	newModel, cmd := m.enterShipping()
+	m = newModel.(Model)
+	if m.GetViewState() != ViewShipping || cmd == nil {
+		t.Fatalf("expected Shipping view loading the zones, got %v", m.GetViewState())
+	}
+	newModel, _ = m.Update(cmd())
+	m = newModel.(Model)
+
+	// Below the free shipping minimum only the flat rate applies
+	if len(m.shippingRates) != 1 || m.shippingRates[0].Title != "Courier" {
+		t.Fatalf("expected only the flat rate, got %+v", m.shippingRates)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	if m.GetViewState() != ViewPayment {
+		t.Fatalf("expected Payment view after choosing a method, got %v", m.GetViewState())
+	}
+	if m.localCart.Shipping == nil || m.localCart.Shipping.InstanceID != 1 {
+		t.Fatalf("expected the flat rate to be chosen, got %+v", m.localCart.Shipping)
+	}
+	if got := m.localCart.CalculateTotal().String(); got != "23.00" {
+		t.Errorf("expected total 23.00 including shipping, got %s", got)
+	}
+
+	// Zone 0 has no methods, so the store doesn't ship outside Italy
+	m.customerInfo.Country = "US"
+	newModel, _ = m.enterShipping()
+	m = newModel.(Model)
+	if len(m.shippingRates) != 0 {
+		t.Errorf("expected no rates for the US, got %+v", m.shippingRates)
+	}
+	if view := m.View(); !strings.Contains(view, "don't ship to US") {
+		t.Errorf("expected a 'don't ship' message, got:\n%s", view)
+	}
+}
+
+func TestReviewTaxEstimate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/settings/tax":
+			w.Write([]byte(`[{"id": "woocommerce_prices_include_tax", "value": "no"}, {"id": "woocommerce_tax_based_on", "value": "billing"}]`))
+		case "/wp-json/wc/v3/taxes/classes":
+			w.Write([]byte(`[{"slug": "standard", "name": "Standard"}]`))
+		case "/wp-json/wc/v3/taxes":
+			w.Write([]byte(`[{"id": 1, "country": "IT", "rate": "22.0000", "name": "IVA", "priority": 1, "shipping": true, "class": "standard"}]`))
+		case "/wp-json/wc/v3/orders":
+			// The store rounds differently from the estimate
+			json.NewEncoder(w).Encode(woo.OrderResponse{ID: 43, Status: "pending", Currency: "EUR", Total: "52.43", TotalTax: "9.45"})
+		case "/wp-json/wc/v3/products":
+			json.NewEncoder(w).Encode([]woo.Product{{ID: 1, Name: "Coffee", StockStatus: "instock"}})
+		default:
+			json.NewEncoder(w).Encode([]woo.Product{})
+		}
+	}))
+	defer server.Close()
+
+	settings := italianStore
+	settings.TaxesEnabled = true
+	client := woo.NewClient(server.URL)
+	m := NewModel(client,
+		cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+		cache.New[int, []woo.Variation](time.Minute),
+		settings)
+	m.width = 80
+	m.localCart.AddItem(LocalCartItem{ProductID: 1, Name: "Coffee", Price: eur(1899), Quantity: 2})
+	m.localCart.Shipping = &woo.ShippingRate{InstanceID: 1, MethodID: "flat_rate", Title: "Corriere", Cost: eur(500), Taxable: true}
+	m.selectedGateway = &woo.PaymentGateway{ID: "bacs", Title: "Bank transfer"}
+	m.customerInfo.Country = "IT"
+
+	newModel, cmd := m.enterReview()
+	m = newModel.(Model)
+	if !m.loadingTax || cmd == nil {
+		t.Fatal("expected the tax rates to be loaded")
+	}
+
+	// Orders can't be placed until the tax is worked out
+	if _, placeCmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")}); placeCmd != nil {
+		t.Error("expected the order to wait for the tax estimate")
+	}
+
+	newModel, _ = m.Update(cmd())
+	m = newModel.(Model)
+
+	// 22% of 37.98 plus 22% of 5.00 shipping
+	if got := m.localCart.CalculateTax().String(); got != "9.46" {
+		t.Errorf("expected 9.46 of tax, got %s", got)
+	}
+	view := m.View()
+	if !strings.Contains(view, "IVA 22%: 9,46 €") || !strings.Contains(view, "Total: 52,44 €") {
+		t.Errorf("expected the tax breakdown and total in the review, got:\n%s", view)
+	}
+
+	newModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = newModel.(Model)
+	newModel, cmd = m.Update(cmd())
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("expected order to be created")
+	}
+	newModel, _ = m.Update(cmd())
+	m = newModel.(Model)
+
+	if view := m.View(); !strings.Contains(view, "Tax charged: 9,45 € (estimated 9,46 €)") {
+		t.Errorf("expected a warning about the tax difference, got:\n%s", view)
+	}
+}
+
+func TestEstimateTaxBaseAddress(t *testing.T) {
+	settings := italianStore
+	settings.DefaultCountry, settings.DefaultState = "IT", "MI"
+	m := NewModel(woo.NewClient("http://localhost"),
+		cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+		cache.New[int, []woo.Variation](time.Minute),
+		settings)
+	m.taxTable = &woo.TaxTable{
+		BasedOn: woo.TaxBasedOnBase,
+		Rates: []woo.TaxRate{
+			{ID: 1, Country: "IT", State: "MI", Rate: "10.0000", Name: "Milano", Priority: 1, Class: "standard"},
+			{ID: 2, Country: "IT", Rate: "22.0000", Name: "IVA", Priority: 1, Class: "standard"},
+		},
+	}
+	m.localCart.AddItem(LocalCartItem{ProductID: 1, Name: "Coffee", Price: eur(1000), Quantity: 1})
+	m.customerInfo.Country, m.customerInfo.State = "IT", "RM"
+
+	// The store is in Milan, wherever the customer is
+	m.estimateTax()
+	if got := m.localCart.CalculateTax().String(); got != "1.00" {
+		t.Errorf("expected the 10%% Milan rate, got %s of tax", got)
+	}
+}
+
+func TestCartCoupon(t *testing.T) {
+	var placed woo.OrderRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/coupons":
+			coupons := []woo.Coupon{}
+			if r.URL.Query().Get("code") == "benvenuto10" {
+				coupons = append(coupons, woo.Coupon{ID: 7, Code: "benvenuto10", DiscountType: woo.DiscountPercent, Amount: "10.00"})
+			}
+			json.NewEncoder(w).Encode(coupons)
+		case "/wp-json/wc/v3/orders":
+			json.NewDecoder(r.Body).Decode(&placed)
+			json.NewEncoder(w).Encode(woo.OrderResponse{ID: 44, Status: "pending"})
+		default:
+			json.NewEncoder(w).Encode([]woo.Product{})
+		}
+	}))
+	defer server.Close()
+
+	client := woo.NewClient(server.URL)
+	m := NewModel(client,
+		cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+		cache.New[int, []woo.Variation](time.Minute),
+		woo.DefaultStoreSettings())
+	m.width = 80
+	m.viewState = ViewCart
+	m.localCart.AddItem(LocalCartItem{ProductID: 1, Name: "Coffee", Price: woo.NewMoney(2000, 2, "USD"), Quantity: 1})
+
+	applyCoupon := func(m Model, code string) Model {
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+		m = newModel.(Model)
+		if !m.showCouponInput {
+			t.Fatal("expected the coupon input to open")
+		}
+		newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(code)})
+		m = newModel.(Model)
+		newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = newModel.(Model)
+		if !m.checkingCoupon || cmd == nil {
+			t.Fatal("expected the coupon to be checked")
+		}
+		newModel, _ = m.Update(cmd())
+		return newModel.(Model)
+	}
+
+	m = applyCoupon(m, "NOPE")
+	if m.localCart.Coupon != nil || !strings.Contains(m.View(), "That coupon code doesn't exist.") {
+		t.Errorf("expected an unknown coupon to be rejected, got:\n%s", m.View())
+	}
+
+	m = applyCoupon(m, "Benvenuto10")
+	if m.localCart.Coupon == nil || m.couponErr != nil {
+		t.Fatalf("expected the coupon to be applied, got error %v", m.couponErr)
+	}
+	if view := m.View(); !strings.Contains(view, "Coupon BENVENUTO10: -$2.00") || !strings.Contains(view, "Total: $18.00") {
+		t.Errorf("expected the discount in the cart totals, got:\n%s", view)
+	}
+
+	// The coupon goes into the order
+	m.localCart.Shipping = &woo.ShippingRate{MethodID: "free_shipping", Title: "Free shipping", Cost: woo.ZeroMoney("USD")}
+	m.selectedGateway = &woo.PaymentGateway{ID: "cod", Title: "Cash on delivery"}
+	m.createOrder()()
+	if len(placed.CouponLines) != 1 || placed.CouponLines[0].Code != "benvenuto10" {
+		t.Errorf("expected the coupon in the order, got %+v", placed.CouponLines)
+	}
+
+	// Removing the coupon
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m = newModel.(Model)
+	if m.localCart.Coupon != nil {
+		t.Error("expected x to remove the coupon")
+	}
+}
+
+// testCustomerLinks is an in-memory CustomerLinker.
+type testCustomerLinks map[string]int
+
+func (l testCustomerLinks) CustomerID(fingerprint string) (int, bool) {
+	id, ok := l[fingerprint]
+	return id, ok
+}
+
+func (l testCustomerLinks) Link(fingerprint string, customerID int) error {
+	l[fingerprint] = customerID
+	return nil
+}
+
+func TestCustomerAccount(t *testing.T) {
+	var placed woo.OrderRequest
+	var created, updated woo.CustomerRequest
+	var lookup url.Values
+	refuseOrders, failOrders := false, false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/wp-json/wc/v3/orders" && r.Method == http.MethodGet:
+			lookup = r.URL.Query()
+			json.NewEncoder(w).Encode([]woo.OrderResponse{})
+		case r.URL.Path == "/wp-json/wc/v3/orders" && failOrders:
+			json.NewDecoder(r.Body).Decode(&placed)
+			w.WriteHeader(http.StatusBadGateway)
+		case r.URL.Path == "/wp-json/wc/v3/orders" && refuseOrders:
+			json.NewDecoder(r.Body).Decode(&placed)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"woocommerce_rest_invalid_coupon","message":"Coupon expired","data":{"status":400}}`))
+		case r.URL.Path == "/wp-json/wc/v3/customers/7" && r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(woo.Customer{
+				ID: 7, Email: "ada@example.com", FirstName: "Ada", LastName: "Lovelace",
+				Billing:  woo.BillingAddress{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Phone: "555", Address1: "Via Roma 1", City: "Roma", Postcode: "00186", Country: "IT"},
+				Shipping: woo.BillingAddress{FirstName: "Ada", LastName: "Lovelace", Address1: "Via Roma 1", City: "Roma", Postcode: "00186", Country: "IT"},
+			})
+		case r.URL.Path == "/wp-json/wc/v3/customers/7" && r.Method == http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&updated)
+			json.NewEncoder(w).Encode(woo.Customer{ID: 7, Email: "ada@example.com", Billing: *updated.Billing, Shipping: *updated.Shipping})
+		case r.URL.Path == "/wp-json/wc/v3/customers":
+			json.NewDecoder(r.Body).Decode(&created)
+			json.NewEncoder(w).Encode(woo.Customer{ID: 8, Email: created.Email})
+		case r.URL.Path == "/wp-json/wc/v3/orders":
+			json.NewDecoder(r.Body).Decode(&placed)
+			json.NewEncoder(w).Encode(woo.OrderResponse{ID: 45, Status: "pending", CustomerID: placed.CustomerID})
+		default:
+			json.NewEncoder(w).Encode([]woo.Product{})
+		}
+	}))
+	defer server.Close()
+
+	newModel := func(links testCustomerLinks, fingerprint string) Model {
+		m := NewModel(woo.NewClient(server.URL),
+			cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+			cache.New[int, []woo.Variation](time.Minute),
+			woo.DefaultStoreSettings())
+		if links != nil {
+			m = m.WithCustomerLinks(links, fingerprint)
+		}
+		m.localCart.AddItem(LocalCartItem{ProductID: 1, Name: "Coffee", Price: woo.NewMoney(1000, 2, "USD"), Quantity: 1})
+		m.localCart.Shipping = &woo.ShippingRate{MethodID: "flat_rate", Title: "Courier", Cost: woo.NewMoney(500, 2, "USD")}
+		m.selectedGateway = &woo.PaymentGateway{ID: "cod", Title: "Cash on delivery"}
+		return m
+	}
+
+	t.Run("linked key", func(t *testing.T) {
+		m := newModel(testCustomerLinks{"SHA256:ada": 7}, "SHA256:ada")
+		updated = woo.CustomerRequest{}
+
+		// The address form is pre-filled from the account
+		next, _ := m.Update(m.loadCustomer()())
+		m = next.(Model)
+		if m.customerInfo.Email != "ada@example.com" || m.customerInfo.City != "Roma" || m.customerInfo.Country != "IT" {
+			t.Fatalf("expected checkout to be pre-filled, got %+v", m.customerInfo)
+		}
+
+		// An unchanged address places the order on the account without an update
+		next, _ = m.Update(m.createOrder()())
+		m = next.(Model)
+		if placed.CustomerID != 7 {
+			t.Errorf("expected the order on customer 7, got %d", placed.CustomerID)
+		}
+		if updated.Billing != nil {
+			t.Errorf("did not expect the account to be updated, got %+v", updated)
+		}
+
+		// A new address is saved to the account, keeping the phone number
+		m.localCart.AddItem(LocalCartItem{ProductID: 1, Name: "Coffee", Price: woo.NewMoney(1000, 2, "USD"), Quantity: 1})
+		m.localCart.Shipping = &woo.ShippingRate{MethodID: "flat_rate", Title: "Courier", Cost: woo.NewMoney(500, 2, "USD")}
+		m.customerInfo.City = "Milano"
+		next, _ = m.Update(m.createOrder()())
+		m = next.(Model)
+		if updated.Shipping == nil || updated.Shipping.City != "Milano" || updated.Billing.Phone != "555" {
+			t.Errorf("expected the new address on the account, got %+v", updated)
+		}
+		m.width = 80
+		if view := m.View(); !strings.Contains(view, "Order saved to your account (ada@example.com)") {
+			t.Errorf("expected the account on the confirmation, got:\n%s", view)
+		}
+	})
+
+	t.Run("unlinked key", func(t *testing.T) {
+		links := testCustomerLinks{}
+		m := newModel(links, "SHA256:new")
+		*m.customerInfo = CustomerInfo{FirstName: "Grace", LastName: "Hopper", Email: "grace@example.com", City: "Roma", Country: "IT", CreateAccount: true}
+
+		next, _ := m.Update(m.createOrder()())
+		m = next.(Model)
+		if created.Email != "grace@example.com" || created.Shipping == nil || created.Shipping.Email != "" {
+			t.Errorf("expected an account to be created from the checkout, got %+v", created)
+		}
+		if placed.CustomerID != 8 || links["SHA256:new"] != 8 || m.customerID != 8 {
+			t.Errorf("expected the new account to be linked and used, got order customer %d, links %v", placed.CustomerID, links)
+		}
+	})
+
+	t.Run("account not asked for", func(t *testing.T) {
+		links := testCustomerLinks{}
+		m := newModel(links, "SHA256:guest")
+		*m.customerInfo = CustomerInfo{FirstName: "Alan", LastName: "Turing", Email: "alan@example.com", City: "Roma", Country: "IT"}
+		created, placed = woo.CustomerRequest{}, woo.OrderRequest{}
+
+		m.createOrder()()
+		if created.Email != "" || placed.CustomerID != 0 || len(links) != 0 {
+			t.Errorf("expected a guest order without an account, got account %+v on customer %d", created, placed.CustomerID)
+		}
+	})
+
+	t.Run("order refused", func(t *testing.T) {
+		links := testCustomerLinks{}
+		m := newModel(links, "SHA256:retry")
+		*m.customerInfo = CustomerInfo{FirstName: "Grace", LastName: "Hopper", Email: "grace@example.com", City: "Roma", Country: "IT", CreateAccount: true}
+		refuseOrders = true
+		defer func() { refuseOrders = false }()
+
+		// The account opened for the order is kept, so trying again uses it
+		next, _ := m.Update(m.createOrder()())
+		m = next.(Model)
+		if m.err == nil || m.creatingOrder {
+			t.Fatalf("expected the order error, got %v", m.err)
+		}
+		if m.customerID != 8 || links["SHA256:retry"] != 8 {
+			t.Fatalf("expected the new account to be kept, got customer %d, links %v", m.customerID, links)
+		}
+		refuseOrders = false
+		created = woo.CustomerRequest{}
+		m.createOrder()()
+		if created.Email != "" || placed.CustomerID != 8 {
+			t.Errorf("expected the retry on customer 8 without a new account, got customer %d", placed.CustomerID)
+		}
+	})
+
+	t.Run("order uncertain", func(t *testing.T) {
+		links := testCustomerLinks{}
+		m := newModel(links, "SHA256:lost")
+		*m.customerInfo = CustomerInfo{FirstName: "Grace", LastName: "Hopper", Email: "grace@example.com", City: "Roma", Country: "IT", CreateAccount: true}
+		failOrders = true
+		defer func() { failOrders = false }()
+
+		// The store is asked for the order on the new account
+		next, cmd := m.Update(m.createOrder()())
+		m = next.(Model)
+		if !m.checkingOrder || cmd == nil {
+			t.Fatalf("expected the order to be looked up, got %v", m.err)
+		}
+		next, cmd = m.Update(cmd())
+		m = next.(Model)
+		if lookup.Get("customer") != "8" {
+			t.Errorf("expected the lookup on customer 8, got %v", lookup)
+		}
+
+		// It wasn't placed, so it is sent again for the same account
+		failOrders = false
+		created, placed = woo.CustomerRequest{}, woo.OrderRequest{}
+		if cmd == nil {
+			t.Fatal("expected the order to be sent again")
+		}
+		next, _ = m.Update(cmd())
+		m = next.(Model)
+		if created.Email != "" || placed.CustomerID != 8 || m.customerID != 8 || m.customer == nil {
+			t.Errorf("expected the retry on customer 8 without a new account, got customer %d", placed.CustomerID)
+		}
+		if m.GetViewState() != ViewOrderConfirmation {
+			t.Errorf("expected the order to be placed, got %v", m.err)
+		}
+	})
+
+	t.Run("no accounts", func(t *testing.T) {
+		m := newModel(nil, "")
+		placed = woo.OrderRequest{}
+		m.createOrder()()
+		if placed.CustomerID != 0 {
+			t.Errorf("expected a guest order, got customer %d", placed.CustomerID)
+		}
+	})
+}
+
+func TestOrderHistory(t *testing.T) {
+	var listQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/orders":
+			listQuery = r.URL.Query()
+			w.Header().Set("X-WP-Total", "2")
+			w.Header().Set("X-WP-TotalPages", "1")
+			json.NewEncoder(w).Encode([]woo.OrderResponse{
+				{ID: 46, Status: "processing", Currency: "USD", Total: "42.98", DateCreated: "2026-10-02T09:30:00", LineItems: []woo.OrderLineItem{{Quantity: 2}, {Quantity: 1}}},
+				{ID: 41, Status: "completed", Currency: "USD", Total: "12.00", DateCreated: "2026-09-14T18:00:00", LineItems: []woo.OrderLineItem{{Quantity: 1}}},
+			})
+		case "/wp-json/wc/v3/orders/46":
+			json.NewEncoder(w).Encode(woo.OrderResponse{
+				ID: 46, Status: "processing", Currency: "USD", Total: "42.98", DateCreated: "2026-10-02T09:30:00",
+				LineItems: []woo.OrderLineItem{
+					{ProductID: 101, Name: "House Blend", Quantity: 2, Total: "30.00", MetaData: []woo.OrderLineItemMetaData{woo.StringLineItemMetaData("pa_grind-size", "espresso")}},
+					{ProductID: 1, Name: "Ethiopia", Quantity: 1, Total: "7.98"},
+				},
+				ShippingLines: []woo.ShippingLine{{MethodTitle: "Courier", Total: "5.00"}},
+				Shipping:      woo.BillingAddress{FirstName: "Ada", LastName: "Lovelace", Address1: "Via Roma 1", City: "Roma", Postcode: "00186", Country: "IT"},
+			})
+		case "/wp-json/wc/v3/orders/46/notes":
+			w.Write([]byte(`[{"id": 9, "date_created": "2026-10-03T08:00:00", "note": "<p>Your coffee has been <b>roasted</b>.</p>", "customer_note": true}]`))
+		default:
+			json.NewEncoder(w).Encode([]woo.Product{})
+		}
+	}))
+	defer server.Close()
+
+	newModel := func() Model {
+		m := NewModel(woo.NewClient(server.URL),
+			cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+			cache.New[int, []woo.Variation](time.Minute),
+			woo.DefaultStoreSettings())
+		m.width = 100
+		return m
+	}
+	press := func(m Model, key tea.KeyMsg) (Model, tea.Cmd) {
+		next, cmd := m.Update(key)
+		return next.(Model), cmd
+	}
+	o := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")}
+
+	// A guest with no orders in this session has nothing to look up
+	m, cmd := press(newModel(), o)
+	if m.GetViewState() != ViewOrders || cmd != nil {
+		t.Fatalf("expected the orders view without a request, got %v", m.GetViewState())
+	}
+	if !strings.Contains(m.View(), "No orders yet.") {
+		t.Errorf("expected an empty order history, got:\n%s", m.View())
+	}
+
+	// A guest sees the orders placed in this session
+	m = newModel()
+	m.placedOrderIDs = []int{41, 46}
+	m, cmd = press(m, o)
+	m.Update(cmd())
+	if listQuery.Get("include") != "41,46" || listQuery.Get("customer") != "" {
+		t.Errorf("expected the session's orders to be listed, got %v", listQuery)
+	}
+
+	// A customer sees every order on their account
+	m = newModel()
+	m.customerID = 7
+	m, cmd = press(m, o)
+	if !m.loadingOrders || cmd == nil {
+		t.Fatal("expected orders to be loaded")
+	}
+	next, _ := m.Update(cmd())
+	m = next.(Model)
+	if listQuery.Get("customer") != "7" {
+		t.Errorf("expected the customer's orders to be listed, got %v", listQuery)
+	}
+	view := m.View()
+	for _, want := range []string{"#46", "2 Oct 2026", "Processing", "3 items", "$42.98", "Completed", "1 item "} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the order list, got:\n%s", want, view)
+		}
+	}
+
+	// The detail view has the items, shipping, address and customer notes
+	m, cmd = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.GetViewState() != ViewOrderDetail || cmd == nil {
+		t.Fatalf("expected the order detail to load, got %v", m.GetViewState())
+	}
+	next, _ = m.Update(cmd())
+	m = next.(Model)
+	view = m.View()
+	for _, want := range []string{"Order #46", "House Blend (espresso) × 2", "Shipping (Courier): $5.00", "Via Roma 1", "Your coffee has been roasted."} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the order detail, got:\n%s", want, view)
+		}
+	}
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.GetViewState() != ViewOrders {
+		t.Errorf("expected esc to go back to the orders, got %v", m.GetViewState())
+	}
+}
+
+func TestSavedCart(t *testing.T) {
+	products := []woo.Product{{ID: 1, Name: "Ethiopia", Type: "simple", Price: "18.99", StockStatus: "instock"}}
+	store := carts.NewMemoryStore(time.Hour)
+
+	// A new session adds to its cart, which is saved in the background
+	m, server := setupTestModel(t, products, nil)
+	defer server.Close()
+	m = m.WithCartStore(store, "SHA256:ada")
+	m.localCart.AddItem(LocalCartItem{ProductID: 1, Name: "Ethiopia", Price: woo.NewMoney(1899, 2, "USD"), Quantity: 1, GrindSize: "Espresso"})
+	m.viewState = ViewCart
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'+'}})
+	m = next.(Model)
+	if cmd == nil {
+		t.Fatal("expected a command reporting the save")
+	}
+	m.FlushCart()
+
+	saved, err := store.Load("SHA256:ada")
+	if err != nil || saved == nil {
+		t.Fatalf("expected the cart to be saved, got %+v, %v", saved, err)
+	}
+	if len(saved.Items) != 1 || saved.Items[0].Quantity != 2 || saved.Items[0].Price != "18.99" {
+		t.Errorf("unexpected saved cart %+v", saved)
+	}
+
+	// The connection drops; the next session with the same key gets the cart back
+	m, server = setupTestModel(t, products, nil)
+	defer server.Close()
+	m = m.WithCartStore(store, "SHA256:ada")
+	if m.localCart.ItemCount() != 2 || m.localCart.Items[0].GrindSize != "Espresso" || m.localCart.GetSubtotal() != "$37.98" {
+		t.Errorf("expected the cart to be restored, got %+v", m.localCart.Items)
+	}
+
+	// Other keys don't see it
+	other, server := setupTestModel(t, products, nil)
+	defer server.Close()
+	if other = other.WithCartStore(store, "SHA256:grace"); !other.localCart.IsEmpty() {
+		t.Errorf("expected an empty cart for another key, got %+v", other.localCart.Items)
+	}
+
+	// A change that leaves the cart as it was isn't saved
+	m.localCart.UpdateQuantity(0, m.localCart.Items[0].Quantity)
+	if next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}}); cmd != nil {
+		t.Error("did not expect an unchanged cart to be saved")
+	}
+	m = next.(Model)
+
+	// Emptying the cart removes it from the store
+	m.viewState = ViewCart
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = next.(Model)
+	m.FlushCart()
+	if saved, _ := store.Load("SHA256:ada"); saved != nil {
+		t.Errorf("expected the emptied cart to be removed, got %+v", saved)
+	}
+}
+
+func TestResumeCartBanner(t *testing.T) {
+	store := carts.NewMemoryStore(0)
+	store.Save("SHA256:ada", carts.Cart{
+		Currency:  "USD",
+		Items:     []carts.Item{{ProductID: 1, Name: "Ethiopia", Price: "18.99", Quantity: 2}},
+		UpdatedAt: time.Now().Add(-3 * time.Hour),
+	})
+
+	m, server := setupTestModel(t, nil, nil)
+	defer server.Close()
+	m = m.WithCartStore(store, "SHA256:ada")
+	m.width = 120
+
+	view := m.View()
+	if !strings.Contains(view, "Welcome back! You left 2 items ($37.98) in your cart 3 hours ago.") {
+		t.Errorf("expected the resume banner, got:\n%s", view)
+	}
+
+	// Resuming opens the cart and hides the banner
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	resumed := next.(Model)
+	if resumed.GetViewState() != ViewCart || resumed.offerCartResume || resumed.localCart.ItemCount() != 2 {
+		t.Errorf("expected the restored cart to be shown, got view %v", resumed.GetViewState())
+	}
+
+	// Starting fresh empties the cart and the store
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	fresh := next.(Model)
+	fresh.FlushCart()
+	if !fresh.localCart.IsEmpty() || fresh.offerCartResume {
+		t.Errorf("expected an empty cart without the banner, got %+v", fresh.localCart.Items)
+	}
+	if saved, _ := store.Load("SHA256:ada"); saved != nil {
+		t.Errorf("expected the saved cart to be removed, got %+v", saved)
+	}
+	if strings.Contains(fresh.View(), "Welcome back") {
+		t.Error("expected the banner to be gone")
+	}
+}
+
+func TestOrderSafeRetry(t *testing.T) {
+	var posted []woo.OrderRequest
+	var saved []woo.OrderResponse
+	saveBeforeFailing := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/wp-json/wc/v3/orders" && r.Method == http.MethodPost:
+			var req woo.OrderRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			posted = append(posted, req)
+			order := woo.OrderResponse{ID: 50 + len(posted), Status: "pending", Billing: req.Billing, MetaData: req.MetaData}
+			if len(posted) == 1 {
+				// The first attempt fails on the way back, maybe after saving
+				if saveBeforeFailing {
+					saved = append(saved, order)
+				}
+				w.WriteHeader(http.StatusGatewayTimeout)
+				w.Write([]byte("upstream timed out"))
+				return
+			}
+			saved = append(saved, order)
+			json.NewEncoder(w).Encode(order)
+		case r.URL.Path == "/wp-json/wc/v3/orders":
+			json.NewEncoder(w).Encode(saved)
+		case r.URL.Path == "/wp-json/wc/v3/products":
+			json.NewEncoder(w).Encode([]woo.Product{{ID: 1, Name: "Coffee", StockStatus: "instock"}})
+		default:
+			json.NewEncoder(w).Encode([]woo.Product{})
+		}
+	}))
+	defer server.Close()
+
+	placeOrder := func(t *testing.T) Model {
+		posted, saved = nil, nil
+		m := NewModel(woo.NewClient(server.URL),
+			cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+			cache.New[int, []woo.Variation](time.Minute),
+			woo.DefaultStoreSettings())
+		m.localCart.AddItem(LocalCartItem{ProductID: 1, Name: "Coffee", Price: woo.NewMoney(1000, 2, "USD"), Quantity: 1})
+		m.localCart.Shipping = &woo.ShippingRate{MethodID: "flat_rate", Title: "Courier", Cost: woo.NewMoney(500, 2, "USD")}
+		m.selectedGateway = &woo.PaymentGateway{ID: "bacs", Title: "Bank transfer"}
+		*m.customerInfo = CustomerInfo{FirstName: "Ada", Email: "ada@example.com", Country: "IT"}
+		m.viewState = ViewReview
+		m.width = 100
+
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = next.(Model)
+		next, cmd = m.Update(cmd()) // Stock check
+		m = next.(Model)
+		next, cmd = m.Update(cmd())
+		m = next.(Model)
+
+		// Instead of the raw error, the review says it is checking
+		if !m.checkingOrder {
+			t.Fatalf("expected the order to be looked up, got err %v", m.err)
+		}
+		if view := m.View(); !strings.Contains(view, "Order may have been placed, checking…") {
+			t.Errorf("expected the checking state, got:\n%s", view)
+		}
+		// Keys can't place a second order meanwhile
+		if _, keyCmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); keyCmd != nil {
+			t.Error("expected enter to do nothing while checking")
+		}
+
+		for cmd != nil {
+			msg := cmd()
+			if batch, ok := msg.(tea.BatchMsg); ok {
+				cmd = nil
+				for _, c := range batch {
+					if c != nil {
+						next, cmd = m.Update(c())
+						m = next.(Model)
+					}
+				}
+				continue
+			}
+			next, cmd = m.Update(msg)
+			m = next.(Model)
+		}
+		return m
+	}
+
+	t.Run("saved before failing", func(t *testing.T) {
+		saveBeforeFailing = true
+		m := placeOrder(t)
+		if len(posted) != 1 {
+			t.Errorf("expected the order to be sent once, got %d", len(posted))
+		}
+		if m.GetViewState() != ViewOrderConfirmation || m.orderResponse == nil || m.orderResponse.ID != 51 {
+			t.Errorf("expected the saved order 51 to be confirmed, got view %v, err %v", m.GetViewState(), m.err)
+		}
+	})
+
+	t.Run("not saved", func(t *testing.T) {
+		saveBeforeFailing = false
+		m := placeOrder(t)
+		if len(posted) != 2 {
+			t.Fatalf("expected the order to be sent again, got %d", len(posted))
+		}
+		first, second := posted[0].MetaData, posted[1].MetaData
+		if len(first) != 1 || first[0].Key != woo.OrderTokenMetaKey || string(first[0].Value) != string(second[0].Value) {
+			t.Errorf("expected both attempts to carry the same token, got %s and %s", first, second)
+		}
+		if m.GetViewState() != ViewOrderConfirmation || len(saved) != 1 {
+			t.Errorf("expected one order to be placed, got view %v, %d orders", m.GetViewState(), len(saved))
+		}
+	})
+}
+
+func TestOrderTracking(t *testing.T) {
+	status := woo.OrderStatusOnHold
+	var notes []woo.OrderNote
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/orders/52":
+			json.NewEncoder(w).Encode(woo.OrderResponse{ID: 52, Status: status})
+		case "/wp-json/wc/v3/orders/52/notes":
+			json.NewEncoder(w).Encode(notes)
+		default:
+			json.NewEncoder(w).Encode([]woo.Product{})
+		}
+	}))
+	defer server.Close()
+
+	m := NewModel(woo.NewClient(server.URL),
+		cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+		cache.New[int, []woo.Variation](time.Minute),
+		woo.DefaultStoreSettings())
+	m.viewState = ViewOrderConfirmation
+	m.orderResponse = &woo.OrderResponse{ID: 52, Status: woo.OrderStatusOnHold}
+	m.width = 100
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	m = next.(Model)
+	if m.GetViewState() != ViewTracking || cmd == nil {
+		t.Fatalf("expected tracking to start, got view %v", m.GetViewState())
+	}
+	first := cmd()
+
+	// The bank transfer arrives and the shop leaves a note
+	status = woo.OrderStatusProcessing
+	notes = []woo.OrderNote{{ID: 1, Note: "Payment received, roasting now.", CustomerNote: true, DateCreated: "2026-06-01T10:00:00"}}
+	next, _ = m.Update(first)
+	m = next.(Model)
+	next, cmd = m.Update(m.pollTrackedOrder()())
+	m = next.(Model)
+	if len(m.trackingSteps) != 2 || m.trackingSteps[1].Status != woo.OrderStatusProcessing || m.trackingPolls != 0 {
+		t.Errorf("expected the new status to be recorded, got %+v", m.trackingSteps)
+	}
+	view := m.View()
+	for _, want := range []string{"Tracking Order #52", "✓ On hold", "● Processing", "○ Completed", "Payment received, roasting now."} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the tracking view, got:\n%s", want, view)
+		}
+	}
+	if cmd == nil {
+		t.Fatal("expected the next poll to be scheduled")
+	}
+
+	// Leaving the view stops polling: a poll already scheduled is dropped,
+	// and one on its way is cancelled
+	inFlight := m.pollTrackedOrder()
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(Model)
+	if _, cmd := m.Update(trackingTickMsg{session: m.trackingSession - 1}); cmd != nil {
+		t.Error("expected no poll after leaving the view")
+	}
+	if msg := inFlight(); msg != nil {
+		t.Errorf("expected the cancelled poll to report nothing, got %+v", msg)
+	}
+
+	// A completed order stops polling by itself
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	m = next.(Model)
+	status = woo.OrderStatusCompleted
+	next, cmd = m.Update(cmd())
+	m = next.(Model)
+	if cmd != nil {
+		t.Error("expected no poll to be scheduled for a completed order")
+	}
+	if view := m.View(); !strings.Contains(view, "This order won't change any more.") {
+		t.Errorf("expected the order to be final, got:\n%s", view)
+	}
+
+	// Ending the SSH session cancels the polls too
+	session, endSession := context.WithCancel(context.Background())
+	_, cmd = m.WithContext(session).restartTracking()
+	endSession()
+	if msg := cmd(); msg != nil {
+		t.Errorf("expected the poll to stop with the session, got %+v", msg)
+	}
+}
+
+func TestConfiguratorChoosesVariation(t *testing.T) {
+	products := []woo.Product{{
+		ID:          301,
+		Name:        "Eva Mug",
+		Type:        "variable",
+		StockStatus: "instock",
+		Attributes:  mugAttributes,
+	}}
+
+	model, server := setupTestModel(t, products, nil)
+	defer server.Close()
+
+	// Two large blue mugs left
+	two := 2
+	variations := append([]woo.Variation(nil), mugVariations...)
+	variations[2].StockQuantity = &two
+
+	m := model
+	m.width, m.height = 100, 40
+	m.selectedProduct = &products[0]
+	m.productVariations = variations
+	m.viewState = ViewProductDetails
+
+	// Run the form's commands, so it moves on to the next field. Timers,
+	// like the cursor blinking, are left out.
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		done := make(chan tea.Msg, 1)
+		go func() { done <- cmd() }()
+		var msg tea.Msg
+		select {
+		case msg = <-done:
+		case <-time.After(50 * time.Millisecond):
+			return
+		}
+		switch msg := msg.(type) {
+		case tea.BatchMsg:
+			for _, c := range msg {
+				run(c)
+			}
+		case nil:
+		default:
+			newModel, next := m.Update(msg)
+			m = newModel.(Model)
+			run(next)
+		}
+	}
+	press := func(msg tea.KeyMsg) {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		run(cmd)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if m.GetViewState() != ViewConfigurator {
+		t.Fatalf("expected Configurator view, got %v", m.GetViewState())
+	}
+	if view := m.View(); !strings.Contains(view, "Select Size") || !strings.Contains(view, "Small ($12.00)") {
+		t.Errorf("expected a size select with prices, got:\n%s", view)
+	}
+
+	// Large, then red: sold out, so it is refused
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.configCompleted {
+		t.Fatal("expected a sold out combination to be refused")
+	}
+	if view := m.View(); !strings.Contains(view, "out of stock") {
+		t.Errorf("expected the combination to show as out of stock, got:\n%s", view)
+	}
+
+	// Blue instead
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	if view := m.View(); !strings.Contains(view, "$18.00") || !strings.Contains(view, "2 in stock") {
+		t.Errorf("expected the blue mug's price and stock, got:\n%s", view)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// More than are in stock are refused
+	press(tea.KeyMsg{Type: tea.KeyBackspace})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.configCompleted || !strings.Contains(m.View(), "only 2 in stock") {
+		t.Fatalf("expected 3 mugs to be refused, got:\n%s", m.View())
+	}
+	press(tea.KeyMsg{Type: tea.KeyBackspace})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.configCompleted {
+		t.Fatal("expected the configuration to be complete")
+	}
+	if m.selectedVariation == nil || m.selectedVariation.ID != 13 {
+		t.Fatalf("expected the large blue mug, got %+v", m.selectedVariation)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if m.GetViewState() != ViewCart || m.localCart.Len() != 1 {
+		t.Fatalf("expected the mug in the cart, got view %v and %d items (err %v)", m.GetViewState(), m.localCart.Len(), m.err)
+	}
+	item := m.localCart.Items[0]
+	if item.VariationID != 13 || item.Name != "Eva Mug (Large, Blue)" || item.Price.String() != "18.00" || item.Quantity != 2 {
+		t.Errorf("unexpected cart item %+v", item)
+	}
+
+	// Both are in the cart already
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'+'}})
+	if m.localCart.Items[0].Quantity != 2 || !strings.Contains(m.View(), "Only 2 of Eva Mug (Large, Blue) in stock.") {
+		t.Errorf("expected the quantity to be capped at the stock, got:\n%s", m.View())
+	}
+	m.viewState = ViewConfigurator
+
+	// Options a variation takes any of are recorded with the item
+	m.selectedVariation = &m.productVariations[0]
+	m.configChoices.Options = []string{"Small", "Green"}
+	if err := m.addToCart(); err != nil {
+		t.Fatalf("addToCart failed: %v", err)
+	}
+	if item := m.localCart.Items[1]; item.VariationID != 11 || item.Meta["colour"] != "Green" || item.Name != "Eva Mug (Small, Green)" {
+		t.Errorf("expected the colour to be recorded for the small mug, got %+v", item)
+	}
+	m.configChoices.Options = []string{"Small", "Red"}
+	if err := m.addToCart(); err != nil {
+		t.Fatalf("addToCart failed: %v", err)
+	}
+	if m.localCart.Len() != 3 {
+		t.Errorf("expected a small mug in another colour to be a separate line, got %d lines", m.localCart.Len())
+	}
+}
+
+func TestReviewStockCheck(t *testing.T) {
+	one := 1
+	var posted int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/products":
+			// Someone else bought all but one bag
+			json.NewEncoder(w).Encode([]woo.Product{{ID: 1, Name: "Coffee", StockStatus: "instock", StockQuantity: &one}})
+		case "/wp-json/wc/v3/orders":
+			posted++
+			json.NewEncoder(w).Encode(woo.OrderResponse{ID: 60, Status: "pending"})
+		default:
+			json.NewEncoder(w).Encode([]woo.Product{})
+		}
+	}))
+	defer server.Close()
+
+	m := NewModel(woo.NewClient(server.URL),
+		cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+		cache.New[int, []woo.Variation](time.Minute),
+		woo.DefaultStoreSettings())
+	m.width = 100
+	m.localCart.AddItem(LocalCartItem{ProductID: 1, Name: "Coffee", Price: woo.NewMoney(1000, 2, "USD"), Quantity: 2, Stock: Stock{Status: "instock"}})
+	m.localCart.Shipping = &woo.ShippingRate{MethodID: "flat_rate", Title: "Courier", Cost: woo.NewMoney(500, 2, "USD")}
+	m.selectedGateway = &woo.PaymentGateway{ID: "bacs", Title: "Bank transfer"}
+	m.viewState = ViewReview
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	if !m.checkingStock || !strings.Contains(m.View(), "Checking stock") {
+		t.Fatalf("expected the stock to be checked, got:\n%s", m.View())
+	}
+	newModel, cmd = m.Update(cmd())
+	m = newModel.(Model)
+
+	if cmd != nil || posted != 0 {
+		t.Fatal("expected no order to be placed")
+	}
+	if view := m.View(); !strings.Contains(view, "✗ Only 1 left") || !strings.Contains(view, "c update cart") {
+		t.Errorf("expected the line to be flagged, got:\n%s", view)
+	}
+
+	// The cart caps the quantity at the stock seen
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	m = newModel.(Model)
+	if m.GetViewState() != ViewCart || !strings.Contains(m.View(), "✗ Only 1 in stock") {
+		t.Fatalf("expected the cart with the line flagged, got:\n%s", m.View())
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'-'}})
+	m = newModel.(Model)
+	if strings.Contains(m.View(), "✗") {
+		t.Errorf("expected the flag to go once the quantity fits, got:\n%s", m.View())
+	}
+}
+
+func TestReviewPriceChange(t *testing.T) {
+	var posted int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/products":
+			// The price went up since the cart was saved
+			json.NewEncoder(w).Encode([]woo.Product{{ID: 1, Name: "Coffee", Price: "12.00", StockStatus: "instock"}})
+		case "/wp-json/wc/v3/orders":
+			posted++
+			json.NewEncoder(w).Encode(woo.OrderResponse{ID: 61, Status: "pending"})
+		default:
+			json.NewEncoder(w).Encode([]woo.Product{})
+		}
+	}))
+	defer server.Close()
+
+	m := NewModel(woo.NewClient(server.URL),
+		cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+		cache.New[int, []woo.Variation](time.Minute),
+		woo.DefaultStoreSettings())
+	m.width = 100
+	m.localCart.AddItem(LocalCartItem{ProductID: 1, Name: "Coffee", Price: woo.NewMoney(1000, 2, "USD"), Quantity: 2, Stock: Stock{Status: "instock"}})
+	m.localCart.Shipping = &woo.ShippingRate{MethodID: "flat_rate", Title: "Courier", Cost: woo.NewMoney(500, 2, "USD")}
+	m.selectedGateway = &woo.PaymentGateway{ID: "bacs", Title: "Bank transfer"}
+	m.viewState = ViewReview
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	newModel, cmd = m.Update(cmd())
+	m = newModel.(Model)
+
+	if cmd != nil || posted != 0 {
+		t.Fatal("expected no order to be placed at the new price unseen")
+	}
+	if view := m.View(); !strings.Contains(view, "~ Now $12.00 each (was $10.00)") || !strings.Contains(view, "c check cart") {
+		t.Errorf("expected the new price to be flagged, got:\n%s", view)
+	}
+
+	// The cart shows the new price, and shipping is chosen again
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	m = newModel.(Model)
+	if m.GetViewState() != ViewCart || m.localCart.Shipping != nil {
+		t.Fatalf("expected the cart without a shipping method, got view %v", m.GetViewState())
+	}
+	if got := m.localCart.Subtotal(); got != woo.NewMoney(2400, 2, "USD") {
+		t.Errorf("expected the subtotal at the new price, got %s", got)
+	}
+}
+
+func TestOrderAgain(t *testing.T) {
+	three := 3
+	products := []woo.Product{{ID: 101, Name: "House Blend", Type: "variable", Status: "publish"}}
+	variations := map[int][]woo.Variation{
+		101: {{ID: 1012, Status: "publish", Price: "52.00", StockStatus: "instock", StockQuantity: &three, Attributes: []woo.VariationAttribute{{Name: "Size", Option: "1kg"}}}},
+	}
+	m, server := setupTestModel(t, products, variations)
+	defer server.Close()
+	m.width = 100
+
+	// The cache still holds the price seen earlier in the session
+	m.variationsCache.Set(101, []woo.Variation{{ID: 1012, Status: "publish", Price: "49.99", StockStatus: "instock"}})
+	order := woo.OrderResponse{ID: 40, Currency: "USD", LineItems: []woo.OrderLineItem{
+		{ProductID: 101, VariationID: 1012, Name: "House Blend - 1kg", Quantity: 2, Subtotal: "99.98"},
+	}}
+	m.selectedOrder = &order
+	m.viewState = ViewOrderDetail
+
+	next, _ := m.Update(m.checkReorder(order)())
+	m = next.(Model)
+	if len(m.reorderLines) != 1 || m.reorderLines[0].Status != ReorderPriceChanged || m.reorderLines[0].Item.Price.String() != "52.00" {
+		t.Fatalf("expected the current price, got %+v", m.reorderLines)
+	}
+	if cached, _ := m.variationsCache.Get(101); len(cached) != 1 || cached[0].Price != "52.00" {
+		t.Errorf("expected the cache to be refreshed, got %+v", cached)
+	}
+
+	// With two bags in the cart already the order doesn't fit in the
+	// stock, so nothing is added
+	bags := *m.reorderLines[0].Item
+	m.localCart.AddItem(bags)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.GetViewState() != ViewReorder || !strings.Contains(m.View(), "in stock") {
+		t.Fatalf("expected the stock to be refused, got:\n%s", m.View())
+	}
+	if m.localCart.Len() != 1 || m.localCart.Items[0].Quantity != 2 {
+		t.Errorf("expected the cart to be left as it was, got %+v", m.localCart.Items)
+	}
+
+	m.localCart.Clear()
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.GetViewState() != ViewCart || m.localCart.ItemCount() != 2 {
+		t.Errorf("expected the order in the cart, got %+v", m.localCart.Items)
+	}
+}
+
+func TestProductImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 0xc0, G: 0xc0, B: 0xc0, A: 0xff}), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	images := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/mug.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(buf.Bytes())
+	}))
+	defer images.Close()
+
+	products := []woo.Product{
+		{ID: 1, Name: "Eva Mug", Type: "simple", Price: "12.00", StockStatus: "instock",
+			Images: []woo.Image{{ID: 5, Src: images.URL + "/mug.png", Alt: "A white mug"}}},
+		{ID: 2, Name: "House Blend", Type: "simple", Price: "10.00", StockStatus: "instock",
+			Images: []woo.Image{{ID: 6, Src: images.URL + "/missing.png"}}},
+	}
+	model, server := setupTestModel(t, products, nil)
+	defer server.Close()
+	loader := termimg.NewLoader(1<<20, 1<<20, 16, time.Minute)
+
+	show := func(term string, product int) Model {
+		t.Helper()
+		m := model.WithImages(loader, term)
+		updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 60})
+		m = updated.(Model)
+		m.selectedProduct = &products[product]
+		m.viewState = ViewProductDetails
+		if cmd := m.loadProductImage(); cmd != nil {
+			updated, _ = m.Update(cmd())
+			m = updated.(Model)
+		}
+		return m
+	}
+
+	m := show("xterm-256color", 0)
+	if m.loadingImage || m.imageErr != nil {
+		t.Fatalf("expected image loaded, got loading=%v err=%v", m.loadingImage, m.imageErr)
+	}
+	if view := m.View(); !strings.Contains(view, "\x1b[38;2;192;192;192m\x1b[48;2;192;192;192m▀") {
+		t.Error("expected half-block image in details view")
+	}
+
+	m = show("xterm-kitty", 0)
+	view := m.View()
+	if !strings.Contains(view, "\x1b_Ga=T") || strings.Contains(view, imageMarker) {
+		t.Error("expected Kitty graphics placed in details view")
+	}
+	lines := strings.Split(view, "\n")
+	if !strings.Contains(lines[len(lines)-1], "\x1b7") {
+		t.Error("expected graphics sent from the last line")
+	}
+	m.viewState = ViewProductList
+	if view := m.View(); strings.Contains(view, "\x1b_Ga=T") || !strings.Contains(view, termimg.Clear(termimg.ProtocolKitty)) {
+		t.Error("expected Kitty image cleared when leaving details view")
+	}
+
+	m = show("dumb", 0)
+	if m.loadingImage || m.productImage != "" {
+		t.Error("expected no image loaded for dumb terminal")
+	}
+	if view := m.View(); !strings.Contains(view, "[Image: A white mug]") {
+		t.Error("expected image description on dumb terminal")
+	}
+
+	// A broken image falls back to the description
+	m = show("xterm-256color", 1)
+	if m.imageErr == nil {
+		t.Fatal("expected error loading missing image")
+	}
+	if view := m.View(); !strings.Contains(view, "[Image: House Blend]") {
+		t.Error("expected product name in place of broken image")
+	}
+
+	// An image arriving after another product was picked is ignored
+	updated, _ := m.Update(imageLoadedMsg{url: products[0].Images[0].Src, rendered: "stale"})
+	if updated.(Model).productImage == "stale" {
+		t.Error("expected image of another product ignored")
+	}
+}
+
+func TestProductReviews(t *testing.T) {
+	var posted woo.ProductReviewRequest
+	var listQuery url.Values
+	var failList bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/wp-json/wc/v3/customers/7":
+			json.NewEncoder(w).Encode(woo.Customer{ID: 7, Email: "ada@example.com", FirstName: "Ada", LastName: "Lovelace"})
+		case r.URL.Path == "/wp-json/wc/v3/products/reviews" && r.Method == http.MethodPost:
+			json.NewDecoder(r.Body).Decode(&posted)
+			json.NewEncoder(w).Encode(woo.ProductReview{ID: 30, ProductID: posted.ProductID, Status: "approved", Rating: posted.Rating})
+		case r.URL.Path == "/wp-json/wc/v3/products/reviews":
+			listQuery = r.URL.Query()
+			if failList {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":"rest_invalid_param","message":"Invalid page.","data":{"status":400}}`))
+				return
+			}
+			w.Header().Set("X-WP-Total", "11")
+			w.Header().Set("X-WP-TotalPages", "2")
+			if listQuery.Get("page") == "2" {
+				json.NewEncoder(w).Encode([]woo.ProductReview{{ID: 20, Reviewer: "Grace", Rating: 3, Review: "<p>Fine, a little flat.</p>"}})
+				return
+			}
+			var reviews []woo.ProductReview
+			for i := 1; i <= 10; i++ {
+				reviews = append(reviews, woo.ProductReview{ID: i, Reviewer: fmt.Sprintf("Reviewer %d", i), Rating: 4, Review: fmt.Sprintf("<p>Review number %d.</p>", i)})
+			}
+			json.NewEncoder(w).Encode(reviews)
+		default:
+			json.NewEncoder(w).Encode([]woo.Product{})
+		}
+	}))
+	defer server.Close()
+
+	products := []woo.Product{{ID: 1, Name: "House Blend", Type: "simple", Price: "10.00", StockStatus: "instock",
+		ReviewsAllowed: true, AverageRating: "4.00", RatingCount: 3}}
+
+	newModel := func(links testCustomerLinks, fingerprint string) Model {
+		m := NewModel(woo.NewClient(server.URL),
+			cache.New[ProductListCacheKey, woo.ProductPage](time.Minute),
+			cache.New[int, []woo.Variation](time.Minute),
+			woo.DefaultStoreSettings())
+		if links != nil {
+			m = m.WithCustomerLinks(links, fingerprint)
+			next, _ := m.Update(m.loadCustomer()())
+			m = next.(Model)
+		}
+		next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 60})
+		m = next.(Model)
+		m.products = products
+		m.updateProductList()
+		return m
+	}
+
+	// Run commands like TestConfiguratorChoosesVariation, leaving out timers
+	var m Model
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		done := make(chan tea.Msg, 1)
+		go func() { done <- cmd() }()
+		var msg tea.Msg
+		select {
+		case msg = <-done:
+		case <-time.After(50 * time.Millisecond):
+			return
+		}
+		switch msg := msg.(type) {
+		case tea.BatchMsg:
+			for _, c := range msg {
+				run(c)
+			}
+		case nil:
+		default:
+			newModel, next := m.Update(msg)
+			m = newModel.(Model)
+			run(next)
+		}
+	}
+	press := func(msg tea.KeyMsg) {
+		newModel, cmd := m.Update(msg)
+		m = newModel.(Model)
+		run(cmd)
+	}
+	key := func(r rune) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}} }
+
+	t.Run("signed in", func(t *testing.T) {
+		m = newModel(testCustomerLinks{"SHA256:ada": 7}, "SHA256:ada")
+		press(tea.KeyMsg{Type: tea.KeyEnter})
+		if m.GetViewState() != ViewProductDetails {
+			t.Fatalf("expected ProductDetails view, got %v", m.GetViewState())
+		}
+		if listQuery.Get("product") != "1" || listQuery.Get("per_page") != "10" {
+			t.Errorf("unexpected reviews query %v", listQuery)
+		}
+
+		view := m.View()
+		for _, want := range []string{"★★★★☆ 4.0 (3 reviews)", "page 1 of 2", "Review number 1.", "lines 1-8 of", "w write a review"} {
+			if !strings.Contains(view, want) {
+				t.Errorf("expected %q in details view, got:\n%s", want, view)
+			}
+		}
+		if strings.Contains(view, "Review number 4.") {
+			t.Error("expected the review list cut to its window")
+		}
+
+		// Scrolling and paging
+		for i := 0; i < 4; i++ {
+			press(tea.KeyMsg{Type: tea.KeyDown})
+		}
+		if view := m.View(); strings.Contains(view, "Review number 1.") || !strings.Contains(view, "Review number 4.") {
+			t.Errorf("expected the review list scrolled, got:\n%s", view)
+		}
+		// A page that fails to load leaves the current one
+		failList = true
+		press(key('n'))
+		if m.reviewsErr == nil || m.reviewsPage != 1 || !strings.Contains(m.View(), "page 1 of 2") {
+			t.Errorf("expected an error on page 1, got page %d:\n%s", m.reviewsPage, m.View())
+		}
+		failList = false
+		press(key('n'))
+		if m.reviewsPage != 2 || m.reviewsScroll != 0 || !strings.Contains(m.View(), "a little flat") {
+			t.Errorf("expected the second page of reviews, got page %d:\n%s", m.reviewsPage, m.View())
+		}
+
+		// Writing a review: 4 stars and a few words
+		press(key('w'))
+		if m.GetViewState() != ViewReviewForm {
+			t.Fatalf("expected ReviewForm view, got %v", m.GetViewState())
+		}
+		if view := m.View(); !strings.Contains(view, "Posted as Ada Lovelace") {
+			t.Errorf("expected the reviewer name on the form, got:\n%s", view)
+		}
+		press(tea.KeyMsg{Type: tea.KeyDown})
+		press(tea.KeyMsg{Type: tea.KeyEnter})
+		for _, r := range "Great crema" {
+			press(key(r))
+		}
+		press(tea.KeyMsg{Type: tea.KeyEnter})
+
+		if posted.ProductID != 1 || posted.Rating != 4 || posted.Review != "Great crema" ||
+			posted.Reviewer != "Ada Lovelace" || posted.ReviewerEmail != "ada@example.com" {
+			t.Errorf("unexpected review submitted %+v", posted)
+		}
+		if m.GetViewState() != ViewProductDetails || m.reviewsPage != 1 {
+			t.Fatalf("expected the first page of reviews again, got view %v page %d", m.GetViewState(), m.reviewsPage)
+		}
+		view = m.View()
+		if !strings.Contains(view, "Thanks for your review!") || !strings.Contains(view, "4.0 (4 reviews)") {
+			t.Errorf("expected thanks and the new rating counted, got:\n%s", view)
+		}
+	})
+
+	t.Run("guest", func(t *testing.T) {
+		m = newModel(nil, "")
+		press(tea.KeyMsg{Type: tea.KeyEnter})
+		view := m.View()
+		if strings.Contains(view, "w write a review") || !strings.Contains(view, "Sign in to write a review") {
+			t.Errorf("expected guests asked to sign in, got:\n%s", view)
+		}
+		press(key('w'))
+		if m.GetViewState() != ViewProductDetails {
+			t.Errorf("expected guests kept out of the review form, got %v", m.GetViewState())
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// ReorderStatus says how a line of a past order compares with the shop today.
+type ReorderStatus int
+
+const (
+	ReorderUnchanged         ReorderStatus = iota
+	ReorderPriceChanged                    // Same item at a different price
+	ReorderLowStock                        // Fewer units in stock than ordered; the quantity is reduced
+	ReorderOutOfStock                      // Still sold, but not in stock
+	ReorderGrindUnavailable                // The grind size is no longer offered
+	ReorderOptionUnavailable               // An option picked for the variation is no longer offered
+	ReorderDiscontinued                    // Product or variation no longer sold
+)
+
+// ReorderLine is a line of a past order checked against current stock and prices.
+type ReorderLine struct {
+	Name      string         // As it appears on the past order
+	GrindSize string         // From the pa_grind-size metadata
+	Attribute string         // Attribute whose option is no longer offered
+	Quantity  int            // Units ordered before
+	PaidPrice *woo.Money     // Unit price paid, nil if unknown
+	Status    ReorderStatus  // Worst difference found
+	Repriced  bool           // The price differs from the one paid, whatever the Status
+	Item      *LocalCartItem // What goes in the cart, nil if it can't be reordered
+}
+
+// CanReorder reports whether the line can go in the cart.
+func (l ReorderLine) CanReorder() bool {
+	return l.Item != nil
+}
+
+// BuildReorder rebuilds the cart items of a past order from the products
+// and variations the shop sells today. Products missing from products
+// are treated as discontinued, as are variable products whose variations
+// are missing from variations, and unpublished products and variations.
+func BuildReorder(order *woo.OrderResponse, products map[int]woo.Product, variations map[int][]woo.Variation, settings woo.StoreSettings) []ReorderLine {
+	lines := make([]ReorderLine, 0, len(order.LineItems))
+
+	for _, orderItem := range order.LineItems {
+		line := ReorderLine{
+			Name:      StripHTML(orderItem.Name),
+			Quantity:  orderItem.Quantity,
+			GrindSize: lineItemMeta(orderItem, "pa_grind-size"),
+			Status:    ReorderDiscontinued,
+		}
+		if paid, err := orderItem.UnitPrice(order.Currency, order.PricesIncludeTax); err == nil {
+			line.PaidPrice = &paid
+		}
+
+		product, ok := products[orderItem.ProductID]
+		if !ok || (product.Status != "" && product.Status != "publish") {
+			lines = append(lines, line)
+			continue
+		}
+
+		var variation *woo.Variation
+		if orderItem.VariationID != 0 {
+			for i := range variations[product.ID] {
+				if variations[product.ID][i].ID == orderItem.VariationID {
+					variation = &variations[product.ID][i]
+					break
+				}
+			}
+			if variation == nil || !variationPublished(variation) {
+				lines = append(lines, line)
+				continue
+			}
+		}
+
+		// Keep the grind size if it is still offered, in its current spelling
+		if line.GrindSize != "" {
+			grind, ok := currentGrindSize(&product, line.GrindSize)
+			if !ok {
+				line.Status = ReorderGrindUnavailable
+				lines = append(lines, line)
+				continue
+			}
+			line.GrindSize = grind
+		}
+
+		// Options picked for attributes the variation takes any of
+		var options []string
+		var meta map[string]string
+		if variation != nil {
+			options, meta, line.Attribute = anyOptions(&product, variation, orderItem)
+			if line.Attribute != "" {
+				line.Status = ReorderOptionUnavailable
+				lines = append(lines, line)
+				continue
+			}
+		}
+
+		item, err := NewLocalCartItemFromProduct(&product, variation, orderItem.Quantity, line.GrindSize, settings.Currency)
+		if err != nil {
+			// No valid price: it can't be bought any more
+			lines = append(lines, line)
+			continue
+		}
+		if len(meta) > 0 {
+			item.Meta = meta
+			item.Name = variationName(&product, options)
+		}
+
+		// Stock and price are checked apart, so a line can show both
+		line.Repriced = !samePrice(line.PaidPrice, item.Price, settings.Decimals)
+		limit, limited := item.Stock.Limit()
+		switch {
+		case limited && limit == 0:
+			line.Status = ReorderOutOfStock
+			lines = append(lines, line)
+			continue
+		case limited && limit < item.Quantity:
+			line.Status = ReorderLowStock
+			item.Quantity = limit
+		case line.Repriced:
+			line.Status = ReorderPriceChanged
+		default:
+			line.Status = ReorderUnchanged
+		}
+
+		line.Item = &item
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// samePrice reports whether the price paid is the price today at the store
+// precision. A price paid in another currency never matches.
+func samePrice(paid *woo.Money, now woo.Money, decimals int) bool {
+	if paid == nil {
+		return false
+	}
+	c, err := paid.Round(decimals).Cmp(now.Round(decimals))
+	return err == nil && c == 0
+}
+
+// lineItemMeta returns the value of a line item's metadata key, or "".
+func lineItemMeta(item woo.OrderLineItem, key string) string {
+	for _, meta := range item.MetaData {
+		if meta.Key == key {
+			return meta.String()
+		}
+	}
+	return ""
+}
+
+// anyOptions reads back from a past order the options picked for the
+// attributes a variation takes any of, in their current spelling. It
+// returns the options of all the variation's attributes in display order,
+// and those picked keyed like addToCart keys them. Orders placed before
+// options were keyed by attribute slug have them under the name. If an
+// option is missing or no longer offered, its attribute is returned.
+func anyOptions(product *woo.Product, variation *woo.Variation, orderItem woo.OrderLineItem) ([]string, map[string]string, string) {
+	attrs := variationAttributes(product)
+	options := make([]string, len(attrs))
+	meta := make(map[string]string)
+	for i := range attrs {
+		attr := &attrs[i]
+		if options[i] = variationOption(variation, *attr); options[i] != "" {
+			continue
+		}
+		picked := lineItemMeta(orderItem, attr.Key())
+		if picked == "" {
+			picked = lineItemMeta(orderItem, attr.Name)
+		}
+		option, ok := currentOption(attr, picked)
+		if !ok {
+			return nil, nil, attr.Name
+		}
+		options[i] = option
+		meta[attr.Key()] = option
+	}
+	return options, meta, ""
+}
+
+// currentGrindSize finds a grind size among the product's current options.
+func currentGrindSize(product *woo.Product, grind string) (string, bool) {
+	attr := product.GetAttribute("Grind Size")
+	if attr == nil {
+		return "", false
+	}
+	return currentOption(attr, grind)
+}
+
+// currentOption finds an option among the attribute's current options.
+// Orders may hold the option's slug ("whole-beans") or its name ("Whole Beans").
+func currentOption(attr *woo.Attribute, value string) (string, bool) {
+	if value == "" {
+		return "", false
+	}
+	for _, option := range attr.Options {
+		slug := strings.ReplaceAll(strings.ToLower(option), " ", "-")
+		if strings.EqualFold(option, value) || slug == strings.ToLower(value) {
+			return option, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+func TestBuildReorder(t *testing.T) {
+	grind := []woo.Attribute{{Name: "Grind Size", Options: []string{"Whole Beans", "Espresso"}}}
+	fifty, three, two, one, zero := 50, 3, 2, 1, 0
+	products := map[int]woo.Product{
+		1:   {ID: 1, Name: "Ethiopia", Type: "simple", Status: "publish", Price: "18.99", RegularPrice: "18.99", StockStatus: "instock", StockQuantity: &fifty, Attributes: grind},
+		2:   {ID: 2, Name: "Colombia", Type: "simple", Status: "publish", Price: "16.99", RegularPrice: "16.99", StockStatus: "instock", StockQuantity: &three},
+		3:   {ID: 3, Name: "Decaf", Type: "simple", Status: "publish", Price: "16.99", RegularPrice: "16.99", StockStatus: "outofstock", StockQuantity: &zero},
+		4:   {ID: 4, Name: "Sumatra", Type: "simple", Status: "draft", Price: "19.99", RegularPrice: "19.99"},
+		101: {ID: 101, Name: "House Blend", Type: "variable", Status: "publish", Attributes: grind},
+		6:   {ID: 6, Name: "Brazil", Type: "simple", Status: "publish", Price: "15.00", StockStatus: "instock", StockQuantity: &two, BackordersAllowed: true},
+		7:   {ID: 7, Name: "Peru", Type: "simple", Status: "publish", Price: "17.00", StockStatus: "instock", StockQuantity: &one},
+		301: {ID: 301, Name: "Eva Mug", Type: "variable", Status: "publish", Attributes: mugAttributes},
+	}
+	variations := map[int][]woo.Variation{
+		101: {
+			{ID: 1012, Status: "publish", Price: "49.99", RegularPrice: "49.99", StockStatus: "instock", Attributes: []woo.VariationAttribute{{Name: "Size", Option: "1kg"}}},
+			{ID: 1013, Status: "private", Price: "89.99", RegularPrice: "89.99", StockStatus: "instock", Attributes: []woo.VariationAttribute{{Name: "Size", Option: "2kg"}}},
+		},
+		301: mugVariations, // The small mug comes in any colour
+	}
+	espresso := []woo.OrderLineItemMetaData{woo.StringLineItemMetaData("pa_grind-size", "espresso")}
+	order := &woo.OrderResponse{
+		Currency:         "USD",
+		PricesIncludeTax: true,
+		LineItems: []woo.OrderLineItem{
+			{ProductID: 1, Name: "Ethiopia", Quantity: 2, Subtotal: "31.13", SubtotalTax: "6.85", MetaData: espresso},
+			{ProductID: 101, VariationID: 1012, Name: "House Blend - 1kg", Quantity: 1, Subtotal: "36.89", SubtotalTax: "8.11", MetaData: []woo.OrderLineItemMetaData{woo.StringLineItemMetaData("pa_grind-size", "whole-beans")}},
+			{ProductID: 2, Name: "Colombia", Quantity: 5, Subtotal: "69.63", SubtotalTax: "15.32"},
+			{ProductID: 3, Name: "Decaf", Quantity: 1, Subtotal: "13.93", SubtotalTax: "3.06"},
+			{ProductID: 4, Name: "Sumatra", Quantity: 1, Subtotal: "16.39", SubtotalTax: "3.60"},
+			{ProductID: 5, Name: "Kenya", Quantity: 1, Subtotal: "15.00"},
+			{ProductID: 101, VariationID: 1011, Name: "House Blend - 250g", Quantity: 1, Subtotal: "12.29"},
+			{ProductID: 101, VariationID: 1013, Name: "House Blend - 2kg", Quantity: 1, Subtotal: "73.76"},
+			{ProductID: 1, Name: "Ethiopia", Quantity: 1, Subtotal: "15.57", MetaData: []woo.OrderLineItemMetaData{woo.StringLineItemMetaData("pa_grind-size", "turkish")}},
+			{ProductID: 301, VariationID: 11, Name: "Eva Mug - Small", Quantity: 1, Subtotal: "12.00", SubtotalTax: "0.00", MetaData: []woo.OrderLineItemMetaData{woo.StringLineItemMetaData("colour", "green")}},
+			{ProductID: 301, VariationID: 11, Name: "Eva Mug - Small", Quantity: 1, Subtotal: "12.00", SubtotalTax: "0.00", MetaData: []woo.OrderLineItemMetaData{woo.StringLineItemMetaData("Colour", "Purple")}},
+			{ProductID: 6, Name: "Brazil", Quantity: 4, Subtotal: "60.00", SubtotalTax: "0.00"},
+			{ProductID: 7, Name: "Peru", Quantity: 2, Subtotal: "30.00", SubtotalTax: "0.00"},
+		},
+	}
+
+	lines := BuildReorder(order, products, variations, woo.DefaultStoreSettings())
+
+	want := []ReorderStatus{
+		ReorderUnchanged,
+		ReorderPriceChanged, // 45.00 then, 49.99 now
+		ReorderLowStock,
+		ReorderOutOfStock,
+		ReorderDiscontinued, // Unpublished
+		ReorderDiscontinued, // Deleted
+		ReorderDiscontinued, // Variation deleted
+		ReorderDiscontinued, // Variation disabled
+		ReorderGrindUnavailable,
+		ReorderUnchanged,
+		ReorderOptionUnavailable, // Purple mugs are no longer made
+		ReorderUnchanged,         // Short of stock, but backorders are allowed
+		ReorderLowStock,          // And 15.00 then, 17.00 now
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %d", len(want), len(lines))
+	}
+	for i, status := range want {
+		if lines[i].Status != status {
+			t.Errorf("line %d (%s): expected status %d, got %d", i, lines[i].Name, status, lines[i].Status)
+		}
+		if canReorder := status <= ReorderLowStock; lines[i].CanReorder() != canReorder {
+			t.Errorf("line %d (%s): expected CanReorder %v", i, lines[i].Name, canReorder)
+		}
+	}
+
+	// The cart items keep the variation and the grind size in its current spelling
+	if item := lines[0].Item; item.Quantity != 2 || item.GrindSize != "Espresso" || item.Price.String() != "18.99" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if item := lines[1].Item; item.VariationID != 1012 || item.GrindSize != "Whole Beans" || lines[1].PaidPrice.String() != "45.00" {
+		t.Errorf("unexpected variation item %+v, paid %s", item, lines[1].PaidPrice)
+	}
+	if item := lines[9].Item; item.Meta["colour"] != "Green" || item.Name != "Eva Mug (Small, Green)" {
+		t.Errorf("expected the colour picked to be kept, got %+v", item)
+	}
+	if lines[10].Attribute != "Colour" {
+		t.Errorf("expected the colour to be missing, got %q", lines[10].Attribute)
+	}
+	if lines[11].Item.Quantity != 4 {
+		t.Errorf("expected the backordered quantity to be kept, got %d", lines[11].Item.Quantity)
+	}
+	if line := lines[12]; line.Item.Quantity != 1 || !line.Repriced || !lines[1].Repriced || lines[2].Repriced {
+		t.Errorf("expected the price change to be kept with the low stock, got %+v", line)
+	}
+	if lines[2].Item.Quantity != 3 {
+		t.Errorf("expected the quantity to be reduced to the 3 in stock, got %d", lines[2].Item.Quantity)
+	}
+}
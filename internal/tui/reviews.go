@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// Reviews are fetched a page at a time and shown in the product details
+// in a window of reviewListRows lines, scrolled a line at a time.
+const (
+	reviewsPerPage = 10
+	reviewListRows = 8
+)
+
+// reviewDraft holds the review being written. The form writes straight
+// into it, so it is kept behind a pointer like configChoices.
+type reviewDraft struct {
+	Rating int
+	Review string
+}
+
+// ratingStars draws a rating out of 5, rounded to the nearest star.
+func ratingStars(rating float64) string {
+	full := max(0, min(int(math.Round(rating)), 5))
+	return strings.Repeat("★", full) + strings.Repeat("☆", 5-full)
+}
+
+// ratingSummary describes a product's ratings, e.g. "4.5 (12 reviews)".
+func ratingSummary(p *woo.Product) string {
+	switch p.RatingCount {
+	case 0:
+		return "No reviews yet"
+	case 1:
+		return fmt.Sprintf("%.1f (1 review)", p.Rating())
+	}
+	return fmt.Sprintf("%.1f (%d reviews)", p.Rating(), p.RatingCount)
+}
+
+// showsReviews reports whether the product details have a reviews section.
+// Shops can turn reviews off per product; reviews written before that are
+// still shown.
+func showsReviews(p *woo.Product) bool {
+	return p.ReviewsAllowed || p.RatingCount > 0
+}
+
+// reviewerName is the name a customer's reviews are signed with.
+func reviewerName(c *woo.Customer) string {
+	if name := strings.TrimSpace(c.FirstName + " " + c.LastName); name != "" {
+		return name
+	}
+	if c.Username != "" {
+		return c.Username
+	}
+	name, _, _ := strings.Cut(c.Email, "@")
+	return name
+}
+
+// reviewLines lays out reviews for the review list, one line per entry so
+// the list can be scrolled a line at a time.
+func reviewLines(reviews []woo.ProductReview, width int, styles Styles) []string {
+	var sb strings.Builder
+	for i, r := range reviews {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		if r.Rating > 0 {
+			sb.WriteString(styles.ProductRating.Render(ratingStars(float64(r.Rating))))
+			sb.WriteString(" ")
+		}
+		sb.WriteString(styles.Highlight.Render(StripHTML(r.Reviewer)))
+		if date := formatOrderDate(r.DateCreated); date != "" {
+			sb.WriteString(styles.Subtle.Render("  " + date))
+		}
+		if r.Verified {
+			sb.WriteString(styles.Success.Render("  ✓ Verified buyer"))
+		}
+		if text := RenderHTML(r.Review, width-2, styles); text != "" {
+			sb.WriteString("\n  ")
+			sb.WriteString(strings.ReplaceAll(text, "\n", "\n  "))
+		}
+	}
+	if sb.Len() == 0 {
+		return nil
+	}
+	return strings.Split(sb.String(), "\n")
+}
+
+// addRating counts a newly published rating in the product's average, so
+// the details match the review list without fetching the product again.
+func addRating(p *woo.Product, rating int) {
+	if rating <= 0 {
+		return
+	}
+	total := p.Rating()*float64(p.RatingCount) + float64(rating)
+	p.RatingCount++
+	p.AverageRating = fmt.Sprintf("%.2f", total/float64(p.RatingCount))
+}
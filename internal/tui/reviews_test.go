@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+func TestRatingStars(t *testing.T) {
+	tests := []struct {
+		rating float64
+		want   string
+	}{
+		{0, "☆☆☆☆☆"},
+		{1, "★☆☆☆☆"},
+		{3.4, "★★★☆☆"},
+		{4.5, "★★★★★"},
+		{5, "★★★★★"},
+		{7, "★★★★★"},
+	}
+	for _, tt := range tests {
+		if got := ratingStars(tt.rating); got != tt.want {
+			t.Errorf("ratingStars(%v) = %q, want %q", tt.rating, got, tt.want)
+		}
+	}
+}
+
+func TestRatingSummary(t *testing.T) {
+	p := woo.Product{ReviewsAllowed: true, AverageRating: "0"}
+	if got := ratingSummary(&p); got != "No reviews yet" {
+		t.Errorf("unexpected summary %q", got)
+	}
+
+	// A first published rating is counted in the average
+	addRating(&p, 4)
+	if got := ratingSummary(&p); got != "4.0 (1 review)" {
+		t.Errorf("unexpected summary %q", got)
+	}
+	addRating(&p, 5)
+	if got := ratingSummary(&p); got != "4.5 (2 reviews)" {
+		t.Errorf("unexpected summary %q", got)
+	}
+
+	p.ReviewsAllowed = false
+	if !showsReviews(&p) {
+		t.Error("expected reviews written before they were turned off to be shown")
+	}
+	if showsReviews(&woo.Product{}) {
+		t.Error("expected no reviews section for a product without reviews")
+	}
+}
+
+func TestReviewerName(t *testing.T) {
+	tests := []struct {
+		customer woo.Customer
+		want     string
+	}{
+		{woo.Customer{FirstName: "Ada", LastName: "Lovelace", Username: "ada", Email: "ada@example.com"}, "Ada Lovelace"},
+		{woo.Customer{FirstName: "Ada", Email: "ada@example.com"}, "Ada"},
+		{woo.Customer{Username: "countess", Email: "ada@example.com"}, "countess"},
+		{woo.Customer{Email: "ada@example.com"}, "ada"},
+	}
+	for _, tt := range tests {
+		if got := reviewerName(&tt.customer); got != tt.want {
+			t.Errorf("reviewerName(%+v) = %q, want %q", tt.customer, got, tt.want)
+		}
+	}
+}
+
+func TestReviewLines(t *testing.T) {
+	if lines := reviewLines(nil, 40, DefaultStyles()); lines != nil {
+		t.Errorf("expected no lines without reviews, got %q", lines)
+	}
+
+	reviews := []woo.ProductReview{
+		{Reviewer: "Ada", DateCreated: "2026-03-01T10:00:00", Rating: 5, Verified: true,
+			Review: "<p>Bright and fruity, lovely as a pour-over on a slow Sunday morning.</p>"},
+		{Reviewer: "Grace", Review: "<p>Too acidic for me.</p>"},
+	}
+	lines := reviewLines(reviews, 40, DefaultStyles())
+	want := []string{
+		"★★★★★ Ada  1 Mar 2026  ✓ Verified buyer",
+		"  Bright and fruity, lovely as a",
+		"  pour-over on a slow Sunday morning.",
+		"",
+		"Grace",
+		"  Too acidic for me.",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("reviewLines =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
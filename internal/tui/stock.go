@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// Stock is what the shop has of a product or variation, as last seen.
+type Stock struct {
+	Status     string // "instock", "outofstock" or "onbackorder"
+	Quantity   *int   // Units in stock, nil if the shop doesn't track them
+	Backorders bool   // More units than Quantity can be ordered
+}
+
+// productStock returns the stock of a product, or of its variation if not nil.
+func productStock(product *woo.Product, variation *woo.Variation) Stock {
+	if variation != nil {
+		return Stock{Status: variation.StockStatus, Quantity: variation.StockQuantity, Backorders: variation.BackordersAllowed}
+	}
+	return Stock{Status: product.StockStatus, Quantity: product.StockQuantity, Backorders: product.BackordersAllowed}
+}
+
+// Limit returns how many units can be ordered, and false if there is no limit.
+func (s Stock) Limit() (int, bool) {
+	switch {
+	case s.Status == "outofstock":
+		return 0, true
+	case s.Backorders || s.Status == "onbackorder" || s.Quantity == nil:
+		return 0, false
+	}
+	return max(*s.Quantity, 0), true
+}
+
+// Backordered returns how many of quantity units won't ship until the shop
+// restocks.
+func (s Stock) Backordered(quantity int) int {
+	if s.Status != "onbackorder" && !s.Backorders {
+		return 0
+	}
+	if s.Quantity == nil {
+		if s.Status == "onbackorder" {
+			return quantity
+		}
+		return 0
+	}
+	return max(quantity-max(*s.Quantity, 0), 0)
+}
+
+// StockLimitError is returned when more units are wanted than the shop has.
+type StockLimitError struct {
+	Name      string
+	Available int // Units that can be ordered
+}
+
+// Error implements the error interface.
+func (e *StockLimitError) Error() string {
+	return fmt.Sprintf("only %d of %s in stock", e.Available, e.Name)
+}
+
+// StockIssue is a cart line the shop can no longer fulfil.
+type StockIssue struct {
+	Index     int // Line in the cart
+	Name      string
+	Wanted    int
+	Available int // Units that can still be ordered, 0 if none
+}
+
+// CanAdd checks that quantity more units of an item fit in the stock, on
+// top of those already in the cart.
+func (c *LocalCart) CanAdd(item LocalCartItem, quantity int) error {
+	limit, ok := item.Stock.Limit()
+	if !ok {
+		return nil
+	}
+	if c.QuantityOf(item.ProductID, item.VariationID)+quantity > limit {
+		return &StockLimitError{Name: item.Name, Available: limit}
+	}
+	return nil
+}
+
+// CanAddAll checks that items can all be added: that they fit in the
+// stock together, on top of those already in the cart, and are priced in
+// the store currency. Once it passes, adding them can't stop half way.
+func (c *LocalCart) CanAddAll(items []LocalCartItem) error {
+	type stockKey struct{ productID, variationID int }
+	adding := make(map[stockKey]int)
+	for _, item := range items {
+		if err := c.checkCurrency(item); err != nil {
+			return err
+		}
+		key := stockKey{item.ProductID, item.VariationID}
+		adding[key] += item.Quantity
+		if err := c.CanAdd(item, adding[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QuantityOf returns how many units of a product or variation the cart
+// holds, over all lines (e.g. one per grind size).
+func (c *LocalCart) QuantityOf(productID, variationID int) int {
+	quantity := 0
+	for _, item := range c.Items {
+		if item.ProductID == productID && item.VariationID == variationID {
+			quantity += item.Quantity
+		}
+	}
+	return quantity
+}
+
+// CheckStock updates the stock of the items from the products and
+// variations the shop has now, and returns the lines that can't be
+// fulfilled. Items missing from products or variations, or no longer
+// published, are no longer sold.
+// Lines sharing a product or variation get its stock in cart order.
+func (c *LocalCart) CheckStock(products map[int]woo.Product, variations map[int][]woo.Variation) []StockIssue {
+	type stockKey struct{ productID, variationID int }
+	remaining := make(map[stockKey]int)
+
+	var issues []StockIssue
+	for i := range c.Items {
+		item := &c.Items[i]
+		issue := StockIssue{Index: i, Name: item.GetDisplayName(), Wanted: item.Quantity}
+
+		product, ok := products[item.ProductID]
+		if !ok || (product.Status != "" && product.Status != "publish") {
+			issues = append(issues, issue)
+			continue
+		}
+		var variation *woo.Variation
+		if item.VariationID != 0 {
+			for j := range variations[item.ProductID] {
+				if variations[item.ProductID][j].ID == item.VariationID {
+					variation = &variations[item.ProductID][j]
+					break
+				}
+			}
+			if variation == nil || !variationPublished(variation) {
+				issues = append(issues, issue)
+				continue
+			}
+		}
+
+		item.Stock = productStock(&product, variation)
+		limit, limited := item.Stock.Limit()
+		if !limited {
+			continue
+		}
+		key := stockKey{item.ProductID, item.VariationID}
+		left, seen := remaining[key]
+		if !seen {
+			left = limit
+		}
+		if item.Quantity > left {
+			issue.Available = left
+			issues = append(issues, issue)
+			remaining[key] = 0
+			continue
+		}
+		remaining[key] = left - item.Quantity
+	}
+	return issues
+}
+
+// PriceChange is a cart line whose price changed since it was added.
+type PriceChange struct {
+	Index int // Line in the cart
+	Name  string
+	Was   woo.Money // Unit price the line had
+}
+
+// UpdatePrices updates the prices of the items from the products and
+// variations the shop has now, and returns the lines whose price changed.
+// A restored cart keeps the prices of the session that filled it, so this
+// runs with the stock check before ordering. Items the shop no longer
+// sells, including unpublished ones, or no longer has a valid price for,
+// are left for CheckStock.
+func (c *LocalCart) UpdatePrices(products map[int]woo.Product, variations map[int][]woo.Variation) []PriceChange {
+	var changes []PriceChange
+	for i := range c.Items {
+		item := &c.Items[i]
+		product, ok := products[item.ProductID]
+		if !ok {
+			continue
+		}
+		var price woo.Money
+		var err error
+		if item.VariationID == 0 {
+			price, err = product.DisplayPrice(c.Settings.Currency)
+		} else {
+			err = woo.ErrNotFound
+			for _, v := range variations[item.ProductID] {
+				if v.ID == item.VariationID && variationPublished(&v) {
+					price, err = v.DisplayPrice(c.Settings.Currency)
+					break
+				}
+			}
+		}
+		if err != nil {
+			continue
+		}
+		if cmp, err := price.Cmp(item.Price); err != nil || cmp == 0 {
+			continue
+		}
+		changes = append(changes, PriceChange{Index: i, Name: item.GetDisplayName(), Was: item.Price})
+		item.Price = price
+	}
+	if len(changes) > 0 {
+		c.changed()
+	}
+	return changes
+}
@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+func TestStockLimit(t *testing.T) {
+	zero, three := 0, 3
+	tests := []struct {
+		name        string
+		stock       Stock
+		limit       int
+		limited     bool
+		backordered int // Of 5 units
+	}{
+		{"untracked", Stock{Status: "instock"}, 0, false, 0},
+		{"tracked", Stock{Status: "instock", Quantity: &three}, 3, true, 0},
+		{"sold out", Stock{Status: "outofstock", Quantity: &zero}, 0, true, 0},
+		{"backorders allowed", Stock{Status: "instock", Quantity: &three, Backorders: true}, 0, false, 2},
+		{"on backorder", Stock{Status: "onbackorder", Quantity: &zero, Backorders: true}, 0, false, 5},
+		{"on backorder, untracked", Stock{Status: "onbackorder"}, 0, false, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, limited := tt.stock.Limit()
+			if limit != tt.limit || limited != tt.limited {
+				t.Errorf("Limit() = %d, %v, want %d, %v", limit, limited, tt.limit, tt.limited)
+			}
+			if got := tt.stock.Backordered(5); got != tt.backordered {
+				t.Errorf("Backordered(5) = %d, want %d", got, tt.backordered)
+			}
+		})
+	}
+}
+
+func TestCanAdd(t *testing.T) {
+	three := 3
+	cart := NewLocalCart(woo.DefaultStoreSettings())
+	item := LocalCartItem{ProductID: 1, Name: "Ethiopia", Price: woo.NewMoney(1899, 2, "USD"), Quantity: 2, GrindSize: "Espresso", Stock: Stock{Status: "instock", Quantity: &three}}
+	cart.AddItem(item)
+
+	// The stock is shared with the same coffee in another grind
+	other := item
+	other.GrindSize = "Filter"
+	if err := cart.CanAdd(other, 1); err != nil {
+		t.Errorf("expected a third bag to fit, got %v", err)
+	}
+	var stockErr *StockLimitError
+	if err := cart.CanAdd(other, 2); !errors.As(err, &stockErr) || stockErr.Available != 3 {
+		t.Errorf("expected a stock limit of 3, got %v", err)
+	}
+}
+
+func TestCheckStock(t *testing.T) {
+	one, five := 1, 5
+	products := map[int]woo.Product{
+		1: {ID: 1, Name: "Ethiopia", Status: "publish", StockStatus: "instock", StockQuantity: &five},
+		2: {ID: 2, Name: "House Blend", Type: "variable", Status: "publish", StockStatus: "instock"},
+		4: {ID: 4, Name: "Decaf", Status: "publish", StockStatus: "onbackorder", BackordersAllowed: true},
+	}
+	variations := map[int][]woo.Variation{
+		2: {
+			{ID: 21, Status: "publish", StockStatus: "instock", StockQuantity: &one},
+			{ID: 23, Status: "private", StockStatus: "instock"}, // Disabled by the shop
+		},
+	}
+
+	cart := NewLocalCart(woo.DefaultStoreSettings())
+	for _, item := range []LocalCartItem{
+		{ProductID: 1, Name: "Ethiopia", Quantity: 3, GrindSize: "Espresso"},
+		{ProductID: 1, Name: "Ethiopia", Quantity: 3, GrindSize: "Filter"}, // 2 of the 5 left for this one
+		{ProductID: 2, VariationID: 21, Name: "House Blend (250g)", Quantity: 1},
+		{ProductID: 2, VariationID: 22, Name: "House Blend (1kg)", Quantity: 1}, // Variation removed
+		{ProductID: 3, Name: "Kenya", Quantity: 1},                              // Product removed
+		{ProductID: 4, Name: "Decaf", Quantity: 10},
+		{ProductID: 2, VariationID: 23, Name: "House Blend (5kg)", Quantity: 1},
+	} {
+		cart.AddItem(item)
+	}
+
+	issues := cart.CheckStock(products, variations)
+	want := []StockIssue{
+		{Index: 1, Name: "Ethiopia - Filter", Wanted: 3, Available: 2},
+		{Index: 3, Name: "House Blend (1kg)", Wanted: 1},
+		{Index: 4, Name: "Kenya", Wanted: 1},
+		{Index: 6, Name: "House Blend (5kg)", Wanted: 1},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %+v", len(want), issues)
+	}
+	for i := range want {
+		if issues[i] != want[i] {
+			t.Errorf("issue %d: got %+v, want %+v", i, issues[i], want[i])
+		}
+	}
+
+	// The stock seen is kept to cap quantities in the cart
+	if q := cart.Items[2].Stock.Quantity; q == nil || *q != 1 {
+		t.Errorf("expected the variation's stock to be recorded, got %+v", cart.Items[2].Stock)
+	}
+	if got := cart.Items[5].Stock.Backordered(10); got != 10 {
+		t.Errorf("expected the decaf to be on backorder, got %d", got)
+	}
+}
+
+func TestUpdatePrices(t *testing.T) {
+	products := map[int]woo.Product{
+		1: {ID: 1, Name: "Ethiopia", Price: "21.00"},
+		2: {ID: 2, Name: "House Blend", Type: "variable"},
+	}
+	variations := map[int][]woo.Variation{
+		2: {{ID: 21, Price: "9.50"}, {ID: 22, Price: "30.00"}},
+	}
+
+	cart := NewLocalCart(woo.DefaultStoreSettings())
+	for _, item := range []LocalCartItem{
+		{ProductID: 1, Name: "Ethiopia", Price: woo.NewMoney(1899, 2, "USD"), Quantity: 1},
+		{ProductID: 2, VariationID: 21, Name: "House Blend (250g)", Price: woo.NewMoney(950, 2, "USD"), Quantity: 1},
+		{ProductID: 2, VariationID: 22, Name: "House Blend (1kg)", Price: woo.NewMoney(2800, 2, "USD"), Quantity: 1},
+		{ProductID: 3, Name: "Kenya", Price: woo.NewMoney(1500, 2, "USD"), Quantity: 1}, // Product removed
+	} {
+		cart.AddItem(item)
+	}
+	cart.Shipping = &woo.ShippingRate{MethodID: "flat_rate"}
+	revision := cart.Revision()
+
+	changes := cart.UpdatePrices(products, variations)
+	want := []PriceChange{
+		{Index: 0, Name: "Ethiopia", Was: woo.NewMoney(1899, 2, "USD")},
+		{Index: 2, Name: "House Blend (1kg)", Was: woo.NewMoney(2800, 2, "USD")},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d: got %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+	if got := cart.Subtotal(); got != woo.NewMoney(7550, 2, "USD") {
+		t.Errorf("expected the subtotal at the new prices, got %s", got)
+	}
+	if cart.Revision() == revision || cart.Shipping != nil {
+		t.Error("expected the repriced cart to count as changed")
+	}
+
+	// Nothing changes the second time
+	if changes := cart.UpdatePrices(products, variations); changes != nil {
+		t.Errorf("expected no more changes, got %+v", changes)
+	}
+}
+
+func TestCanAddAll(t *testing.T) {
+	three := 3
+	stock := Stock{Status: "instock", Quantity: &three}
+	cart := NewLocalCart(woo.DefaultStoreSettings())
+	cart.AddItem(LocalCartItem{ProductID: 1, Name: "Ethiopia", Price: woo.NewMoney(1899, 2, "USD"), Quantity: 1, GrindSize: "Espresso", Stock: stock})
+
+	espresso := LocalCartItem{ProductID: 1, Name: "Ethiopia", Price: woo.NewMoney(1899, 2, "USD"), Quantity: 1, GrindSize: "Espresso", Stock: stock}
+	filter := espresso
+	filter.GrindSize, filter.Quantity = "Filter", 1
+	if err := cart.CanAddAll([]LocalCartItem{espresso, filter}); err != nil {
+		t.Errorf("expected 3 bags to fit, got %v", err)
+	}
+
+	// Each line fits on its own, but not together
+	filter.Quantity = 2
+	var limitErr *StockLimitError
+	if err := cart.CanAddAll([]LocalCartItem{espresso, filter}); !errors.As(err, &limitErr) || limitErr.Available != 3 {
+		t.Errorf("expected a stock limit error, got %v", err)
+	}
+
+	euros := espresso
+	euros.Price = woo.NewMoney(1799, 2, "EUR")
+	if err := cart.CanAddAll([]LocalCartItem{euros}); !errors.Is(err, woo.ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+}
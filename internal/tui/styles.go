@@ -41,6 +41,14 @@ type Styles struct {
 	ProductAttribute   lipgloss.Style
 	ProductInStock     lipgloss.Style
 	ProductOutOfStock  lipgloss.Style
+	ProductRating      lipgloss.Style // Stars
+
+	// Product descriptions rendered from HTML
+	DescHeading lipgloss.Style
+	DescLink    lipgloss.Style
+	DescCode    lipgloss.Style
+	DescQuote   lipgloss.Style
+	DescMarker  lipgloss.Style // List markers, quote bars, table borders and rules
 
 	// Configurator
 	ConfigTitle   lipgloss.Style
@@ -51,6 +59,7 @@ type Styles struct {
 	Subtle    lipgloss.Style
 	Highlight lipgloss.Style
 	Error     lipgloss.Style
+	Warning   lipgloss.Style
 	Success   lipgloss.Style
 	Box       lipgloss.Style
 	HelpBar   lipgloss.Style
@@ -122,6 +131,28 @@ func DefaultStyles() Styles {
 		ProductOutOfStock: lipgloss.NewStyle().
 			Foreground(colorError),
 
+		ProductRating: lipgloss.NewStyle().
+			Foreground(colorWarning),
+
+		DescHeading: lipgloss.NewStyle().
+			Foreground(colorCaramel).
+			Bold(true),
+
+		DescLink: lipgloss.NewStyle().
+			Foreground(colorHighlight).
+			Underline(true),
+
+		DescCode: lipgloss.NewStyle().
+			Foreground(colorCaramel).
+			Background(colorEspresso),
+
+		DescQuote: lipgloss.NewStyle().
+			Foreground(colorMocha).
+			Italic(true),
+
+		DescMarker: lipgloss.NewStyle().
+			Foreground(colorMocha),
+
 		ConfigTitle: lipgloss.NewStyle().
 			Foreground(colorCaramel).
 			Bold(true).
@@ -147,6 +178,9 @@ func DefaultStyles() Styles {
 			Foreground(colorError).
 			Bold(true),
 
+		Warning: lipgloss.NewStyle().
+			Foreground(colorWarning),
+
 		Success: lipgloss.NewStyle().
 			Foreground(colorSuccess),
 
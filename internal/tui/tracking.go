@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+// Order tracking polls the order on a backoff schedule: quickly at first,
+// then less often while nothing changes. Any change starts over.
+const (
+	trackingBaseDelay = 5 * time.Second
+	trackingMaxDelay  = 2 * time.Minute
+)
+
+// trackingDelay returns how long to wait before the next poll, after
+// polls polls in a row without a change.
+func trackingDelay(polls int) time.Duration {
+	delay := trackingBaseDelay
+	for i := 0; i < polls && delay < trackingMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > trackingMaxDelay {
+		delay = trackingMaxDelay
+	}
+	return delay
+}
+
+// isFinalOrderStatus reports whether an order status won't change any
+// more on its own, so there is nothing left to watch.
+func isFinalOrderStatus(status string) bool {
+	switch status {
+	case woo.OrderStatusCompleted, woo.OrderStatusCancelled, woo.OrderStatusRefunded, woo.OrderStatusFailed:
+		return true
+	}
+	return false
+}
+
+// trackingStep is a status an order has been seen in.
+type trackingStep struct {
+	Status string
+	Since  time.Time // When the status was first seen
+}
+
+// timelineStep is a line of the status timeline.
+type timelineStep struct {
+	Status  string
+	Since   time.Time // Zero for steps still to come
+	Current bool
+	Done    bool
+}
+
+// statusTimeline lays out the statuses an order went through, followed by
+// the ones it is still expected to go through. Orders start out pending
+// payment or on hold (e.g. bank transfers) and move on to processing and
+// completed; cancelled, refunded and failed orders stop where they are.
+func statusTimeline(seen []trackingStep) []timelineStep {
+	if len(seen) == 0 {
+		return nil
+	}
+
+	timeline := make([]timelineStep, len(seen))
+	for i, step := range seen {
+		timeline[i] = timelineStep{Status: step.Status, Since: step.Since, Done: i < len(seen)-1}
+	}
+	timeline[len(timeline)-1].Current = true
+
+	current := seen[len(seen)-1].Status
+	if current == woo.OrderStatusCompleted {
+		timeline[len(timeline)-1].Done = true
+	}
+	if isFinalOrderStatus(current) {
+		return timeline
+	}
+
+	var upcoming []string
+	switch current {
+	case woo.OrderStatusPending, woo.OrderStatusOnHold:
+		upcoming = []string{woo.OrderStatusProcessing, woo.OrderStatusCompleted}
+	default:
+		upcoming = []string{woo.OrderStatusCompleted}
+	}
+	for _, status := range upcoming {
+		timeline = append(timeline, timelineStep{Status: status})
+	}
+	return timeline
+}
+
+// trackStatus records the order's status if it differs from the last one
+// seen, and reports whether it did.
+func trackStatus(seen []trackingStep, status string, now time.Time) ([]trackingStep, bool) {
+	if len(seen) > 0 && seen[len(seen)-1].Status == status {
+		return seen, false
+	}
+	return append(seen, trackingStep{Status: status, Since: now}), true
+}
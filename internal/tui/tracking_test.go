@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thomas/eva-terminal-go/internal/woo"
+)
+
+func TestTrackingDelay(t *testing.T) {
+	want := []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second, 2 * time.Minute, 2 * time.Minute}
+	for polls, delay := range want {
+		if got := trackingDelay(polls); got != delay {
+			t.Errorf("trackingDelay(%d) = %v, want %v", polls, got, delay)
+		}
+	}
+}
+
+func TestStatusTimeline(t *testing.T) {
+	placed := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	seen, changed := trackStatus(nil, woo.OrderStatusOnHold, placed)
+	if !changed {
+		t.Fatal("expected the first status to be recorded")
+	}
+	if _, changed := trackStatus(seen, woo.OrderStatusOnHold, placed.Add(time.Minute)); changed {
+		t.Error("expected the same status not to be recorded twice")
+	}
+
+	// A bank transfer waits on hold, then goes through processing to completed
+	timeline := statusTimeline(seen)
+	if len(timeline) != 3 || !timeline[0].Current || timeline[1].Status != woo.OrderStatusProcessing || !timeline[2].Since.IsZero() {
+		t.Errorf("unexpected timeline %+v", timeline)
+	}
+
+	seen, _ = trackStatus(seen, woo.OrderStatusProcessing, placed.Add(time.Hour))
+	timeline = statusTimeline(seen)
+	if len(timeline) != 3 || !timeline[0].Done || !timeline[1].Current || timeline[1].Done || timeline[2].Status != woo.OrderStatusCompleted {
+		t.Errorf("unexpected timeline %+v", timeline)
+	}
+
+	seen, _ = trackStatus(seen, woo.OrderStatusCompleted, placed.Add(2*time.Hour))
+	timeline = statusTimeline(seen)
+	if len(timeline) != 3 || !timeline[2].Current || !timeline[2].Done {
+		t.Errorf("expected a finished timeline, got %+v", timeline)
+	}
+
+	// Cancelled orders have nothing to come
+	cancelled, _ := trackStatus(seen[:1], woo.OrderStatusCancelled, placed.Add(time.Hour))
+	if timeline := statusTimeline(cancelled); len(timeline) != 2 || !timeline[1].Current || timeline[1].Done {
+		t.Errorf("unexpected cancelled timeline %+v", timeline)
+	}
+}
@@ -0,0 +1,150 @@
+package woo
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuthMode selects how API credentials are sent to WooCommerce.
+type AuthMode string
+
+const (
+	// AuthAuto uses HTTP Basic auth over HTTPS and OAuth 1.0a over HTTP.
+	AuthAuto AuthMode = "auto"
+	// AuthBasic sends the key and secret as HTTP Basic credentials.
+	// WooCommerce only accepts this over HTTPS.
+	AuthBasic AuthMode = "basic"
+	// AuthOAuth1 signs each request with one-legged OAuth 1.0a (HMAC-SHA256).
+	AuthOAuth1 AuthMode = "oauth1"
+	// AuthQueryString sends consumer_key and consumer_secret as query
+	// parameters. The secret ends up in proxy and access logs; opt-in only.
+	AuthQueryString AuthMode = "query"
+)
+
+// ParseAuthMode converts a configuration string into an AuthMode.
+// An empty string selects AuthAuto.
+func ParseAuthMode(s string) (AuthMode, error) {
+	switch mode := AuthMode(strings.ToLower(s)); mode {
+	case "":
+		return AuthAuto, nil
+	case AuthAuto, AuthBasic, AuthOAuth1, AuthQueryString:
+		return mode, nil
+	}
+	return "", errors.New("auth mode must be 'auto', 'basic', 'oauth1' or 'query'")
+}
+
+// WithAuthMode sets how credentials are sent. The default is AuthAuto.
+func WithAuthMode(mode AuthMode) ClientOption {
+	return func(c *Client) {
+		c.authMode = mode
+	}
+}
+
+// effectiveAuthMode resolves AuthAuto against the base URL scheme.
+func (c *Client) effectiveAuthMode() AuthMode {
+	if c.authMode != "" && c.authMode != AuthAuto {
+		return c.authMode
+	}
+	if strings.HasPrefix(strings.ToLower(c.baseURL), "https://") {
+		return AuthBasic
+	}
+	return AuthOAuth1
+}
+
+// hasCredentials returns true if both key and secret are set.
+func (c *Client) hasCredentials() bool {
+	return c.consumerKey != "" && c.consumerSecret != ""
+}
+
+// authenticate adds credentials to an outgoing request. The request URL
+// must already carry every query parameter, since OAuth signs them all.
+func (c *Client) authenticate(req *http.Request) error {
+	if !c.hasCredentials() {
+		return nil
+	}
+
+	switch c.effectiveAuthMode() {
+	case AuthBasic:
+		req.SetBasicAuth(c.consumerKey, c.consumerSecret)
+
+	case AuthQueryString:
+		q := req.URL.Query()
+		q.Set("consumer_key", c.consumerKey)
+		q.Set("consumer_secret", c.consumerSecret)
+		req.URL.RawQuery = q.Encode()
+
+	default:
+		nonce, err := oauthNonce()
+		if err != nil {
+			return fmt.Errorf("generating OAuth nonce: %w", err)
+		}
+		c.signOAuth1(req, nonce, time.Now())
+	}
+	return nil
+}
+
+// signOAuth1 adds one-legged OAuth 1.0a parameters and signature to the
+// request query string, as expected by WooCommerce over plain HTTP.
+func (c *Client) signOAuth1(req *http.Request, nonce string, now time.Time) {
+	q := req.URL.Query()
+	q.Set("oauth_consumer_key", c.consumerKey)
+	q.Set("oauth_nonce", nonce)
+	q.Set("oauth_signature_method", "HMAC-SHA256")
+	q.Set("oauth_timestamp", strconv.FormatInt(now.Unix(), 10))
+
+	// Normalized parameters: encoded key=value pairs sorted by key, then value
+	var pairs []string
+	for key, values := range q {
+		for _, value := range values {
+			pairs = append(pairs, oauthEscape(key)+"="+oauthEscape(value))
+		}
+	}
+	sort.Strings(pairs)
+
+	baseURL := req.URL.Scheme + "://" + req.URL.Host + req.URL.EscapedPath()
+	baseString := req.Method + "&" + oauthEscape(baseURL) + "&" + oauthEscape(strings.Join(pairs, "&"))
+
+	mac := hmac.New(sha256.New, []byte(c.consumerSecret+"&"))
+	mac.Write([]byte(baseString))
+	q.Set("oauth_signature", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	req.URL.RawQuery = q.Encode()
+}
+
+// oauthEscape percent-encodes s as required by RFC 5849 section 3.6.
+func oauthEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// nonceSource supplies the randomness for OAuth nonces.
+var nonceSource io.Reader = rand.Reader
+
+// oauthNonce returns a random 32-character nonce.
+func oauthNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(nonceSource, b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// redactURL strips the query string from a request URL so that
+// credentials and signatures never end up in error messages or logs.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.RawQuery = ""
+	redacted.User = nil
+	return redacted.String()
+}
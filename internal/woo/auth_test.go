@@ -0,0 +1,157 @@
+package woo
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestAuthAutoUsesBasicOverHTTPS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "ck_test" || pass != "cs_test" {
+			t.Errorf("expected basic auth ck_test/cs_test, got %q/%q (ok=%v)", user, pass, ok)
+		}
+		if r.URL.Query().Get("consumer_secret") != "" {
+			t.Error("secret must not be sent in the query string")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Product{})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithCredentials("ck_test", "cs_test"), WithHTTPClient(server.Client()))
+	if _, err := client.GetProducts(context.Background(), GetProductsParams{}); err != nil {
+		t.Fatalf("GetProducts failed: %v", err)
+	}
+}
+
+func TestAuthAutoUsesOAuth1OverHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if _, _, ok := r.BasicAuth(); ok {
+			t.Error("basic auth must not be used over plain HTTP")
+		}
+		if q.Get("consumer_secret") != "" {
+			t.Error("secret must not be sent in the query string")
+		}
+		if q.Get("oauth_consumer_key") != "ck_test" {
+			t.Errorf("expected oauth_consumer_key=ck_test, got %q", q.Get("oauth_consumer_key"))
+		}
+		if q.Get("oauth_signature_method") != "HMAC-SHA256" {
+			t.Errorf("expected HMAC-SHA256, got %q", q.Get("oauth_signature_method"))
+		}
+		if q.Get("oauth_nonce") == "" || q.Get("oauth_timestamp") == "" {
+			t.Error("expected nonce and timestamp")
+		}
+		if q.Get("search") != "kenya" {
+			t.Errorf("expected original query to be kept, got search=%q", q.Get("search"))
+		}
+
+		// Verify the signature the way WooCommerce does
+		got := q.Get("oauth_signature")
+		q.Del("oauth_signature")
+		var pairs []string
+		for k, vs := range q {
+			for _, v := range vs {
+				pairs = append(pairs, oauthEscape(k)+"="+oauthEscape(v))
+			}
+		}
+		sort.Strings(pairs)
+		base := "GET&" + oauthEscape("http://"+r.Host+r.URL.Path) + "&" + oauthEscape(strings.Join(pairs, "&"))
+		mac := hmac.New(sha256.New, []byte("cs_test&"))
+		mac.Write([]byte(base))
+		if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); got != want {
+			t.Errorf("signature mismatch: got %q, want %q", got, want)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Product{})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithCredentials("ck_test", "cs_test"))
+	if _, err := client.GetProducts(context.Background(), GetProductsParams{Search: "kenya"}); err != nil {
+		t.Fatalf("GetProducts failed: %v", err)
+	}
+}
+
+func TestAuthNonceFailure(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode([]Product{})
+	}))
+	defer server.Close()
+
+	nonceSource = iotest.ErrReader(errors.New("no entropy"))
+	defer func() { nonceSource = rand.Reader }()
+
+	client := NewClient(server.URL, WithCredentials("ck_test", "cs_test"))
+	if _, err := client.GetProducts(context.Background(), GetProductsParams{}); err == nil || !strings.Contains(err.Error(), "no entropy") {
+		t.Errorf("expected the nonce error, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("expected no unsigned request to be sent, got %d", calls)
+	}
+}
+
+func TestSignOAuth1KnownSignature(t *testing.T) {
+	c := NewClient("http://shop.example", WithCredentials("ck_abc", "cs_xyz"))
+	req, _ := http.NewRequest(http.MethodGet, "http://shop.example/wp-json/wc/v3/products?per_page=10&search=a b", nil)
+
+	c.signOAuth1(req, "nonce123", time.Unix(1700000000, 0))
+
+	q := req.URL.Query()
+	base := "GET&http%3A%2F%2Fshop.example%2Fwp-json%2Fwc%2Fv3%2Fproducts&" +
+		"oauth_consumer_key%3Dck_abc%26oauth_nonce%3Dnonce123%26oauth_signature_method%3DHMAC-SHA256" +
+		"%26oauth_timestamp%3D1700000000%26per_page%3D10%26search%3Da%2520b"
+	mac := hmac.New(sha256.New, []byte("cs_xyz&"))
+	mac.Write([]byte(base))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); q.Get("oauth_signature") != want {
+		t.Errorf("signature mismatch: got %q, want %q", q.Get("oauth_signature"), want)
+	}
+}
+
+func TestParseAuthMode(t *testing.T) {
+	for in, want := range map[string]AuthMode{"": AuthAuto, "auto": AuthAuto, "BASIC": AuthBasic, "oauth1": AuthOAuth1, "query": AuthQueryString} {
+		got, err := ParseAuthMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseAuthMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseAuthMode("digest"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestTransportErrorDoesNotLeakSecret(t *testing.T) {
+	// Nothing listens on this address, so the request fails in transport
+	client := NewClient("http://127.0.0.1:1", WithCredentials("ck_test", "cs_supersecret"), WithAuthMode(AuthQueryString))
+	_, err := client.GetProducts(context.Background(), GetProductsParams{})
+	if err == nil {
+		t.Fatal("expected transport error")
+	}
+	if strings.Contains(err.Error(), "cs_supersecret") {
+		t.Errorf("error leaks consumer secret: %v", err)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	u, _ := url.Parse("http://user:pw@shop.example/wp-json/wc/v3/products?consumer_secret=cs_x")
+	if got := redactURL(u); got != "http://shop.example/wp-json/wc/v3/products" {
+		t.Errorf("unexpected redacted URL %q", got)
+	}
+}
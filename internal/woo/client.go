@@ -4,26 +4,34 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Client is a WooCommerce REST API client.
+// A single Client is safe for concurrent use by many SSH sessions.
 type Client struct {
-	baseURL       string
-	consumerKey   string
+	baseURL        string
+	consumerKey    string
 	consumerSecret string
-	httpClient    *http.Client
+	authMode       AuthMode
+	httpClient     *http.Client
+	retryPolicy    RetryPolicy
+	limiter        *RateLimiter
 }
 
 // ClientOption is a functional option for configuring the client.
 type ClientOption func(*Client)
 
 // WithCredentials sets the WooCommerce API credentials.
+// How they are sent is controlled by WithAuthMode.
 func WithCredentials(key, secret string) ClientOption {
 	return func(c *Client) {
 		c.consumerKey = key
@@ -38,6 +46,22 @@ func WithHTTPClient(hc *http.Client) ClientOption {
 	}
 }
 
+// WithRetryPolicy enables retries with exponential backoff.
+// Without it, every request is attempted exactly once.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = p
+	}
+}
+
+// WithRateLimiter throttles all requests made by the client through l.
+// The limiter may be shared between clients.
+func WithRateLimiter(l *RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
 // NewClient creates a new WooCommerce API client.
 func NewClient(baseURL string, opts ...ClientOption) *Client {
 	c := &Client{
@@ -58,10 +82,45 @@ type GetProductsParams struct {
 	PerPage     int
 	Search      string
 	InStockOnly bool
+	Category    int // Category ID, 0 for all categories
+	Tag         int // Tag ID, 0 for all tags
+
+	// Sorting
+	OrderBy string // One of the OrderBy* constants, empty for the API default
+	Order   string // "asc" or "desc", empty for the API default
+
+	// Filters
+	MinPrice string // Decimal string, e.g. "15.00"
+	MaxPrice string
+	OnSale   bool
+	Featured bool
+	SKU      string
+	Include  []int // Only these product IDs
+	Exclude  []int // Never these product IDs
+}
+
+// Sort fields accepted by GetProductsParams.OrderBy.
+const (
+	OrderByDate       = "date"
+	OrderByPrice      = "price"
+	OrderByPopularity = "popularity"
+	OrderByRating     = "rating"
+	OrderByTitle      = "title"
+)
+
+// JoinIDs formats IDs as the comma-separated list WordPress accepts for array parameters.
+func JoinIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
 }
 
-// GetProducts fetches a list of products from the WooCommerce API.
-func (c *Client) GetProducts(ctx context.Context, params GetProductsParams) ([]Product, error) {
+// GetProducts fetches a page of products from the WooCommerce API.
+// The returned ProductPage carries the pagination metadata reported by
+// the X-WP-Total and X-WP-TotalPages headers.
+func (c *Client) GetProducts(ctx context.Context, params GetProductsParams) (*ProductPage, error) {
 	endpoint := "/wp-json/wc/v3/products"
 
 	query := url.Values{}
@@ -77,26 +136,77 @@ func (c *Client) GetProducts(ctx context.Context, params GetProductsParams) ([]P
 	if params.InStockOnly {
 		query.Set("stock_status", "instock")
 	}
+	if params.Category > 0 {
+		query.Set("category", strconv.Itoa(params.Category))
+	}
+	if params.Tag > 0 {
+		query.Set("tag", strconv.Itoa(params.Tag))
+	}
+	if params.OrderBy != "" {
+		query.Set("orderby", params.OrderBy)
+	}
+	if params.Order != "" {
+		query.Set("order", params.Order)
+	}
+	if params.MinPrice != "" {
+		query.Set("min_price", params.MinPrice)
+	}
+	if params.MaxPrice != "" {
+		query.Set("max_price", params.MaxPrice)
+	}
+	if params.OnSale {
+		query.Set("on_sale", "true")
+	}
+	if params.Featured {
+		query.Set("featured", "true")
+	}
+	if params.SKU != "" {
+		query.Set("sku", params.SKU)
+	}
+	if len(params.Include) > 0 {
+		query.Set("include", JoinIDs(params.Include))
+	}
+	if len(params.Exclude) > 0 {
+		query.Set("exclude", JoinIDs(params.Exclude))
+	}
 
 	var products []Product
-	if err := c.doRequest(ctx, endpoint, query, &products); err != nil {
+	header, err := c.doGet(ctx, endpoint, query, &products)
+	if err != nil {
 		return nil, err
 	}
-	return products, nil
+	return &ProductPage{
+		Products: products,
+		PageInfo: parsePageInfo(header, params.Page, params.PerPage, len(products)),
+	}, nil
 }
 
-// GetVariations fetches variations for a variable product.
+// GetVariations fetches every variation of a variable product,
+// following pagination beyond the 100-per-page API limit.
 func (c *Client) GetVariations(ctx context.Context, productID int) ([]Variation, error) {
-	endpoint := fmt.Sprintf("/wp-json/wc/v3/products/%d/variations", productID)
+	it := c.AllVariations(ctx, productID)
+	defer it.Close()
 
-	query := url.Values{}
-	query.Set("per_page", "100") // Get all variations
+	return collect(it)
+}
 
-	var variations []Variation
-	if err := c.doRequest(ctx, endpoint, query, &variations); err != nil {
-		return nil, err
-	}
-	return variations, nil
+// GetTermsParams holds parameters for listing categories or tags.
+type GetTermsParams struct {
+	HideEmpty bool // Skip terms with no published products
+}
+
+// GetCategories fetches every product category.
+func (c *Client) GetCategories(ctx context.Context, params GetTermsParams) ([]Category, error) {
+	it := allTerms[Category](ctx, c, "/wp-json/wc/v3/products/categories", params)
+	defer it.Close()
+	return collect(it)
+}
+
+// GetTags fetches every product tag.
+func (c *Client) GetTags(ctx context.Context, params GetTermsParams) ([]Tag, error) {
+	it := allTerms[Tag](ctx, c, "/wp-json/wc/v3/products/tags", params)
+	defer it.Close()
+	return collect(it)
 }
 
 // GetPaymentGateways fetches available payment gateways.
@@ -105,6 +215,26 @@ func (c *Client) GetPaymentGateways(ctx context.Context, result *[]PaymentGatewa
 	return c.doRequest(ctx, endpoint, nil, result)
 }
 
+// GetAvailablePaymentGateways fetches the gateways customers can pay with,
+// in the order configured in the store.
+func (c *Client) GetAvailablePaymentGateways(ctx context.Context) ([]PaymentGateway, error) {
+	var gateways []PaymentGateway
+	if err := c.GetPaymentGateways(ctx, &gateways); err != nil {
+		return nil, err
+	}
+
+	available := []PaymentGateway{}
+	for _, g := range gateways {
+		if g.IsAvailable() {
+			available = append(available, g)
+		}
+	}
+	sort.SliceStable(available, func(i, j int) bool {
+		return available[i].Order < available[j].Order
+	})
+	return available, nil
+}
+
 // CreateOrder creates a new order in WooCommerce.
 func (c *Client) CreateOrder(ctx context.Context, order OrderRequest) (*OrderResponse, error) {
 	endpoint := "/wp-json/wc/v3/orders"
@@ -116,87 +246,148 @@ func (c *Client) CreateOrder(ctx context.Context, order OrderRequest) (*OrderRes
 	return &response, nil
 }
 
+// parsePageInfo builds pagination metadata from WordPress REST headers.
+// When the headers are missing it assumes the response is the only page.
+func parsePageInfo(header http.Header, page, perPage, count int) PageInfo {
+	if page < 1 {
+		page = 1
+	}
+	info := PageInfo{
+		Page:       page,
+		PerPage:    perPage,
+		TotalItems: count,
+		TotalPages: 1,
+	}
+	if total, err := strconv.Atoi(header.Get("X-WP-Total")); err == nil {
+		info.TotalItems = total
+	}
+	if pages, err := strconv.Atoi(header.Get("X-WP-TotalPages")); err == nil {
+		info.TotalPages = pages
+	}
+	return info
+}
+
 // doRequest performs an HTTP GET request to the WooCommerce API.
 func (c *Client) doRequest(ctx context.Context, endpoint string, query url.Values, result interface{}) error {
-	// Add authentication if credentials are provided
-	if c.consumerKey != "" && c.consumerSecret != "" {
-		query.Set("consumer_key", c.consumerKey)
-		query.Set("consumer_secret", c.consumerSecret)
-	}
+	_, err := c.doGet(ctx, endpoint, query, result)
+	return err
+}
 
-	reqURL := c.baseURL + endpoint
-	if len(query) > 0 {
-		reqURL += "?" + query.Encode()
-	}
+// doGet performs an HTTP GET request and returns the response headers
+// alongside the decoded body.
+func (c *Client) doGet(ctx context.Context, endpoint string, query url.Values, result interface{}) (http.Header, error) {
+	return c.doJSON(ctx, http.MethodGet, endpoint, query, nil, result)
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
-	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
-	}
+// doPostRequest performs an HTTP POST request to the WooCommerce API.
+func (c *Client) doPostRequest(ctx context.Context, endpoint string, body interface{}, result interface{}) error {
+	_, err := c.doJSON(ctx, http.MethodPost, endpoint, nil, body, result)
+	return err
+}
 
-	req.Header.Set("Accept", "application/json")
+// doJSON sends a request with an optional JSON body and decodes the JSON
+// response into result. Non-2xx responses are returned as *APIError.
+func (c *Client) doJSON(ctx context.Context, method, endpoint string, query url.Values, body interface{}, result interface{}) (http.Header, error) {
+	var jsonBody []byte
+	if body != nil {
+		var err error
+		jsonBody, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encoding request body: %w", err)
+		}
+	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(ctx, method, endpoint, query, jsonBody)
 	if err != nil {
-		return fmt.Errorf("executing request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
-	}
-
 	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-		return fmt.Errorf("decoding response: %w", err)
+		return nil, fmt.Errorf("decoding response: %w", err)
 	}
 
-	return nil
+	return resp.Header, nil
 }
 
-// doPostRequest performs an HTTP POST request to the WooCommerce API.
-func (c *Client) doPostRequest(ctx context.Context, endpoint string, body interface{}, result interface{}) error {
-	// Encode body as JSON
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		return fmt.Errorf("encoding request body: %w", err)
+// send performs a request, waiting on the rate limiter and retrying
+// according to the client's retry policy. On success the caller must
+// close the response body.
+func (c *Client) send(ctx context.Context, method, endpoint string, query url.Values, body []byte) (*http.Response, error) {
+	attempts := c.retryPolicy.attemptsFor(method)
+
+	for attempt := 1; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.sendOnce(ctx, method, endpoint, query, body)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= attempts || ctx.Err() != nil {
+			return nil, err
+		}
+
+		delay, ok := c.retryPolicy.delay(attempt, err)
+		if !ok {
+			return nil, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
 	}
+}
 
-	// Build URL with authentication
+// sendOnce performs a single HTTP round trip.
+func (c *Client) sendOnce(ctx context.Context, method, endpoint string, query url.Values, body []byte) (*http.Response, error) {
 	reqURL := c.baseURL + endpoint
-	if c.consumerKey != "" && c.consumerSecret != "" {
-		query := url.Values{}
-		query.Set("consumer_key", c.consumerKey)
-		query.Set("consumer_secret", c.consumerSecret)
+	if len(query) > 0 {
 		reqURL += "?" + query.Encode()
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(jsonBody))
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	req.Header.Set("Accept", "application/json")
+	if err := c.authenticate(req); err != nil {
+		return nil, err
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("executing request: %w", err)
+		// The URL may carry credentials or an OAuth signature
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redactURL(req.URL)
+		}
+		return nil, fmt.Errorf("executing request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// Accept 200 OK or 201 Created
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-		return fmt.Errorf("decoding response: %w", err)
+		apiErr := newAPIError(resp.StatusCode, respBody)
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return nil, apiErr
 	}
 
-	return nil
+	return resp, nil
 }
-
-
-
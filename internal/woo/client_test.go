@@ -56,13 +56,14 @@ func TestGetProducts(t *testing.T) {
 	client := NewClient(server.URL)
 
 	// Test GetProducts
-	products, err := client.GetProducts(context.Background(), GetProductsParams{
+	page, err := client.GetProducts(context.Background(), GetProductsParams{
 		Page:    1,
 		PerPage: 10,
 	})
 	if err != nil {
 		t.Fatalf("GetProducts failed: %v", err)
 	}
+	products := page.Products
 
 	if len(products) != 2 {
 		t.Fatalf("expected 2 products, got %d", len(products))
@@ -77,6 +78,60 @@ func TestGetProducts(t *testing.T) {
 	}
 }
 
+func TestGetProductsPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") != "2" {
+			t.Errorf("expected page=2, got %s", r.URL.Query().Get("page"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-WP-Total", "45")
+		w.Header().Set("X-WP-TotalPages", "3")
+		json.NewEncoder(w).Encode([]Product{{ID: 21, Name: "Page Two Coffee"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	page, err := client.GetProducts(context.Background(), GetProductsParams{Page: 2, PerPage: 20})
+	if err != nil {
+		t.Fatalf("GetProducts failed: %v", err)
+	}
+
+	if page.TotalItems != 45 {
+		t.Errorf("expected 45 total items, got %d", page.TotalItems)
+	}
+	if page.TotalPages != 3 {
+		t.Errorf("expected 3 total pages, got %d", page.TotalPages)
+	}
+	if page.Page != 2 {
+		t.Errorf("expected page 2, got %d", page.Page)
+	}
+	if !page.HasNext() || !page.HasPrev() {
+		t.Error("expected page 2 of 3 to have both next and previous pages")
+	}
+}
+
+func TestGetProductsPaginationMissingHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Product{{ID: 1}, {ID: 2}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	page, err := client.GetProducts(context.Background(), GetProductsParams{PerPage: 10})
+	if err != nil {
+		t.Fatalf("GetProducts failed: %v", err)
+	}
+
+	if page.TotalItems != 2 || page.TotalPages != 1 || page.Page != 1 {
+		t.Errorf("expected single page of 2 items, got %+v", page.PageInfo)
+	}
+	if page.HasNext() || page.HasPrev() {
+		t.Error("expected no adjacent pages")
+	}
+}
+
 func TestGetProductsWithSearch(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		query := r.URL.Query()
@@ -228,7 +283,7 @@ func TestGetProductsWithCredentials(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewClient(server.URL, WithCredentials("ck_test", "cs_test"))
+	client := NewClient(server.URL, WithCredentials("ck_test", "cs_test"), WithAuthMode(AuthQueryString))
 	_, err := client.GetProducts(context.Background(), GetProductsParams{Page: 1, PerPage: 10})
 	if err != nil {
 		t.Fatalf("GetProducts with credentials failed: %v", err)
@@ -273,6 +328,39 @@ func TestProductMethods(t *testing.T) {
 	if noAttr != nil {
 		t.Error("expected nil for nonexistent attribute")
 	}
+
+	if p.MainImage() != nil {
+		t.Error("expected nil main image for a product without images")
+	}
+	p.Images = []Image{{ID: 7, Src: "https://shop.example/mug.jpg", Alt: "Mug"}, {ID: 8, Src: "https://shop.example/mug-2.jpg"}}
+	if img := p.MainImage(); img == nil || img.ID != 7 {
+		t.Errorf("expected the first image as main image, got %+v", img)
+	}
+
+	if r := p.Rating(); r != 0 {
+		t.Errorf("expected no rating, got %v", r)
+	}
+	p.AverageRating, p.RatingCount = "4.50", 2
+	if r := p.Rating(); r != 4.5 {
+		t.Errorf("expected rating 4.5, got %v", r)
+	}
+}
+
+func TestAttributeKey(t *testing.T) {
+	tests := []struct {
+		attr Attribute
+		want string
+	}{
+		{Attribute{ID: 3, Name: "Color", Slug: "pa_colour"}, "pa_colour"},
+		{Attribute{ID: 1, Name: "Grind Size"}, "pa_grind-size"},
+		{Attribute{Name: "Gift wrap?"}, "gift-wrap"},
+		{Attribute{Name: "  Mug -  Handle_Side "}, "mug-handle_side"},
+	}
+	for _, tt := range tests {
+		if got := tt.attr.Key(); got != tt.want {
+			t.Errorf("%+v: got key %q, want %q", tt.attr, got, tt.want)
+		}
+	}
 }
 
 func TestVariationMethods(t *testing.T) {
@@ -304,5 +392,198 @@ func TestVariationMethods(t *testing.T) {
 	}
 }
 
+func TestGetProductsByCategory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("category") != "15" {
+			t.Errorf("expected category=15, got %s", r.URL.Query().Get("category"))
+		}
+		if r.URL.Query().Get("tag") != "" {
+			t.Errorf("expected no tag filter, got %s", r.URL.Query().Get("tag"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Product{
+			{ID: 1, Name: "Ethiopian", Categories: []ProductTerm{{ID: 15, Name: "Single Origin", Slug: "single-origin"}}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	page, err := client.GetProducts(context.Background(), GetProductsParams{Category: 15})
+	if err != nil {
+		t.Fatalf("GetProducts by category failed: %v", err)
+	}
+	if !page.Products[0].HasCategory(15) {
+		t.Error("expected product to be in category 15")
+	}
+	if page.Products[0].HasCategory(16) {
+		t.Error("did not expect product to be in category 16")
+	}
+}
+
+func TestGetCategories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wp-json/wc/v3/products/categories" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("hide_empty") != "true" {
+			t.Errorf("expected hide_empty=true, got %s", r.URL.Query().Get("hide_empty"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Category{
+			{ID: 15, Name: "Single Origin", Slug: "single-origin", Count: 3},
+			{ID: 17, Name: "Decaf", Slug: "decaf", Count: 1},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	categories, err := client.GetCategories(context.Background(), GetTermsParams{HideEmpty: true})
+	if err != nil {
+		t.Fatalf("GetCategories failed: %v", err)
+	}
+	if len(categories) != 2 || categories[1].Name != "Decaf" {
+		t.Errorf("unexpected categories: %+v", categories)
+	}
+}
+
+func TestGetTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wp-json/wc/v3/products/tags" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Tag{{ID: 20, Name: "Fruity", Slug: "fruity"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	tags, err := client.GetTags(context.Background(), GetTermsParams{})
+	if err != nil {
+		t.Fatalf("GetTags failed: %v", err)
+	}
+	if len(tags) != 1 || tags[0].Slug != "fruity" {
+		t.Errorf("unexpected tags: %+v", tags)
+	}
+}
+
+func TestGetProductsSortingAndFilters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		expected := map[string]string{
+			"orderby":   "price",
+			"order":     "asc",
+			"min_price": "10",
+			"max_price": "25.50",
+			"on_sale":   "true",
+			"featured":  "true",
+			"sku":       "ETH-250",
+			"include":   "1,2,3",
+			"exclude":   "4",
+		}
+		for key, want := range expected {
+			if got := query.Get(key); got != want {
+				t.Errorf("expected %s=%s, got %s", key, want, got)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Product{})
+	}))
+	defer server.Close()
 
+	client := NewClient(server.URL)
+	_, err := client.GetProducts(context.Background(), GetProductsParams{
+		OrderBy:  OrderByPrice,
+		Order:    "asc",
+		MinPrice: "10",
+		MaxPrice: "25.50",
+		OnSale:   true,
+		Featured: true,
+		SKU:      "ETH-250",
+		Include:  []int{1, 2, 3},
+		Exclude:  []int{4},
+	})
+	if err != nil {
+		t.Fatalf("GetProducts with filters failed: %v", err)
+	}
+}
 
+func TestGetProductsOmitsUnsetFilters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, key := range []string{"orderby", "order", "min_price", "max_price", "on_sale", "featured", "sku", "include", "exclude"} {
+			if r.URL.Query().Has(key) {
+				t.Errorf("expected %s to be omitted", key)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Product{})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	if _, err := client.GetProducts(context.Background(), GetProductsParams{}); err != nil {
+		t.Fatalf("GetProducts failed: %v", err)
+	}
+}
+
+func TestGetAvailablePaymentGateways(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wp-json/wc/v3/payment_gateways" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]PaymentGateway{
+			{ID: "cod", Title: "Cash on delivery", Order: 2, Enabled: true},
+			{ID: "cheque", Title: "Check payments", Order: 1, Enabled: false},
+			{ID: "stripe", Title: "Credit card", Order: 0, Enabled: true, NeedsSetup: true},
+			{ID: "bacs", Title: "Direct bank transfer", Order: 1, Enabled: true},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	gateways, err := client.GetAvailablePaymentGateways(context.Background())
+	if err != nil {
+		t.Fatalf("GetAvailablePaymentGateways failed: %v", err)
+	}
+
+	if len(gateways) != 2 {
+		t.Fatalf("expected 2 available gateways, got %d", len(gateways))
+	}
+	if gateways[0].ID != "bacs" || gateways[1].ID != "cod" {
+		t.Errorf("expected bacs then cod, got %s then %s", gateways[0].ID, gateways[1].ID)
+	}
+}
+
+func TestGatewayOrderFormats(t *testing.T) {
+	// WooCommerce sends "" for gateways the shop never sorted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id": "paypal", "title": "PayPal", "order": "", "enabled": true},
+			{"id": "cod", "title": "Cash on delivery", "order": "2", "enabled": true},
+			{"id": "bacs", "title": "Direct bank transfer", "order": 1, "enabled": true}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	gateways, err := client.GetAvailablePaymentGateways(context.Background())
+	if err != nil {
+		t.Fatalf("GetAvailablePaymentGateways failed: %v", err)
+	}
+
+	var ids []string
+	for _, g := range gateways {
+		ids = append(ids, g.ID)
+	}
+	if got := strings.Join(ids, ","); got != "bacs,cod,paypal" {
+		t.Errorf("expected bacs,cod,paypal, got %s", got)
+	}
+	if gateways[2].Order != Unordered {
+		t.Errorf("expected \"\" to decode as Unordered, got %d", gateways[2].Order)
+	}
+}
@@ -0,0 +1,346 @@
+package woo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Coupon errors returned by GetCouponByCode and Coupon.Validate.
+var (
+	ErrCouponNotFound      = errors.New("coupon does not exist")
+	ErrCouponExpired       = errors.New("coupon has expired")
+	ErrCouponUsageLimit    = errors.New("coupon usage limit reached")
+	ErrCouponMinimumSpend  = errors.New("cart is below the coupon minimum spend")
+	ErrCouponMaximumSpend  = errors.New("cart is above the coupon maximum spend")
+	ErrCouponNotApplicable = errors.New("coupon does not apply to the cart")
+)
+
+// Coupon discount types.
+const (
+	DiscountPercent      = "percent"       // Percentage off each eligible item
+	DiscountFixedCart    = "fixed_cart"    // Fixed amount off the eligible items
+	DiscountFixedProduct = "fixed_product" // Fixed amount off each eligible unit
+)
+
+// couponDateLayout is the format of WooCommerce dates without a zone.
+const couponDateLayout = "2006-01-02T15:04:05"
+
+// Coupon is a WooCommerce coupon with its restrictions.
+type Coupon struct {
+	ID                        int      `json:"id"`
+	Code                      string   `json:"code"`
+	Amount                    string   `json:"amount"` // Percentage or fixed amount, see DiscountType
+	DiscountType              string   `json:"discount_type"`
+	Description               string   `json:"description"`
+	DateExpiresGMT            *string  `json:"date_expires_gmt"` // nil if the coupon never expires
+	UsageCount                int      `json:"usage_count"`
+	UsageLimit                *int     `json:"usage_limit"` // nil for unlimited
+	ProductIDs                []int    `json:"product_ids"`
+	ExcludedProductIDs        []int    `json:"excluded_product_ids"`
+	ProductCategories         []int    `json:"product_categories"`
+	ExcludedProductCategories []int    `json:"excluded_product_categories"`
+	ExcludeSaleItems          bool     `json:"exclude_sale_items"`
+	MinimumAmount             string   `json:"minimum_amount"` // "" or "0.00" for no minimum
+	MaximumAmount             string   `json:"maximum_amount"`
+	LimitUsageToXItems        *int     `json:"limit_usage_to_x_items"` // Units discounted, nil for all
+	FreeShipping              bool     `json:"free_shipping"`          // Enables free shipping methods that require a coupon
+	EmailRestrictions         []string `json:"email_restrictions"`     // Checked by the store when the order is placed
+}
+
+// CouponItem is a cart line a coupon may apply to.
+type CouponItem struct {
+	ProductID   int
+	VariationID int
+	CategoryIDs []int
+	OnSale      bool
+	Price       Money // Unit price
+	Quantity    int
+}
+
+// total returns the unit price times the quantity.
+func (item CouponItem) total() Money {
+	return item.Price.Mul(item.Quantity)
+}
+
+// sumItems adds up the totals of the items at the given indexes.
+func sumItems(items []CouponItem, indexes []int) (Money, error) {
+	sum := Money{}
+	for _, i := range indexes {
+		var err error
+		if sum, err = sum.Add(items[i].total()); err != nil {
+			return Money{}, err
+		}
+	}
+	return sum, nil
+}
+
+// allItems returns the indexes of every item, for sumItems.
+func allItems(items []CouponItem) []int {
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// CouponLine is a coupon applied to an order.
+type CouponLine struct {
+	Code string `json:"code"`
+}
+
+// GetCouponByCode looks up a coupon by its code. Codes are not case-sensitive.
+// It returns ErrCouponNotFound if there is no such coupon.
+func (c *Client) GetCouponByCode(ctx context.Context, code string) (*Coupon, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return nil, ErrCouponNotFound
+	}
+
+	query := url.Values{}
+	query.Set("code", code)
+	var coupons []Coupon
+	if err := c.doRequest(ctx, "/wp-json/wc/v3/coupons", query, &coupons); err != nil {
+		return nil, fmt.Errorf("fetching coupon %q: %w", code, err)
+	}
+	for i := range coupons {
+		if strings.EqualFold(coupons[i].Code, code) {
+			return &coupons[i], nil
+		}
+	}
+	return nil, fmt.Errorf("coupon %q: %w", code, ErrCouponNotFound)
+}
+
+// Validate checks the coupon against the cart the way WooCommerce does:
+// expiry, usage limit, minimum and maximum spend, and product and category
+// restrictions. Email restrictions and per-customer limits are left to the
+// store, which checks them when the order is placed.
+func (cp *Coupon) Validate(items []CouponItem, now time.Time) error {
+	if cp.DateExpiresGMT != nil && *cp.DateExpiresGMT != "" {
+		expires, err := time.Parse(couponDateLayout, *cp.DateExpiresGMT)
+		if err != nil {
+			return fmt.Errorf("coupon %q: invalid expiry date %q: %w", cp.Code, *cp.DateExpiresGMT, err)
+		}
+		if !now.UTC().Before(expires) {
+			return fmt.Errorf("coupon %q: %w", cp.Code, ErrCouponExpired)
+		}
+	}
+
+	if cp.UsageLimit != nil && *cp.UsageLimit > 0 && cp.UsageCount >= *cp.UsageLimit {
+		return fmt.Errorf("coupon %q: %w", cp.Code, ErrCouponUsageLimit)
+	}
+
+	subtotal, err := sumItems(items, allItems(items))
+	if err != nil {
+		return fmt.Errorf("coupon %q: %w", cp.Code, err)
+	}
+	// The limits are parsed in the subtotal's currency, so comparing can't fail
+	if limit, ok, err := cp.spendLimit(cp.MinimumAmount, subtotal.Currency()); err != nil {
+		return err
+	} else if less, _ := subtotal.LessThan(limit); ok && less {
+		return fmt.Errorf("coupon %q: minimum spend %s: %w", cp.Code, limit, ErrCouponMinimumSpend)
+	}
+	if limit, ok, err := cp.spendLimit(cp.MaximumAmount, subtotal.Currency()); err != nil {
+		return err
+	} else if more, _ := limit.LessThan(subtotal); ok && more {
+		return fmt.Errorf("coupon %q: maximum spend %s: %w", cp.Code, limit, ErrCouponMaximumSpend)
+	}
+
+	eligible := false
+	for _, item := range items {
+		if cp.appliesTo(item) {
+			eligible = true
+		} else if cp.DiscountType == DiscountFixedCart && cp.excludes(item) {
+			// Cart discounts can't be used at all with excluded items in the cart
+			return fmt.Errorf("coupon %q: %w", cp.Code, ErrCouponNotApplicable)
+		}
+	}
+	if !eligible {
+		return fmt.Errorf("coupon %q: %w", cp.Code, ErrCouponNotApplicable)
+	}
+	return nil
+}
+
+// spendLimit parses a minimum or maximum amount. ok is false when unset.
+func (cp *Coupon) spendLimit(amount, currency string) (Money, bool, error) {
+	if amount == "" {
+		return Money{}, false, nil
+	}
+	limit, err := ParseMoney(amount, currency)
+	if err != nil {
+		return Money{}, false, fmt.Errorf("coupon %q: %w", cp.Code, err)
+	}
+	return limit, !limit.IsZero(), nil
+}
+
+// appliesTo reports whether an item is eligible for the discount. Coupons
+// without product or category restrictions apply to every item.
+func (cp *Coupon) appliesTo(item CouponItem) bool {
+	if cp.excludes(item) {
+		return false
+	}
+	if len(cp.ProductIDs) == 0 && len(cp.ProductCategories) == 0 {
+		return true
+	}
+	return containsID(cp.ProductIDs, item.ProductID) ||
+		(item.VariationID != 0 && containsID(cp.ProductIDs, item.VariationID)) ||
+		intersects(cp.ProductCategories, item.CategoryIDs)
+}
+
+// excludes reports whether an item is explicitly excluded from the coupon.
+func (cp *Coupon) excludes(item CouponItem) bool {
+	return (cp.ExcludeSaleItems && item.OnSale) ||
+		containsID(cp.ExcludedProductIDs, item.ProductID) ||
+		(item.VariationID != 0 && containsID(cp.ExcludedProductIDs, item.VariationID)) ||
+		intersects(cp.ExcludedProductCategories, item.CategoryIDs)
+}
+
+// Discounts returns the discount on each item, in the order given.
+// It does not validate the coupon; call Validate first.
+func (cp *Coupon) Discounts(items []CouponItem) ([]Money, error) {
+	// Past this check every price is in one currency, so comparing them
+	// can't fail
+	if _, err := sumItems(items, allItems(items)); err != nil {
+		return nil, fmt.Errorf("coupon %q: %w", cp.Code, err)
+	}
+	discounts := make([]Money, len(items))
+	currency := ""
+	for i, item := range items {
+		currency = item.Price.Currency()
+		discounts[i] = ZeroMoney(currency)
+	}
+
+	// Most expensive items first, which matters when the units are limited
+	var eligible []int
+	for i, item := range items {
+		if cp.appliesTo(item) {
+			eligible = append(eligible, i)
+		}
+	}
+	sort.SliceStable(eligible, func(a, b int) bool {
+		less, _ := items[eligible[b]].Price.LessThan(items[eligible[a]].Price)
+		return less
+	})
+
+	switch cp.DiscountType {
+	case DiscountPercent:
+		ppm, err := parsePercent(cp.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("coupon %q: %w", cp.Code, err)
+		}
+		if ppm > 1000000 {
+			ppm = 1000000
+		}
+		units := cp.unitLimit()
+		for _, i := range eligible {
+			n := limitUnits(items[i].Quantity, &units)
+			discounts[i] = items[i].Price.Mul(n).MulRatio(ppm, 1000000)
+		}
+
+	case DiscountFixedProduct:
+		amount, err := ParseMoney(cp.Amount, currency)
+		if err != nil {
+			return nil, fmt.Errorf("coupon %q: %w", cp.Code, err)
+		}
+		units := cp.unitLimit()
+		for _, i := range eligible {
+			perUnit := amount
+			if less, _ := items[i].Price.LessThan(perUnit); less {
+				perUnit = items[i].Price
+			}
+			discounts[i] = perUnit.Mul(limitUnits(items[i].Quantity, &units))
+		}
+
+	case DiscountFixedCart:
+		amount, err := ParseMoney(cp.Amount, currency)
+		if err != nil {
+			return nil, fmt.Errorf("coupon %q: %w", cp.Code, err)
+		}
+		if err := spreadDiscount(amount, items, eligible, discounts); err != nil {
+			return nil, fmt.Errorf("coupon %q: %w", cp.Code, err)
+		}
+
+	default:
+		return nil, fmt.Errorf("coupon %q: unsupported discount type %q", cp.Code, cp.DiscountType)
+	}
+
+	return discounts, nil
+}
+
+// unitLimit returns how many units may be discounted, -1 for all.
+func (cp *Coupon) unitLimit() int {
+	if cp.LimitUsageToXItems == nil || *cp.LimitUsageToXItems <= 0 {
+		return -1
+	}
+	return *cp.LimitUsageToXItems
+}
+
+// limitUnits takes up to quantity units from the remaining allowance.
+func limitUnits(quantity int, remaining *int) int {
+	if *remaining < 0 {
+		return quantity
+	}
+	if quantity > *remaining {
+		quantity = *remaining
+	}
+	*remaining -= quantity
+	return quantity
+}
+
+// spreadDiscount splits a fixed cart discount across the eligible items in
+// proportion to their totals. The last item takes the rounding remainder,
+// so the discounts add up to the amount exactly.
+func spreadDiscount(amount Money, items []CouponItem, eligible []int, discounts []Money) error {
+	total, err := sumItems(items, eligible)
+	if err != nil {
+		return err
+	}
+	if less, err := total.LessThan(amount); err != nil {
+		return err
+	} else if less {
+		amount = total
+	}
+	if total.IsZero() {
+		return nil
+	}
+
+	decimals := max(amount.Decimals(), total.Decimals())
+	denominator := total.Round(decimals).Minor()
+	remaining := amount
+	for n, i := range eligible {
+		if n == len(eligible)-1 {
+			discounts[i] = remaining
+			break
+		}
+		share := amount.MulRatio(items[i].total().Round(decimals).Minor(), denominator)
+		discounts[i] = share
+		if remaining, err = remaining.Sub(share); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsID reports whether ids contains id.
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// intersects reports whether a and b have an ID in common.
+func intersects(a, b []int) bool {
+	for _, id := range b {
+		if containsID(a, id) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testCouponItems is a cart with two single origins (category 15), one on
+// sale, and a blend (category 16).
+func testCouponItems() []CouponItem {
+	return []CouponItem{
+		{ProductID: 1, CategoryIDs: []int{15}, Price: NewMoney(1899, 2, "EUR"), Quantity: 2},
+		{ProductID: 2, CategoryIDs: []int{15}, OnSale: true, Price: NewMoney(1650, 2, "EUR"), Quantity: 1},
+		{ProductID: 101, VariationID: 1012, CategoryIDs: []int{16}, Price: NewMoney(4999, 2, "EUR"), Quantity: 1},
+	}
+}
+
+func TestCouponValidate(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	past, future := "2026-05-31T23:59:59", "2026-12-31T00:00:00"
+	limit := 100
+
+	tests := []struct {
+		name   string
+		coupon Coupon
+		want   error
+	}{
+		{"valid", Coupon{Code: "ok", DiscountType: DiscountPercent, Amount: "10", DateExpiresGMT: &future}, nil},
+		{"expired", Coupon{Code: "old", DiscountType: DiscountPercent, Amount: "10", DateExpiresGMT: &past}, ErrCouponExpired},
+		{"used up", Coupon{Code: "gone", DiscountType: DiscountPercent, Amount: "10", UsageLimit: &limit, UsageCount: 100}, ErrCouponUsageLimit},
+		{"minimum spend", Coupon{Code: "big", DiscountType: DiscountPercent, Amount: "10", MinimumAmount: "150.00"}, ErrCouponMinimumSpend},
+		{"no minimum", Coupon{Code: "zero", DiscountType: DiscountPercent, Amount: "10", MinimumAmount: "0.00"}, nil},
+		{"maximum spend", Coupon{Code: "small", DiscountType: DiscountPercent, Amount: "10", MaximumAmount: "50"}, ErrCouponMaximumSpend},
+		{"category", Coupon{Code: "decaf", DiscountType: DiscountPercent, Amount: "10", ProductCategories: []int{17}}, ErrCouponNotApplicable},
+		{"variation", Coupon{Code: "kilo", DiscountType: DiscountFixedProduct, Amount: "5", ProductIDs: []int{1012}}, nil},
+		{"percent with excluded item", Coupon{Code: "nosale", DiscountType: DiscountPercent, Amount: "10", ExcludeSaleItems: true}, nil},
+		{"cart with excluded item", Coupon{Code: "nosale", DiscountType: DiscountFixedCart, Amount: "10", ExcludeSaleItems: true}, ErrCouponNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.coupon.Validate(testCouponItems(), now)
+			if tt.want == nil && err != nil {
+				t.Errorf("expected the coupon to be valid, got %v", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCouponDiscounts(t *testing.T) {
+	one := 1
+
+	tests := []struct {
+		name   string
+		coupon Coupon
+		want   []string
+	}{
+		{"percent", Coupon{DiscountType: DiscountPercent, Amount: "10"}, []string{"3.80", "1.65", "5.00"}},
+		{"percent of single origins", Coupon{DiscountType: DiscountPercent, Amount: "10", ProductCategories: []int{15}}, []string{"3.80", "1.65", "0.00"}},
+		{"percent excluding sale items", Coupon{DiscountType: DiscountPercent, Amount: "10", ExcludeSaleItems: true}, []string{"3.80", "0.00", "5.00"}},
+		{"fixed product", Coupon{DiscountType: DiscountFixedProduct, Amount: "5"}, []string{"10.00", "5.00", "5.00"}},
+		{"fixed product on the priciest unit", Coupon{DiscountType: DiscountFixedProduct, Amount: "5", LimitUsageToXItems: &one}, []string{"0.00", "0.00", "5.00"}},
+		{"fixed cart", Coupon{DiscountType: DiscountFixedCart, Amount: "10"}, []string{"3.64", "1.57", "4.79"}},
+		{"fixed cart above the total", Coupon{DiscountType: DiscountFixedCart, Amount: "500"}, []string{"37.98", "16.50", "49.99"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discounts, err := tt.coupon.Discounts(testCouponItems())
+			if err != nil {
+				t.Fatalf("Discounts failed: %v", err)
+			}
+			for i, want := range tt.want {
+				if discounts[i].String() != want {
+					t.Errorf("item %d: expected %s, got %s", i, want, discounts[i])
+				}
+			}
+		})
+	}
+
+	if _, err := (&Coupon{DiscountType: "sign_up_fee", Amount: "5"}).Discounts(testCouponItems()); err == nil {
+		t.Error("expected an error for an unsupported discount type")
+	}
+}
+
+func TestGetCouponByCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		coupons := []Coupon{}
+		if r.URL.Query().Get("code") == "benvenuto10" {
+			coupons = append(coupons, Coupon{ID: 7, Code: "benvenuto10", DiscountType: DiscountPercent, Amount: "10.00"})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(coupons)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	coupon, err := client.GetCouponByCode(context.Background(), "  BENVENUTO10 ")
+	if err != nil {
+		t.Fatalf("GetCouponByCode failed: %v", err)
+	}
+	if coupon.ID != 7 || coupon.DiscountType != DiscountPercent {
+		t.Errorf("unexpected coupon %+v", coupon)
+	}
+
+	if _, err := client.GetCouponByCode(context.Background(), "nope"); !errors.Is(err, ErrCouponNotFound) {
+		t.Errorf("expected ErrCouponNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package woo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Customer is a WooCommerce customer account.
+type Customer struct {
+	ID               int            `json:"id"`
+	Email            string         `json:"email"`
+	FirstName        string         `json:"first_name"`
+	LastName         string         `json:"last_name"`
+	Username         string         `json:"username"`
+	Billing          BillingAddress `json:"billing"`
+	Shipping         BillingAddress `json:"shipping"` // Email is not used for shipping
+	IsPayingCustomer bool           `json:"is_paying_customer"`
+}
+
+// CustomerRequest creates or updates a customer. Empty fields and nil
+// addresses are left unchanged by an update.
+type CustomerRequest struct {
+	Email     string          `json:"email,omitempty"`
+	FirstName string          `json:"first_name,omitempty"`
+	LastName  string          `json:"last_name,omitempty"`
+	Username  string          `json:"username,omitempty"` // Generated by the store if empty
+	Billing   *BillingAddress `json:"billing,omitempty"`
+	Shipping  *BillingAddress `json:"shipping,omitempty"`
+}
+
+// GetCustomer fetches a customer by ID.
+func (c *Client) GetCustomer(ctx context.Context, id int) (*Customer, error) {
+	endpoint := fmt.Sprintf("/wp-json/wc/v3/customers/%d", id)
+
+	var customer Customer
+	if err := c.doRequest(ctx, endpoint, nil, &customer); err != nil {
+		return nil, fmt.Errorf("fetching customer %d: %w", id, err)
+	}
+	return &customer, nil
+}
+
+// CreateCustomer registers a new customer. The store emails the account
+// details to the customer. It returns ErrEmailExists if the email address
+// already belongs to an account.
+func (c *Client) CreateCustomer(ctx context.Context, req CustomerRequest) (*Customer, error) {
+	var customer Customer
+	if err := c.doPostRequest(ctx, "/wp-json/wc/v3/customers", req, &customer); err != nil {
+		return nil, fmt.Errorf("creating customer: %w", err)
+	}
+	return &customer, nil
+}
+
+// UpdateCustomer changes a customer's details, typically the billing and
+// shipping addresses.
+func (c *Client) UpdateCustomer(ctx context.Context, id int, req CustomerRequest) (*Customer, error) {
+	endpoint := fmt.Sprintf("/wp-json/wc/v3/customers/%d", id)
+
+	var customer Customer
+	if _, err := c.doJSON(ctx, http.MethodPut, endpoint, nil, req, &customer); err != nil {
+		return nil, fmt.Errorf("updating customer %d: %w", id, err)
+	}
+	return &customer, nil
+}
@@ -0,0 +1,68 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCustomerRequests(t *testing.T) {
+	var updated CustomerRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/wp-json/wc/v3/customers/7":
+			json.NewEncoder(w).Encode(Customer{ID: 7, Email: "ada@example.com", Shipping: BillingAddress{City: "Roma"}})
+		case r.Method == http.MethodPost && r.URL.Path == "/wp-json/wc/v3/customers":
+			var req CustomerRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Email == "taken@example.com" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":"registration-error-email-exists","message":"An account is already registered with your email address.","data":{"status":400}}`))
+				return
+			}
+			json.NewEncoder(w).Encode(Customer{ID: 8, Email: req.Email})
+		case r.Method == http.MethodPut && r.URL.Path == "/wp-json/wc/v3/customers/7":
+			json.NewDecoder(r.Body).Decode(&updated)
+			json.NewEncoder(w).Encode(Customer{ID: 7, Shipping: *updated.Shipping})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"woocommerce_rest_invalid_id","message":"Invalid resource ID.","data":{"status":404}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	customer, err := client.GetCustomer(ctx, 7)
+	if err != nil {
+		t.Fatalf("GetCustomer failed: %v", err)
+	}
+	if customer.Email != "ada@example.com" || customer.Shipping.City != "Roma" {
+		t.Errorf("unexpected customer %+v", customer)
+	}
+	if _, err := client.GetCustomer(ctx, 99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	created, err := client.CreateCustomer(ctx, CustomerRequest{Email: "new@example.com"})
+	if err != nil || created.ID != 8 {
+		t.Fatalf("expected customer 8 to be created, got %+v, %v", created, err)
+	}
+	if _, err := client.CreateCustomer(ctx, CustomerRequest{Email: "taken@example.com"}); !errors.Is(err, ErrEmailExists) {
+		t.Errorf("expected ErrEmailExists, got %v", err)
+	}
+
+	shipping := BillingAddress{FirstName: "Ada", City: "Milano", Country: "IT"}
+	customer, err = client.UpdateCustomer(ctx, 7, CustomerRequest{Shipping: &shipping})
+	if err != nil {
+		t.Fatalf("UpdateCustomer failed: %v", err)
+	}
+	if updated.Billing != nil || customer.Shipping.City != "Milano" {
+		t.Errorf("expected only the shipping address to be sent, got %+v", updated)
+	}
+}
@@ -0,0 +1,110 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for common WooCommerce API failures.
+// Use errors.Is to check an error returned by the client against them.
+var (
+	ErrNotFound     = errors.New("resource not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrInvalidParam = errors.New("invalid parameter")
+	ErrOutOfStock   = errors.New("product out of stock")
+	ErrEmailExists  = errors.New("email address already registered")
+)
+
+// APIError is a non-2xx response from the WooCommerce REST API.
+// WooCommerce returns errors as {"code": ..., "message": ..., "data": {"status": ...}}.
+type APIError struct {
+	StatusCode int           // HTTP status code of the response
+	Code       string        `json:"code"`    // e.g. "woocommerce_rest_product_invalid_id"
+	Message    string        `json:"message"` // Human-readable message from WooCommerce
+	Data       APIErrorData  `json:"data"`
+	Body       string        // Raw response body, kept when it is not WooCommerce JSON
+	RetryAfter time.Duration // From the Retry-After header, zero if absent
+}
+
+// APIErrorData holds the "data" object of a WooCommerce error response.
+type APIErrorData struct {
+	Status int               `json:"status"`
+	Params map[string]string `json:"params,omitempty"` // Set for rest_invalid_param
+}
+
+// newAPIError builds an APIError from a response status and body.
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+	if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Code == "" {
+		apiErr.Code = ""
+		apiErr.Message = ""
+		apiErr.Body = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("API error (status %d): %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Is reports whether the error matches one of the package sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound ||
+			strings.HasSuffix(e.Code, "_invalid_id") ||
+			e.Code == "woocommerce_rest_invalid_product_id" ||
+			e.Code == "rest_no_route"
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized ||
+			e.StatusCode == http.StatusForbidden ||
+			strings.HasPrefix(e.Code, "woocommerce_rest_cannot_") ||
+			e.Code == "woocommerce_rest_authentication_error"
+	case ErrInvalidParam:
+		return e.Code == "rest_invalid_param" ||
+			e.Code == "rest_missing_callback_param"
+	case ErrOutOfStock:
+		return e.isOutOfStock()
+	case ErrEmailExists:
+		return e.Code == "registration-error-email-exists"
+	}
+	return false
+}
+
+// isOutOfStock detects stock failures during order creation. WooCommerce and
+// stock plugins do not agree on a single code, so the message is checked too.
+func (e *APIError) isOutOfStock() bool {
+	if strings.Contains(e.Code, "out_of_stock") || strings.Contains(e.Code, "insufficient_stock") {
+		return true
+	}
+	msg := strings.ToLower(e.Message)
+	return strings.Contains(msg, "out of stock") ||
+		strings.Contains(msg, "not enough stock") ||
+		strings.Contains(msg, "insufficient stock")
+}
+
+// MayHaveSucceeded reports whether a failed write may still have been
+// carried out by the store: the request timed out, the connection failed
+// or the server answered with a 5xx. Other API errors mean the store
+// rejected the request.
+func MayHaveSucceeded(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr)
+}
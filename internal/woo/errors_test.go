@@ -0,0 +1,149 @@
+package woo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIErrorParsesWooCommerceJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"woocommerce_rest_product_invalid_id","message":"Invalid ID.","data":{"status":404}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	_, err := client.GetVariations(context.Background(), 999)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.Code != "woocommerce_rest_product_invalid_id" {
+		t.Errorf("unexpected code %q", apiErr.Code)
+	}
+	if apiErr.Message != "Invalid ID." {
+		t.Errorf("unexpected message %q", apiErr.Message)
+	}
+	if apiErr.Data.Status != 404 {
+		t.Errorf("expected data.status 404, got %d", apiErr.Data.Status)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("expected errors.Is(err, ErrNotFound)")
+	}
+	if errors.Is(err, ErrUnauthorized) {
+		t.Error("did not expect errors.Is(err, ErrUnauthorized)")
+	}
+}
+
+func TestAPIErrorNonJSONBody(t *testing.T) {
+	apiErr := newAPIError(http.StatusBadGateway, []byte("<html>Bad Gateway</html>\n"))
+
+	if apiErr.Code != "" {
+		t.Errorf("expected empty code, got %q", apiErr.Code)
+	}
+	if apiErr.Body != "<html>Bad Gateway</html>" {
+		t.Errorf("expected raw body to be kept, got %q", apiErr.Body)
+	}
+	if !strings.Contains(apiErr.Error(), "502") {
+		t.Errorf("expected error to contain status code, got %q", apiErr.Error())
+	}
+}
+
+func TestAPIErrorSentinels(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		target error
+	}{
+		{
+			name:   "unauthorized",
+			status: http.StatusUnauthorized,
+			body:   `{"code":"woocommerce_rest_cannot_view","message":"Sorry, you cannot list resources.","data":{"status":401}}`,
+			target: ErrUnauthorized,
+		},
+		{
+			name:   "invalid param",
+			status: http.StatusBadRequest,
+			body:   `{"code":"rest_invalid_param","message":"Invalid parameter(s): per_page","data":{"status":400,"params":{"per_page":"per_page must be between 1 (inclusive) and 100 (inclusive)"}}}`,
+			target: ErrInvalidParam,
+		},
+		{
+			name:   "out of stock",
+			status: http.StatusBadRequest,
+			body:   `{"code":"woocommerce_rest_invalid_item","message":"Ethiopian Yirgacheffe is out of stock and cannot be purchased.","data":{"status":400}}`,
+			target: ErrOutOfStock,
+		},
+		{
+			name:   "email exists",
+			status: http.StatusBadRequest,
+			body:   `{"code":"registration-error-email-exists","message":"An account is already registered with your email address.","data":{"status":400}}`,
+			target: ErrEmailExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := error(newAPIError(tt.status, []byte(tt.body)))
+			if !errors.Is(err, tt.target) {
+				t.Errorf("expected errors.Is(%v, %v)", err, tt.target)
+			}
+			if errors.Is(err, ErrNotFound) {
+				t.Errorf("did not expect errors.Is(%v, ErrNotFound)", err)
+			}
+		})
+	}
+}
+
+func TestCreateOrderOutOfStock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"woocommerce_rest_product_out_of_stock","message":"Sorry, not enough stock.","data":{"status":400}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	_, err := client.CreateOrder(context.Background(), OrderRequest{})
+	if !errors.Is(err, ErrOutOfStock) {
+		t.Fatalf("expected ErrOutOfStock, got %v", err)
+	}
+	if strings.Contains(err.Error(), "{") {
+		t.Errorf("expected error message without raw JSON, got %q", err.Error())
+	}
+}
+
+func TestMayHaveSucceeded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	// The store got the request but we gave up waiting for the answer
+	client := NewClient(server.URL, WithHTTPClient(&http.Client{Timeout: 10 * time.Millisecond}))
+	_, err := client.CreateOrder(context.Background(), OrderRequest{})
+	if err == nil || !MayHaveSucceeded(err) {
+		t.Errorf("expected a timeout to be ambiguous, got %v", err)
+	}
+
+	if !MayHaveSucceeded(&APIError{StatusCode: http.StatusBadGateway}) {
+		t.Error("expected a 502 to be ambiguous")
+	}
+	if MayHaveSucceeded(&APIError{StatusCode: http.StatusBadRequest, Code: "woocommerce_rest_product_out_of_stock"}) {
+		t.Error("expected a 400 to be a rejection")
+	}
+	if MayHaveSucceeded(nil) {
+		t.Error("expected nil not to be ambiguous")
+	}
+}
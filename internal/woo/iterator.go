@@ -0,0 +1,199 @@
+package woo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// maxPerPage is the largest page size the WooCommerce REST API accepts.
+const maxPerPage = 100
+
+// Iterator streams items from a paginated WooCommerce list endpoint.
+// The next page is fetched in the background while the current one is
+// being consumed. Callers must call Close when they stop iterating early.
+//
+//	it := client.AllProducts(ctx, woo.GetProductsParams{})
+//	defer it.Close()
+//	for it.Next() {
+//		p := it.Value()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator[T any] struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	pages    chan pageResult[T]
+	items    []T
+	current  T
+	pageInfo PageInfo
+	err      error
+	closed   bool
+}
+
+// pageResult is a single fetched page handed from the fetcher goroutine.
+type pageResult[T any] struct {
+	items []T
+	info  PageInfo
+	err   error
+}
+
+// pageFetcher fetches one page of a list endpoint.
+type pageFetcher[T any] func(ctx context.Context, page int) ([]T, PageInfo, error)
+
+// newIterator starts fetching pages from startPage in the background.
+func newIterator[T any](ctx context.Context, startPage int, fetch pageFetcher[T]) *Iterator[T] {
+	if startPage < 1 {
+		startPage = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	it := &Iterator[T]{
+		ctx:    ctx,
+		cancel: cancel,
+		pages:  make(chan pageResult[T]),
+	}
+	go it.fetchPages(startPage, fetch)
+	return it
+}
+
+// fetchPages fetches pages until the last one, an error, or cancellation.
+// The channel is unbuffered, so at most one page is fetched ahead of the
+// page the caller is currently reading.
+func (it *Iterator[T]) fetchPages(page int, fetch pageFetcher[T]) {
+	defer close(it.pages)
+	for it.ctx.Err() == nil {
+		items, info, err := fetch(it.ctx, page)
+		select {
+		case it.pages <- pageResult[T]{items: items, info: info, err: err}:
+		case <-it.ctx.Done():
+			return
+		}
+		if err != nil || len(items) == 0 || !info.HasNext() {
+			return
+		}
+		page++
+	}
+}
+
+// Next advances to the next item. It returns false when there are no
+// more items, an error occurred, or the context was cancelled. The context
+// is checked for every item, so cancelling stops the iteration straight
+// away rather than at the end of the page.
+func (it *Iterator[T]) Next() bool {
+	if it.closed || it.err != nil {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		it.items = nil
+		return false
+	}
+	for len(it.items) == 0 {
+		res, ok := <-it.pages
+		if !ok {
+			// Fetcher stopped: either finished or cancelled mid-fetch
+			if err := it.ctx.Err(); err != nil {
+				it.err = err
+			}
+			it.cancel()
+			return false
+		}
+		if res.err != nil {
+			it.err = res.err
+			it.cancel()
+			return false
+		}
+		it.items = res.items
+		it.pageInfo = res.info
+	}
+
+	it.current = it.items[0]
+	it.items = it.items[1:]
+	return true
+}
+
+// Value returns the current item. It is only valid after Next returns true.
+func (it *Iterator[T]) Value() T {
+	return it.current
+}
+
+// PageInfo returns the pagination metadata of the most recently fetched page.
+func (it *Iterator[T]) PageInfo() PageInfo {
+	return it.pageInfo
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Close stops the background fetcher. It is safe to call more than once.
+func (it *Iterator[T]) Close() {
+	it.closed = true
+	it.cancel()
+}
+
+// collect drains an iterator into a slice.
+func collect[T any](it *Iterator[T]) ([]T, error) {
+	items := []T{}
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// AllProducts returns an iterator over every product matching params,
+// starting at params.Page (or the first page). A zero PerPage uses the
+// largest page size the API allows.
+func (c *Client) AllProducts(ctx context.Context, params GetProductsParams) *Iterator[Product] {
+	if params.PerPage <= 0 {
+		params.PerPage = maxPerPage
+	}
+	return newIterator(ctx, params.Page, func(ctx context.Context, page int) ([]Product, PageInfo, error) {
+		p := params
+		p.Page = page
+		result, err := c.GetProducts(ctx, p)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		return result.Products, result.PageInfo, nil
+	})
+}
+
+// AllVariations returns an iterator over every variation of a variable product.
+func (c *Client) AllVariations(ctx context.Context, productID int) *Iterator[Variation] {
+	endpoint := fmt.Sprintf("/wp-json/wc/v3/products/%d/variations", productID)
+	return allPages[Variation](ctx, c, endpoint, nil)
+}
+
+// allTerms returns an iterator over a taxonomy endpoint (categories or tags).
+func allTerms[T any](ctx context.Context, c *Client, endpoint string, params GetTermsParams) *Iterator[T] {
+	query := url.Values{}
+	if params.HideEmpty {
+		query.Set("hide_empty", "true")
+	}
+	return allPages[T](ctx, c, endpoint, query)
+}
+
+// allPages returns an iterator over a list endpoint fetched with the
+// largest page size. The query is copied for every page.
+func allPages[T any](ctx context.Context, c *Client, endpoint string, query url.Values) *Iterator[T] {
+	return newIterator(ctx, 1, func(ctx context.Context, page int) ([]T, PageInfo, error) {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("page", strconv.Itoa(page))
+		q.Set("per_page", strconv.Itoa(maxPerPage))
+
+		var items []T
+		header, err := c.doGet(ctx, endpoint, q, &items)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		return items, parsePageInfo(header, page, maxPerPage, len(items)), nil
+	})
+}
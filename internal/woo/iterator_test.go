@@ -0,0 +1,146 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// pagedServer serves total items split into pages of perPage, recording
+// which pages were requested.
+func pagedServer(t *testing.T, total, perPage int, requested *[]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 1 {
+			page = 1
+		}
+		if requested != nil {
+			*requested = append(*requested, page)
+		}
+
+		var products []Product
+		for id := (page-1)*perPage + 1; id <= page*perPage && id <= total; id++ {
+			products = append(products, Product{ID: id})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-WP-Total", strconv.Itoa(total))
+		w.Header().Set("X-WP-TotalPages", strconv.Itoa((total+perPage-1)/perPage))
+		json.NewEncoder(w).Encode(products)
+	}))
+}
+
+func TestAllProducts(t *testing.T) {
+	var requested []int
+	server := pagedServer(t, 25, 10, &requested)
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	it := client.AllProducts(context.Background(), GetProductsParams{PerPage: 10})
+	defer it.Close()
+
+	var ids []int
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ids) != 25 {
+		t.Fatalf("expected 25 products, got %d", len(ids))
+	}
+	for i, id := range ids {
+		if id != i+1 {
+			t.Fatalf("expected product %d at position %d, got %d", i+1, i, id)
+		}
+	}
+	if len(requested) != 3 {
+		t.Errorf("expected 3 page requests, got %v", requested)
+	}
+	if it.PageInfo().TotalItems != 25 {
+		t.Errorf("expected TotalItems=25, got %d", it.PageInfo().TotalItems)
+	}
+}
+
+func TestAllProductsContextCancel(t *testing.T) {
+	// Cancelled in the middle of the first page
+	server := pagedServer(t, 50, 20, nil)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := NewClient(server.URL)
+	it := client.AllProducts(ctx, GetProductsParams{PerPage: 20})
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		count++
+		if count == 5 {
+			cancel()
+		}
+	}
+
+	if !errors.Is(it.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", it.Err())
+	}
+	if count != 5 {
+		t.Errorf("expected iteration to stop as soon as it was cancelled, got %d items", count)
+	}
+}
+
+func TestAllProductsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	it := client.AllProducts(context.Background(), GetProductsParams{})
+	defer it.Close()
+
+	if it.Next() {
+		t.Fatal("expected Next to return false on error")
+	}
+	if it.Err() == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestGetVariationsFollowsPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("expected per_page=100, got %s", r.URL.Query().Get("per_page"))
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		var variations []Variation
+		count := 100
+		if page == 2 {
+			count = 20
+		}
+		for i := 0; i < count; i++ {
+			variations = append(variations, Variation{ID: page*1000 + i})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-WP-Total", "120")
+		w.Header().Set("X-WP-TotalPages", "2")
+		json.NewEncoder(w).Encode(variations)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	variations, err := client.GetVariations(context.Background(), 101)
+	if err != nil {
+		t.Fatalf("GetVariations failed: %v", err)
+	}
+	if len(variations) != 120 {
+		t.Errorf("expected 120 variations, got %d", len(variations))
+	}
+}
@@ -0,0 +1,266 @@
+package woo
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultDecimals is the number of decimals used for amounts that do not
+// carry more precision, matching the WooCommerce default of two.
+const DefaultDecimals = 2
+
+// maxDecimals caps the precision accepted by ParseMoney so that amounts
+// always fit in an int64 of minor units.
+const maxDecimals = 8
+
+// ErrInvalidMoney is returned when a price string is not a plain decimal number.
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// ErrCurrencyMismatch is returned when adding, subtracting or comparing
+// amounts in two different currencies.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an exact decimal amount in a currency. It is stored as an integer
+// number of minor units (e.g. cents) and the number of decimals those units
+// represent, so sums and products never lose precision.
+//
+// The zero value is zero in an unknown currency.
+type Money struct {
+	minor    int64
+	decimals int
+	currency string
+}
+
+// NewMoney returns an amount of minor units with the given number of decimals.
+// NewMoney(1899, 2, "EUR") is €18.99.
+func NewMoney(minor int64, decimals int, currency string) Money {
+	return Money{minor: minor, decimals: decimals, currency: currency}
+}
+
+// ZeroMoney returns zero in the given currency.
+func ZeroMoney(currency string) Money {
+	return Money{decimals: DefaultDecimals, currency: currency}
+}
+
+// ParseMoney parses a WooCommerce price string such as "18.99", "5" or "-2.50".
+// Only an optional minus sign, digits and a single decimal point are accepted;
+// empty strings, exponents, thousand separators and currency symbols are
+// rejected. The result keeps every decimal given, and at least DefaultDecimals.
+func ParseMoney(s string, currency string) (Money, error) {
+	invalid := func() (Money, error) {
+		return Money{}, fmt.Errorf("parsing price %q: %w", s, ErrInvalidMoney)
+	}
+
+	digits := s
+	negative := strings.HasPrefix(digits, "-")
+	if negative {
+		digits = digits[1:]
+	}
+
+	whole, frac, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || (hasPoint && frac == "") || len(frac) > maxDecimals {
+		return invalid()
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return invalid()
+	}
+
+	decimals := DefaultDecimals
+	if len(frac) > decimals {
+		decimals = len(frac)
+	}
+	frac += strings.Repeat("0", decimals-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return invalid()
+	}
+	if negative {
+		minor = -minor
+	}
+	return Money{minor: minor, decimals: decimals, currency: currency}, nil
+}
+
+// isDigits reports whether s contains only ASCII digits.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Minor returns the amount in minor units at Decimals precision.
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Decimals returns the number of decimals the minor units represent.
+func (m Money) Decimals() int {
+	return m.decimals
+}
+
+// Currency returns the ISO 4217 currency code, or "" if unknown.
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// IsNegative reports whether the amount is below zero.
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// Add returns m + o. See align for how currencies and precision are combined.
+func (m Money) Add(o Money) (Money, error) {
+	a, b, err := align(m, o)
+	if err != nil {
+		return Money{}, err
+	}
+	a.minor += b.minor
+	return a, nil
+}
+
+// Sub returns m - o.
+func (m Money) Sub(o Money) (Money, error) {
+	a, b, err := align(m, o)
+	if err != nil {
+		return Money{}, err
+	}
+	a.minor -= b.minor
+	return a, nil
+}
+
+// Mul returns m multiplied by a quantity.
+func (m Money) Mul(quantity int) Money {
+	m.minor *= int64(quantity)
+	return m
+}
+
+// MulRatio returns m * num / den, rounded half away from zero at the
+// precision of m. It is used to apply percentages such as tax rates;
+// den must be positive.
+func (m Money) MulRatio(num, den int64) Money {
+	product := new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(num))
+	q, r := new(big.Int).QuoRem(product, big.NewInt(den), new(big.Int))
+	r.Abs(r).Mul(r, big.NewInt(2))
+	if r.Cmp(big.NewInt(den)) >= 0 {
+		if product.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	m.minor = q.Int64()
+	return m
+}
+
+// parsePercent parses a percentage such as "22.0000" or "10" into parts
+// per million, so 22% is 220000. Up to four decimals are kept.
+func parsePercent(s string) (int64, error) {
+	percent, err := ParseMoney(strings.TrimSpace(s), "")
+	if err != nil {
+		return 0, err
+	}
+	return percent.Round(4).Minor(), nil
+}
+
+// Cmp compares m and o and returns -1, 0 or +1.
+func (m Money) Cmp(o Money) (int, error) {
+	a, b, err := align(m, o)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case a.minor < b.minor:
+		return -1, nil
+	case a.minor > b.minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// LessThan reports whether m < o.
+func (m Money) LessThan(o Money) (bool, error) {
+	c, err := m.Cmp(o)
+	return c < 0, err
+}
+
+// Round returns m rounded half away from zero to the given number of
+// decimals, the way WooCommerce rounds totals.
+func (m Money) Round(decimals int) Money {
+	if decimals >= m.decimals {
+		return m.rescale(decimals)
+	}
+	factor := pow10(m.decimals - decimals)
+	q, r := m.minor/factor, m.minor%factor
+	if r*2 >= factor {
+		q++
+	} else if r*2 <= -factor {
+		q--
+	}
+	return Money{minor: q, decimals: decimals, currency: m.currency}
+}
+
+// String formats the amount as a plain decimal string such as "18.99",
+// the format WooCommerce uses for prices and totals in the REST API.
+func (m Money) String() string {
+	minor := m.minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	if m.decimals == 0 {
+		return sign + strconv.FormatInt(minor, 10)
+	}
+	factor := pow10(m.decimals)
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/factor, m.decimals, minor%factor)
+}
+
+// align brings two amounts to the same precision. An amount with no currency
+// takes the currency of the other; two different currencies can't be
+// combined and return ErrCurrencyMismatch.
+func align(a, b Money) (Money, Money, error) {
+	switch {
+	case a.currency == "":
+		a.currency = b.currency
+	case b.currency == "":
+		b.currency = a.currency
+	case a.currency != b.currency:
+		return a, b, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.currency, b.currency)
+	}
+	if a.decimals < b.decimals {
+		a = a.rescale(b.decimals)
+	} else if b.decimals < a.decimals {
+		b = b.rescale(a.decimals)
+	}
+	return a, b, nil
+}
+
+// rescale increases the precision of m without changing its value.
+func (m Money) rescale(decimals int) Money {
+	if decimals <= m.decimals {
+		return m
+	}
+	m.minor *= pow10(decimals - m.decimals)
+	m.decimals = decimals
+	return m
+}
+
+// pow10 returns 10^n for small non-negative n.
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
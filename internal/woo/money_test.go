@@ -0,0 +1,162 @@
+package woo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input    string
+		minor    int64
+		decimals int
+		str      string
+	}{
+		{"18.99", 1899, 2, "18.99"},
+		{"5", 500, 2, "5.00"},
+		{"5.5", 550, 2, "5.50"},
+		{"0.10", 10, 2, "0.10"},
+		{"-2.50", -250, 2, "-2.50"},
+		{"12.3456", 123456, 4, "12.3456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m, err := ParseMoney(tt.input, "EUR")
+			if err != nil {
+				t.Fatalf("ParseMoney(%q) failed: %v", tt.input, err)
+			}
+			if m.Minor() != tt.minor || m.Decimals() != tt.decimals {
+				t.Errorf("expected %d at %d decimals, got %d at %d", tt.minor, tt.decimals, m.Minor(), m.Decimals())
+			}
+			if m.Currency() != "EUR" {
+				t.Errorf("expected currency EUR, got %q", m.Currency())
+			}
+			if m.String() != tt.str {
+				t.Errorf("expected %q, got %q", tt.str, m.String())
+			}
+		})
+	}
+}
+
+func TestParseMoneyRejectsInvalid(t *testing.T) {
+	inputs := []string{"", "-", ".5", "5.", "1e3", "1,000.00", "€5", " 5", "5.00 ", "1.2.3", "abc", "--1", "1.123456789", "99999999999999999999"}
+
+	for _, input := range inputs {
+		if _, err := ParseMoney(input, "EUR"); !errors.Is(err, ErrInvalidMoney) {
+			t.Errorf("ParseMoney(%q): expected ErrInvalidMoney, got %v", input, err)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	price := NewMoney(1899, 2, "EUR")
+	must := func(m Money, err error) Money {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	// Floats would give 56.970000000000006
+	if got := price.Mul(3).String(); got != "56.97" {
+		t.Errorf("expected 56.97, got %s", got)
+	}
+
+	// 0.1 + 0.2 is exact
+	sum := must(NewMoney(10, 2, "EUR").Add(NewMoney(20, 2, "EUR")))
+	if sum.String() != "0.30" {
+		t.Errorf("expected 0.30, got %s", sum.String())
+	}
+
+	// Mixed precision is rescaled
+	mixed := must(NewMoney(150, 2, "EUR").Add(NewMoney(1, 3, "EUR")))
+	if mixed.String() != "1.501" {
+		t.Errorf("expected 1.501, got %s", mixed.String())
+	}
+
+	if got := must(NewMoney(500, 2, "EUR").Sub(price)).String(); got != "-13.99" {
+		t.Errorf("expected -13.99, got %s", got)
+	}
+
+	// Zero value takes the other currency
+	if got := must((Money{}).Add(price)).Currency(); got != "EUR" {
+		t.Errorf("expected EUR, got %q", got)
+	}
+}
+
+func TestMoneyCompare(t *testing.T) {
+	a := NewMoney(4999, 2, "EUR")
+	b := NewMoney(5000, 2, "EUR")
+
+	aLess, err := a.LessThan(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bLess, _ := b.LessThan(a)
+	if !aLess || bLess {
+		t.Error("expected 49.99 < 50.00")
+	}
+	if c, err := NewMoney(50, 0, "EUR").Cmp(b); err != nil || c != 0 {
+		t.Errorf("expected 50 == 50.00, got %d, %v", c, err)
+	}
+	if !ZeroMoney("EUR").IsZero() || !NewMoney(-1, 2, "EUR").IsNegative() {
+		t.Error("unexpected IsZero/IsNegative result")
+	}
+}
+
+func TestMoneyRound(t *testing.T) {
+	tests := []struct {
+		minor    int64
+		decimals int
+		want     string
+	}{
+		{1234, 3, "1.23"},
+		{1235, 3, "1.24"},
+		{-1235, 3, "-1.24"},
+		{1299, 2, "12.99"},
+	}
+
+	for _, tt := range tests {
+		if got := NewMoney(tt.minor, tt.decimals, "EUR").Round(2).String(); got != tt.want {
+			t.Errorf("Round(%d at %d decimals) = %s, want %s", tt.minor, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMulRatio(t *testing.T) {
+	tests := []struct {
+		minor    int64
+		decimals int
+		num, den int64
+		want     string
+	}{
+		{1000, 2, 22, 100, "2.20"},                 // 22% of 10.00
+		{1899, 2, 22, 100, "4.18"},                 // 4.1778 rounds up
+		{1220, 2, 100, 122, "10.00"},               // Price without 22% tax
+		{-1899, 2, 22, 100, "-4.18"},               // Rounds away from zero
+		{5, 2, 1, 2, "0.03"},                       // Half rounds up
+		{18990000, 6, 220000, 1000000, "4.177800"}, // Exact at higher precision
+	}
+
+	for _, tt := range tests {
+		if got := NewMoney(tt.minor, tt.decimals, "EUR").MulRatio(tt.num, tt.den).String(); got != tt.want {
+			t.Errorf("MulRatio(%d, %d/%d) = %s, want %s", tt.minor, tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMixedCurrencies(t *testing.T) {
+	eur, usd := NewMoney(100, 2, "EUR"), NewMoney(100, 2, "USD")
+
+	if _, err := eur.Add(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add: expected ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err := eur.Sub(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub: expected ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err := eur.Cmp(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Cmp: expected ErrCurrencyMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package woo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Order statuses.
+const (
+	OrderStatusPending    = "pending"    // Awaiting payment
+	OrderStatusProcessing = "processing" // Paid, being prepared
+	OrderStatusOnHold     = "on-hold"    // Awaiting payment confirmation, e.g. a bank transfer
+	OrderStatusCompleted  = "completed"
+	OrderStatusCancelled  = "cancelled"
+	OrderStatusRefunded   = "refunded"
+	OrderStatusFailed     = "failed"
+)
+
+// OrderTokenMetaKey is the order meta_data key holding the token an order
+// was placed with, see NewOrderToken.
+const OrderTokenMetaKey = "_eva_terminal_order_token"
+
+// OrderMetaData is a custom field on an order. Plugins store any JSON in
+// these, so the value is kept raw.
+type OrderMetaData struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// StringMetaData returns a custom field with a string value.
+func StringMetaData(key, value string) OrderMetaData {
+	encoded, _ := json.Marshal(value)
+	return OrderMetaData{Key: key, Value: encoded}
+}
+
+// MetaValue returns the string value of a custom field, or "" if the
+// order doesn't have it or its value is not a string.
+func (o *OrderResponse) MetaValue(key string) string {
+	for _, meta := range o.MetaData {
+		var value string
+		if meta.Key == key && json.Unmarshal(meta.Value, &value) == nil {
+			return value
+		}
+	}
+	return ""
+}
+
+// NewOrderToken returns a random token to place an order with. Sent in
+// the order's meta_data under OrderTokenMetaKey, it lets FindOrderByToken
+// tell whether a request that failed midway created the order anyway.
+func NewOrderToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating order token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ListOrdersParams holds parameters for listing orders.
+type ListOrdersParams struct {
+	Page         int
+	PerPage      int
+	Customer     int       // Only orders placed on this customer account
+	BillingEmail string    // Only orders with this billing email, see ListOrders
+	Include      []int     // Only these order IDs
+	Status       []string  // Any status if empty
+	After        time.Time // Only orders created after this time, if set
+}
+
+// OrderPage is one page of orders, newest first.
+type OrderPage struct {
+	Orders []OrderResponse
+	PageInfo
+}
+
+// ListOrders fetches a page of orders, newest first.
+//
+// The API has no billing email filter, so BillingEmail searches for the
+// address and drops orders where it only matched elsewhere. The page
+// counts are for the search, so a page may hold fewer orders than PerPage.
+func (c *Client) ListOrders(ctx context.Context, params ListOrdersParams) (*OrderPage, error) {
+	query := url.Values{}
+	if params.Page > 0 {
+		query.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(params.PerPage))
+	}
+	if params.Customer > 0 {
+		query.Set("customer", strconv.Itoa(params.Customer))
+	}
+	if params.BillingEmail != "" {
+		query.Set("search", params.BillingEmail)
+	}
+	if len(params.Include) > 0 {
+		query.Set("include", JoinIDs(params.Include))
+	}
+	if len(params.Status) > 0 {
+		query.Set("status", strings.Join(params.Status, ","))
+	}
+	if !params.After.IsZero() {
+		query.Set("after", params.After.UTC().Format("2006-01-02T15:04:05"))
+		query.Set("dates_are_gmt", "true")
+	}
+	query.Set("orderby", "date")
+	query.Set("order", "desc")
+
+	var orders []OrderResponse
+	header, err := c.doGet(ctx, "/wp-json/wc/v3/orders", query, &orders)
+	if err != nil {
+		return nil, fmt.Errorf("listing orders: %w", err)
+	}
+	pageInfo := parsePageInfo(header, params.Page, params.PerPage, len(orders))
+
+	if params.BillingEmail != "" {
+		matched := orders[:0]
+		for _, o := range orders {
+			if strings.EqualFold(o.Billing.Email, params.BillingEmail) {
+				matched = append(matched, o)
+			}
+		}
+		orders = matched
+	}
+
+	return &OrderPage{Orders: orders, PageInfo: pageInfo}, nil
+}
+
+// FindOrderByToken looks for the order placed with a token among the
+// orders matching params, which should narrow the search down to the
+// customer or billing email and the time the order was first attempted.
+// Orders from a few minutes before params.After are checked too, in case
+// the store's clock is behind. It returns ErrNotFound if there is no such
+// order.
+func (c *Client) FindOrderByToken(ctx context.Context, token string, params ListOrdersParams) (*OrderResponse, error) {
+	if !params.After.IsZero() {
+		params.After = params.After.Add(-5 * time.Minute)
+	}
+	if params.PerPage == 0 {
+		params.PerPage = 20
+	}
+
+	for params.Page = 1; ; params.Page++ {
+		page, err := c.ListOrders(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("looking up order: %w", err)
+		}
+		for i := range page.Orders {
+			if page.Orders[i].MetaValue(OrderTokenMetaKey) == token {
+				return &page.Orders[i], nil
+			}
+		}
+		if params.Page >= page.TotalPages {
+			return nil, fmt.Errorf("looking up order: %w", ErrNotFound)
+		}
+	}
+}
+
+// GetOrder fetches a single order.
+func (c *Client) GetOrder(ctx context.Context, id int) (*OrderResponse, error) {
+	endpoint := fmt.Sprintf("/wp-json/wc/v3/orders/%d", id)
+
+	var order OrderResponse
+	if err := c.doRequest(ctx, endpoint, nil, &order); err != nil {
+		return nil, fmt.Errorf("fetching order %d: %w", id, err)
+	}
+	return &order, nil
+}
+
+// UnitPrice returns the price paid per unit before discounts, the way the
+// store displayed it: with tax if the order's prices included tax.
+func (item OrderLineItem) UnitPrice(currency string, pricesIncludeTax bool) (Money, error) {
+	if item.Quantity <= 0 {
+		return Money{}, fmt.Errorf("line item %q: invalid quantity %d", item.Name, item.Quantity)
+	}
+	subtotal, err := ParseMoney(item.Subtotal, currency)
+	if err != nil {
+		return Money{}, fmt.Errorf("line item %q: %w", item.Name, err)
+	}
+	if pricesIncludeTax && item.SubtotalTax != "" {
+		tax, err := ParseMoney(item.SubtotalTax, currency)
+		if err != nil {
+			return Money{}, fmt.Errorf("line item %q: %w", item.Name, err)
+		}
+		// Both are parsed in the order currency
+		subtotal, _ = subtotal.Add(tax)
+	}
+	return subtotal.MulRatio(1, int64(item.Quantity)), nil
+}
+
+// OrderNote is a note on an order, written by the store or the customer.
+type OrderNote struct {
+	ID           int    `json:"id"`
+	Author       string `json:"author"`
+	DateCreated  string `json:"date_created"`
+	Note         string `json:"note"` // May contain HTML
+	CustomerNote bool   `json:"customer_note"`
+}
+
+// GetCustomerOrderNotes fetches the notes on an order that the store wrote
+// for the customer, oldest first. Private notes are never returned.
+func (c *Client) GetCustomerOrderNotes(ctx context.Context, orderID int) ([]OrderNote, error) {
+	endpoint := fmt.Sprintf("/wp-json/wc/v3/orders/%d/notes", orderID)
+
+	query := url.Values{}
+	query.Set("type", "customer")
+	var notes []OrderNote
+	if err := c.doRequest(ctx, endpoint, query, &notes); err != nil {
+		return nil, fmt.Errorf("fetching notes for order %d: %w", orderID, err)
+	}
+
+	// The store returns the newest note first; an older store may also
+	// ignore the type filter, so private notes are dropped here too
+	customerNotes := []OrderNote{}
+	for i := len(notes) - 1; i >= 0; i-- {
+		if notes[i].CustomerNote {
+			customerNotes = append(customerNotes, notes[i])
+		}
+	}
+	return customerNotes, nil
+}
@@ -0,0 +1,228 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListOrders(t *testing.T) {
+	var query map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = map[string]string{}
+		for k := range r.URL.Query() {
+			query[k] = r.URL.Query().Get(k)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-WP-Total", "3")
+		w.Header().Set("X-WP-TotalPages", "1")
+		json.NewEncoder(w).Encode([]OrderResponse{
+			{ID: 3, Billing: BillingAddress{Email: "Ada@Example.com"}, LineItems: []OrderLineItem{{Quantity: 2}, {Quantity: 1}}},
+			{ID: 2, Billing: BillingAddress{Email: "grace@example.com"}, Shipping: BillingAddress{Address2: "c/o ada@example.com"}},
+			{ID: 1, Billing: BillingAddress{Email: "ada@example.com"}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	page, err := client.ListOrders(context.Background(), ListOrdersParams{Customer: 7, Status: []string{OrderStatusProcessing, OrderStatusCompleted}, Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("ListOrders failed: %v", err)
+	}
+	if query["customer"] != "7" || query["status"] != "processing,completed" || query["orderby"] != "date" || query["order"] != "desc" {
+		t.Errorf("unexpected query %v", query)
+	}
+	if len(page.Orders) != 3 || page.TotalItems != 3 {
+		t.Errorf("expected all 3 orders, got %d of %d", len(page.Orders), page.TotalItems)
+	}
+	if page.Orders[0].ItemCount() != 3 {
+		t.Errorf("expected 3 items in order 3, got %d", page.Orders[0].ItemCount())
+	}
+
+	// Orders where the email only matched another field are dropped
+	page, err = client.ListOrders(context.Background(), ListOrdersParams{BillingEmail: "ada@example.com"})
+	if err != nil {
+		t.Fatalf("ListOrders failed: %v", err)
+	}
+	if query["search"] != "ada@example.com" {
+		t.Errorf("expected a search for the email, got %v", query)
+	}
+	if len(page.Orders) != 2 || page.Orders[0].ID != 3 || page.Orders[1].ID != 1 {
+		t.Errorf("expected orders 3 and 1, got %+v", page.Orders)
+	}
+}
+
+func TestGetOrderAndNotes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/orders/42":
+			w.Write([]byte(`{"id": 42, "status": "processing", "line_items": [{"product_id": 1, "name": "Ethiopia", "quantity": 2, "total": "37.98"}]}`))
+		case "/wp-json/wc/v3/orders/42/notes":
+			if r.URL.Query().Get("type") != "customer" {
+				t.Errorf("expected only customer notes to be requested, got %q", r.URL.RawQuery)
+			}
+			// Newest first, with a private note the store should have filtered
+			w.Write([]byte(`[
+				{"id": 3, "note": "Shipped with tracking 123", "customer_note": true},
+				{"id": 2, "note": "Customer seems nice", "customer_note": false},
+				{"id": 1, "note": "Roasting your coffee", "customer_note": true}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	order, err := client.GetOrder(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("GetOrder failed: %v", err)
+	}
+	if order.Status != OrderStatusProcessing || order.LineItems[0].Name != "Ethiopia" || order.LineItems[0].Total != "37.98" {
+		t.Errorf("unexpected order %+v", order)
+	}
+
+	notes, err := client.GetCustomerOrderNotes(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("GetCustomerOrderNotes failed: %v", err)
+	}
+	if len(notes) != 2 || notes[0].ID != 1 || notes[1].ID != 3 {
+		t.Errorf("expected customer notes 1 and 3, oldest first, got %+v", notes)
+	}
+}
+
+func TestOrderLineItemMetaDataValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// Plugins store numbers and objects next to the attribute options
+		w.Write([]byte(`{"id": 42, "line_items": [{"product_id": 1, "name": "Ethiopia", "quantity": 2, "meta_data": [
+			{"id": 1, "key": "pa_grind-size", "value": "espresso"},
+			{"id": 2, "key": "_reduced_stock", "value": 2},
+			{"id": 3, "key": "_gift_wrap", "value": true},
+			{"id": 4, "key": "_bundle", "value": {"parent": 7}}
+		]}]}`))
+	}))
+	defer server.Close()
+
+	order, err := NewClient(server.URL).GetOrder(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("GetOrder failed: %v", err)
+	}
+
+	var got []string
+	for _, meta := range order.LineItems[0].MetaData {
+		got = append(got, meta.Key+"="+meta.String())
+	}
+	want := "pa_grind-size=espresso _reduced_stock=2 _gift_wrap=true _bundle="
+	if strings.Join(got, " ") != want {
+		t.Errorf("expected %q, got %q", want, strings.Join(got, " "))
+	}
+}
+
+func TestOrderLineItemUnitPrice(t *testing.T) {
+	// Two bags at 18.99 including 22% VAT
+	item := OrderLineItem{Name: "Ethiopia", Quantity: 2, Subtotal: "31.13", SubtotalTax: "6.85", Total: "28.02"}
+
+	price, err := item.UnitPrice("EUR", true)
+	if err != nil {
+		t.Fatalf("UnitPrice failed: %v", err)
+	}
+	if price.String() != "18.99" {
+		t.Errorf("expected 18.99 with tax, got %s", price)
+	}
+
+	price, err = item.UnitPrice("EUR", false)
+	if err != nil {
+		t.Fatalf("UnitPrice failed: %v", err)
+	}
+	if price.String() != "15.57" {
+		t.Errorf("expected 15.57 without tax, got %s", price)
+	}
+
+	if _, err := (OrderLineItem{Quantity: 1}).UnitPrice("EUR", false); err == nil {
+		t.Error("expected an error for a missing subtotal")
+	}
+}
+
+func TestFindOrderByToken(t *testing.T) {
+	var queries []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := map[string]string{}
+		for k := range r.URL.Query() {
+			query[k] = r.URL.Query().Get(k)
+		}
+		queries = append(queries, query)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-WP-TotalPages", "2")
+		other := OrderResponse{ID: 40, MetaData: []OrderMetaData{
+			{Key: "_plugin_data", Value: json.RawMessage(`{"a": [1, 2]}`)},
+			StringMetaData(OrderTokenMetaKey, "other"),
+		}}
+		if query["page"] == "1" {
+			json.NewEncoder(w).Encode([]OrderResponse{other})
+			return
+		}
+		json.NewEncoder(w).Encode([]OrderResponse{{ID: 41, MetaData: []OrderMetaData{StringMetaData(OrderTokenMetaKey, "abc123")}}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	since := time.Date(2026, 6, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	order, err := client.FindOrderByToken(context.Background(), "abc123", ListOrdersParams{Customer: 7, After: since})
+	if err != nil {
+		t.Fatalf("FindOrderByToken failed: %v", err)
+	}
+	if order.ID != 41 {
+		t.Errorf("expected order 41 from the second page, got %d", order.ID)
+	}
+	// Five minutes of slack for the store's clock, in GMT
+	if q := queries[0]; q["after"] != "2026-06-01T09:55:00" || q["dates_are_gmt"] != "true" || q["customer"] != "7" {
+		t.Errorf("unexpected query %v", q)
+	}
+
+	_, err = client.FindOrderByToken(context.Background(), "missing", ListOrdersParams{Customer: 7})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCreateOrderSendsMetaData(t *testing.T) {
+	var body map[string]json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(OrderResponse{ID: 45, MetaData: []OrderMetaData{StringMetaData(OrderTokenMetaKey, "abc123")}})
+	}))
+	defer server.Close()
+
+	token, err := NewOrderToken()
+	if err != nil {
+		t.Fatalf("NewOrderToken failed: %v", err)
+	}
+	if other, _ := NewOrderToken(); len(token) != 32 || token == other {
+		t.Errorf("expected a random 32 character token, got %q", token)
+	}
+
+	order, err := NewClient(server.URL).CreateOrder(context.Background(), OrderRequest{
+		MetaData: []OrderMetaData{StringMetaData(OrderTokenMetaKey, "abc123")},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder failed: %v", err)
+	}
+	if got := string(body["meta_data"]); got != `[{"key":"_eva_terminal_order_token","value":"abc123"}]` {
+		t.Errorf("unexpected meta_data %s", got)
+	}
+	if order.MetaValue(OrderTokenMetaKey) != "abc123" || order.MetaValue("missing") != "" {
+		t.Errorf("unexpected meta data %+v", order.MetaData)
+	}
+}
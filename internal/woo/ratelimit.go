@@ -0,0 +1,76 @@
+package woo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token-bucket limiter for outgoing API requests.
+// One limiter is meant to be shared by every SSH session so that many
+// concurrent customers cannot overload the store.
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // Tokens added per second
+	burst   float64 // Bucket capacity
+	tokens  float64
+	last    time.Time
+	nowFunc func() time.Time // For testing
+}
+
+// NewRateLimiter creates a limiter allowing ratePerSecond requests on
+// average with bursts of up to burst requests.
+func NewRateLimiter(ratePerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    ratePerSecond,
+		burst:   float64(burst),
+		tokens:  float64(burst),
+		last:    time.Now(),
+		nowFunc: time.Now,
+	}
+}
+
+// Wait blocks until a token is available or the context is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve()
+		if wait == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available and returns zero, otherwise
+// it returns how long until the next token.
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.nowFunc()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	if l.rate <= 0 {
+		// No refill: behave as unlimited rather than blocking forever
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
@@ -0,0 +1,45 @@
+package woo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	l := NewRateLimiter(2, 3)
+
+	current := time.Now()
+	l.last = current
+	l.nowFunc = func() time.Time { return current }
+
+	// The full burst is available immediately
+	for i := 0; i < 3; i++ {
+		if wait := l.reserve(); wait != 0 {
+			t.Fatalf("request %d: expected no wait, got %v", i, wait)
+		}
+	}
+
+	// The bucket is empty: next token arrives after 1/rate seconds
+	if wait := l.reserve(); wait != 500*time.Millisecond {
+		t.Errorf("expected 500ms wait, got %v", wait)
+	}
+
+	// After a second, two tokens have been added
+	current = current.Add(time.Second)
+	if wait := l.reserve(); wait != 0 {
+		t.Errorf("expected token after refill, got wait %v", wait)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	l := NewRateLimiter(0.001, 1)
+	l.Wait(context.Background()) // Drain the only token
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); err == nil {
+		t.Fatal("expected Wait to fail when the context expires")
+	}
+}
@@ -0,0 +1,102 @@
+package woo
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how the client retries failed requests.
+// Requests are retried on network errors and on 429, 502, 503 and 504
+// responses, with exponential backoff and full jitter between attempts.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first; 0 or 1 disables retries
+	BaseDelay   time.Duration // Backoff before the first retry
+	MaxDelay    time.Duration // Upper bound for a single wait, including Retry-After
+
+	// RetryNonIdempotent also retries POST/PUT/DELETE requests.
+	// Leave it off unless duplicate writes are harmless.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns a policy suitable for an interactive TUI:
+// up to three attempts, retrying GET requests only.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+	}
+}
+
+// attemptsFor returns how many attempts a request with the given method gets.
+func (p RetryPolicy) attemptsFor(method string) int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	if method != http.MethodGet && method != http.MethodHead && !p.RetryNonIdempotent {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// delay returns how long to wait before retrying after the given failed
+// attempt (1-based), or false if err should not be retried.
+func (p RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return 0, false
+		}
+		if apiErr.RetryAfter > 0 {
+			// Never retry earlier than the server asked; give up instead
+			// if that is longer than we are willing to wait.
+			if p.MaxDelay > 0 && apiErr.RetryAfter > p.MaxDelay {
+				return 0, false
+			}
+			return apiErr.RetryAfter, true
+		}
+	}
+
+	return p.backoff(attempt), true
+}
+
+// backoff returns a random duration in [0, min(MaxDelay, BaseDelay*2^(attempt-1))].
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || ceiling < p.MaxDelay); i++ {
+		ceiling *= 2
+	}
+	if p.MaxDelay > 0 && ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// parseRetryAfter parses a Retry-After header given either as seconds or
+// as an HTTP date. It returns zero if the header is absent or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
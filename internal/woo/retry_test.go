@@ -0,0 +1,173 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fastRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    50 * time.Millisecond,
+	}
+}
+
+func TestRetryOnServiceUnavailable(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Product{{ID: 1}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryPolicy(fastRetryPolicy()))
+	page, err := client.GetProducts(context.Background(), GetProductsParams{})
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if len(page.Products) != 1 {
+		t.Errorf("expected 1 product, got %d", len(page.Products))
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryPolicy(fastRetryPolicy()))
+	_, err := client.GetProducts(context.Background(), GetProductsParams{})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected 502 APIError, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryNotOnInternalServerError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryPolicy(fastRetryPolicy()))
+	client.GetProducts(context.Background(), GetProductsParams{})
+
+	if calls != 1 {
+		t.Errorf("expected a single call for a 500, got %d", calls)
+	}
+}
+
+func TestRetryNotOnPOSTByDefault(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "busy", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryPolicy(fastRetryPolicy()))
+	client.CreateOrder(context.Background(), OrderRequest{})
+
+	if calls != 1 {
+		t.Errorf("expected POST not to be retried, got %d calls", calls)
+	}
+}
+
+func TestRetryRespectsRetryAfter(t *testing.T) {
+	var calls int32
+	var first time.Time
+	var elapsed time.Duration
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		elapsed = time.Since(first)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Product{})
+	}))
+	defer server.Close()
+
+	policy := fastRetryPolicy()
+	policy.MaxDelay = 2 * time.Second
+	client := NewClient(server.URL, WithRetryPolicy(policy))
+	if _, err := client.GetProducts(context.Background(), GetProductsParams{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed < time.Second {
+		t.Errorf("expected retry to wait for Retry-After, waited %v", elapsed)
+	}
+}
+
+func TestRetryAfterLongerThanMaxDelay(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "120")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetryPolicy(fastRetryPolicy()))
+	_, err := client.GetProducts(context.Background(), GetProductsParams{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Errorf("expected no retry when Retry-After exceeds MaxDelay, got %d calls", calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if d := parseRetryAfter("5", now); d != 5*time.Second {
+		t.Errorf("expected 5s, got %v", d)
+	}
+	if d := parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now); d != 30*time.Second {
+		t.Errorf("expected 30s, got %v", d)
+	}
+	if d := parseRetryAfter("", now); d != 0 {
+		t.Errorf("expected 0 for empty header, got %v", d)
+	}
+	if d := parseRetryAfter("soon", now); d != 0 {
+		t.Errorf("expected 0 for invalid header, got %v", d)
+	}
+}
+
+func TestBackoffBounds(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		for i := 0; i < 50; i++ {
+			if d := p.backoff(attempt); d < 0 || d > p.MaxDelay {
+				t.Fatalf("attempt %d: backoff %v out of bounds", attempt, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package woo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ProductReview is a customer's review of a product.
+type ProductReview struct {
+	ID            int    `json:"id"`
+	DateCreated   string `json:"date_created"`
+	ProductID     int    `json:"product_id"`
+	Status        string `json:"status"` // "approved", "hold", "spam", "unspam", "trash" or "untrash"
+	Reviewer      string `json:"reviewer"`
+	ReviewerEmail string `json:"reviewer_email"`
+	Review        string `json:"review"`   // May contain HTML
+	Rating        int    `json:"rating"`   // 1 to 5, 0 if not rated
+	Verified      bool   `json:"verified"` // The reviewer bought the product
+}
+
+// ListReviewsParams holds parameters for listing product reviews.
+type ListReviewsParams struct {
+	Product int // Only reviews of this product
+	Page    int
+	PerPage int
+}
+
+// ReviewPage is one page of approved reviews, newest first.
+type ReviewPage struct {
+	Reviews []ProductReview
+	PageInfo
+}
+
+// ProductReviewRequest submits a review. The store marks it verified if
+// the reviewer's email bought the product.
+type ProductReviewRequest struct {
+	ProductID     int    `json:"product_id"`
+	Review        string `json:"review"`
+	Reviewer      string `json:"reviewer"`
+	ReviewerEmail string `json:"reviewer_email"`
+	Rating        int    `json:"rating"`
+}
+
+// ListProductReviews fetches a page of a product's approved reviews, newest first.
+func (c *Client) ListProductReviews(ctx context.Context, params ListReviewsParams) (*ReviewPage, error) {
+	query := url.Values{}
+	if params.Product > 0 {
+		query.Set("product", strconv.Itoa(params.Product))
+	}
+	if params.Page > 0 {
+		query.Set("page", strconv.Itoa(params.Page))
+	}
+	if params.PerPage > 0 {
+		query.Set("per_page", strconv.Itoa(params.PerPage))
+	}
+	query.Set("status", "approved")
+	query.Set("orderby", "date_gmt")
+	query.Set("order", "desc")
+
+	var reviews []ProductReview
+	header, err := c.doGet(ctx, "/wp-json/wc/v3/products/reviews", query, &reviews)
+	if err != nil {
+		return nil, fmt.Errorf("listing reviews: %w", err)
+	}
+	return &ReviewPage{Reviews: reviews, PageInfo: parsePageInfo(header, params.Page, params.PerPage, len(reviews))}, nil
+}
+
+// CreateProductReview submits a review. Depending on the store's settings
+// it is published straight away or held for moderation, see its Status.
+func (c *Client) CreateProductReview(ctx context.Context, req ProductReviewRequest) (*ProductReview, error) {
+	var review ProductReview
+	if err := c.doPostRequest(ctx, "/wp-json/wc/v3/products/reviews", req, &review); err != nil {
+		return nil, fmt.Errorf("submitting review: %w", err)
+	}
+	return &review, nil
+}
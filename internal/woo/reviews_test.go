@@ -0,0 +1,59 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProductReviews(t *testing.T) {
+	var created ProductReviewRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/wp-json/wc/v3/products/reviews" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			q := r.URL.Query()
+			if q.Get("product") != "12" || q.Get("status") != "approved" || q.Get("page") != "2" || q.Get("per_page") != "1" {
+				t.Errorf("unexpected query %s", r.URL.RawQuery)
+			}
+			w.Header().Set("X-WP-Total", "3")
+			w.Header().Set("X-WP-TotalPages", "3")
+			json.NewEncoder(w).Encode([]ProductReview{{ID: 5, ProductID: 12, Reviewer: "Ada", Rating: 4, Verified: true}})
+		case http.MethodPost:
+			json.NewDecoder(r.Body).Decode(&created)
+			json.NewEncoder(w).Encode(ProductReview{ID: 6, ProductID: created.ProductID, Status: "hold", Rating: created.Rating})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	page, err := client.ListProductReviews(ctx, ListReviewsParams{Product: 12, Page: 2, PerPage: 1})
+	if err != nil {
+		t.Fatalf("ListProductReviews failed: %v", err)
+	}
+	if len(page.Reviews) != 1 || page.Reviews[0].Reviewer != "Ada" || !page.Reviews[0].Verified {
+		t.Errorf("unexpected reviews %+v", page.Reviews)
+	}
+	if page.TotalItems != 3 || page.TotalPages != 3 || !page.HasNext() {
+		t.Errorf("unexpected page info %+v", page.PageInfo)
+	}
+
+	review, err := client.CreateProductReview(ctx, ProductReviewRequest{ProductID: 12, Review: "Lovely", Reviewer: "Ada", ReviewerEmail: "ada@example.com", Rating: 5})
+	if err != nil {
+		t.Fatalf("CreateProductReview failed: %v", err)
+	}
+	if review.ID != 6 || review.Status != "hold" {
+		t.Errorf("unexpected review %+v", review)
+	}
+	if created.ReviewerEmail != "ada@example.com" || created.Rating != 5 {
+		t.Errorf("unexpected request %+v", created)
+	}
+}
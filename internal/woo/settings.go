@@ -0,0 +1,210 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Currency symbol positions from the woocommerce_currency_pos setting.
+const (
+	CurrencyPosLeft       = "left"        // €99.99
+	CurrencyPosRight      = "right"       // 99.99€
+	CurrencyPosLeftSpace  = "left_space"  // € 99.99
+	CurrencyPosRightSpace = "right_space" // 99.99 €
+)
+
+// StoreSettings holds the currency and number format of the store,
+// taken from the WooCommerce general settings.
+type StoreSettings struct {
+	Currency          string // ISO 4217 code, e.g. "EUR"
+	CurrencySymbol    string // e.g. "€"
+	CurrencyPosition  string // One of the CurrencyPos* constants
+	ThousandSeparator string
+	DecimalSeparator  string
+	Decimals          int    // Number of decimals shown in prices
+	DefaultCountry    string // Store base country, e.g. "IT"
+	DefaultState      string // Store base state without the country, e.g. "MI"; "" if not set
+	TaxesEnabled      bool   // woocommerce_calc_taxes
+
+	// Warnings lists settings that were malformed and replaced by a
+	// fallback, for the caller to log.
+	Warnings []string
+}
+
+// DefaultStoreSettings returns the WooCommerce defaults (US dollars),
+// used when the store settings cannot be fetched.
+func DefaultStoreSettings() StoreSettings {
+	return StoreSettings{
+		Currency:          "USD",
+		CurrencySymbol:    "$",
+		CurrencyPosition:  CurrencyPosLeft,
+		ThousandSeparator: ",",
+		DecimalSeparator:  ".",
+		Decimals:          DefaultDecimals,
+		DefaultCountry:    "US",
+	}
+}
+
+// currencyDecimals lists the ISO 4217 currencies whose minor unit is not
+// a hundredth. Every other currency has DefaultDecimals.
+var currencyDecimals = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyDecimals returns the number of decimals a currency is usually
+// priced with, e.g. 2 for EUR and 0 for JPY.
+func CurrencyDecimals(currency string) int {
+	if n, ok := currencyDecimals[strings.ToUpper(currency)]; ok {
+		return n
+	}
+	return DefaultDecimals
+}
+
+// settingOption is one entry of a WooCommerce settings group.
+type settingOption struct {
+	ID    string          `json:"id"`
+	Value json.RawMessage `json:"value"`
+}
+
+// currencyInfo is the response of the data/currencies endpoints.
+type currencyInfo struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+}
+
+// GetStoreSettings fetches the currency and price format from the general
+// settings group, and the currency symbol from the data endpoint.
+// Settings missing from the response keep their DefaultStoreSettings value;
+// a malformed number of decimals falls back to the currency's precision,
+// with a warning in the returned settings.
+func (c *Client) GetStoreSettings(ctx context.Context) (*StoreSettings, error) {
+	var options []settingOption
+	if err := c.doRequest(ctx, "/wp-json/wc/v3/settings/general", nil, &options); err != nil {
+		return nil, fmt.Errorf("fetching general settings: %w", err)
+	}
+
+	settings := DefaultStoreSettings()
+	currencyChanged := false
+	invalidDecimals := false
+	for _, opt := range options {
+		var value string
+		if err := json.Unmarshal(opt.Value, &value); err != nil {
+			continue // Not a string setting
+		}
+		switch opt.ID {
+		case "woocommerce_currency":
+			if value != "" && value != settings.Currency {
+				settings.Currency = value
+				currencyChanged = true
+			}
+		case "woocommerce_currency_pos":
+			settings.CurrencyPosition = value
+		case "woocommerce_price_thousand_sep":
+			settings.ThousandSeparator = value
+		case "woocommerce_price_decimal_sep":
+			if value != "" {
+				settings.DecimalSeparator = value
+			}
+		case "woocommerce_price_num_decimals":
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 || n > maxDecimals {
+				invalidDecimals = true
+				settings.Warnings = append(settings.Warnings,
+					fmt.Sprintf("invalid woocommerce_price_num_decimals %q, using the currency's precision", value))
+				continue
+			}
+			settings.Decimals = n
+		case "woocommerce_default_country":
+			// "IT" or "IT:RM" when a state is set
+			country, state, _ := strings.Cut(value, ":")
+			settings.DefaultCountry = country
+			settings.DefaultState = state
+		case "woocommerce_calc_taxes":
+			settings.TaxesEnabled = value == "yes"
+		}
+	}
+
+	if invalidDecimals {
+		settings.Decimals = CurrencyDecimals(settings.Currency)
+	}
+
+	var currency currencyInfo
+	err := c.doRequest(ctx, "/wp-json/wc/v3/data/currencies/current", nil, &currency)
+	switch {
+	case err == nil && currency.Symbol != "":
+		settings.CurrencySymbol = html.UnescapeString(currency.Symbol)
+	case err != nil && !errors.Is(err, ErrNotFound):
+		return nil, fmt.Errorf("fetching currency symbol: %w", err)
+	case currencyChanged:
+		// Older stores without the data endpoint: show the code instead
+		settings.CurrencySymbol = settings.Currency
+	}
+
+	return &settings, nil
+}
+
+// ParsePrice parses a price string as Money in the store currency.
+func (s StoreSettings) ParsePrice(price string) (Money, error) {
+	return ParseMoney(price, s.Currency)
+}
+
+// Format formats an amount the way the store shows prices, e.g. "1.234,50 €".
+// The amount is rounded to the store's number of decimals.
+func (s StoreSettings) Format(m Money) string {
+	rounded := m.Round(s.Decimals)
+	digits := rounded.String()
+
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(digits, "-")
+	whole, frac, _ := strings.Cut(digits, ".")
+
+	number := groupThousands(whole, s.ThousandSeparator)
+	if frac != "" {
+		number += s.DecimalSeparator + frac
+	}
+
+	var formatted string
+	switch s.CurrencyPosition {
+	case CurrencyPosRight:
+		formatted = number + s.CurrencySymbol
+	case CurrencyPosLeftSpace:
+		formatted = s.CurrencySymbol + " " + number
+	case CurrencyPosRightSpace:
+		formatted = number + " " + s.CurrencySymbol
+	default:
+		formatted = s.CurrencySymbol + number
+	}
+
+	if negative {
+		return "-" + formatted
+	}
+	return formatted
+}
+
+// groupThousands inserts sep between every group of three digits.
+func groupThousands(digits, sep string) string {
+	if sep == "" || len(digits) <= 3 {
+		return digits
+	}
+	var sb strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		sb.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if sb.Len() > 0 {
+			sb.WriteString(sep)
+		}
+		sb.WriteString(digits[i : i+3])
+	}
+	return sb.String()
+}
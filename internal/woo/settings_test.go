@@ -0,0 +1,142 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetStoreSettings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/settings/general":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"id": "woocommerce_default_country", "value": "IT:RM"},
+				{"id": "woocommerce_currency", "value": "EUR"},
+				{"id": "woocommerce_currency_pos", "value": "right_space"},
+				{"id": "woocommerce_price_thousand_sep", "value": "."},
+				{"id": "woocommerce_price_decimal_sep", "value": ","},
+				{"id": "woocommerce_price_num_decimals", "value": "2"},
+				{"id": "woocommerce_specific_allowed_countries", "value": []string{"IT", "FR"}},
+			})
+		case "/wp-json/wc/v3/data/currencies/current":
+			json.NewEncoder(w).Encode(map[string]string{"code": "EUR", "name": "Euro", "symbol": "&euro;"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	settings, err := client.GetStoreSettings(context.Background())
+	if err != nil {
+		t.Fatalf("GetStoreSettings failed: %v", err)
+	}
+
+	want := StoreSettings{
+		Currency:          "EUR",
+		CurrencySymbol:    "€",
+		CurrencyPosition:  CurrencyPosRightSpace,
+		ThousandSeparator: ".",
+		DecimalSeparator:  ",",
+		Decimals:          2,
+		DefaultCountry:    "IT",
+		DefaultState:      "RM",
+	}
+	if !reflect.DeepEqual(*settings, want) {
+		t.Errorf("expected %+v, got %+v", want, *settings)
+	}
+}
+
+func TestGetStoreSettingsWithoutCurrencyEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wp-json/wc/v3/settings/general" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"rest_no_route","message":"No route was found","data":{"status":404}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]map[string]string{{"id": "woocommerce_currency", "value": "CHF"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	settings, err := client.GetStoreSettings(context.Background())
+	if err != nil {
+		t.Fatalf("GetStoreSettings failed: %v", err)
+	}
+	if settings.CurrencySymbol != "CHF" {
+		t.Errorf("expected the currency code as symbol, got %q", settings.CurrencySymbol)
+	}
+}
+
+func TestGetStoreSettingsInvalidDecimals(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/settings/general":
+			json.NewEncoder(w).Encode([]map[string]string{
+				{"id": "woocommerce_price_num_decimals", "value": "two"},
+				{"id": "woocommerce_currency", "value": "JPY"},
+			})
+		case "/wp-json/wc/v3/data/currencies/current":
+			json.NewEncoder(w).Encode(map[string]string{"code": "JPY", "name": "Japanese yen", "symbol": "&yen;"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	settings, err := client.GetStoreSettings(context.Background())
+	if err != nil {
+		t.Fatalf("GetStoreSettings failed: %v", err)
+	}
+	if settings.Decimals != 0 {
+		t.Errorf("expected the 0 decimals of JPY, got %d", settings.Decimals)
+	}
+	if len(settings.Warnings) != 1 || !strings.Contains(settings.Warnings[0], `"two"`) {
+		t.Errorf("expected a warning about the decimals, got %q", settings.Warnings)
+	}
+}
+
+func TestStoreSettingsFormat(t *testing.T) {
+	italy := StoreSettings{
+		Currency:          "EUR",
+		CurrencySymbol:    "€",
+		CurrencyPosition:  CurrencyPosRightSpace,
+		ThousandSeparator: ".",
+		DecimalSeparator:  ",",
+		Decimals:          2,
+	}
+
+	tests := []struct {
+		name     string
+		settings StoreSettings
+		amount   Money
+		want     string
+	}{
+		{"default", DefaultStoreSettings(), NewMoney(1899, 2, "USD"), "$18.99"},
+		{"thousands", DefaultStoreSettings(), NewMoney(123456789, 2, "USD"), "$1,234,567.89"},
+		{"italy", italy, NewMoney(123450, 2, "EUR"), "1.234,50 €"},
+		{"negative", italy, NewMoney(-500, 2, "EUR"), "-5,00 €"},
+		{"rounded", italy, NewMoney(18995, 3, "EUR"), "19,00 €"},
+		{"no decimals", StoreSettings{CurrencySymbol: "¥", CurrencyPosition: CurrencyPosLeft, ThousandSeparator: ",", Decimals: 0}, NewMoney(150000, 2, "JPY"), "¥1,500"},
+		{"right", StoreSettings{CurrencySymbol: "kr", CurrencyPosition: CurrencyPosRight, DecimalSeparator: ",", Decimals: 2}, NewMoney(995, 2, "SEK"), "9,95kr"},
+		{"left space", StoreSettings{CurrencySymbol: "CHF", CurrencyPosition: CurrencyPosLeftSpace, ThousandSeparator: "'", DecimalSeparator: ".", Decimals: 2}, NewMoney(100000, 2, "CHF"), "CHF 1'000.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.settings.Format(tt.amount); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
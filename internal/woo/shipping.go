@@ -0,0 +1,404 @@
+package woo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedCost is returned for shipping cost formulas the client cannot evaluate.
+var ErrUnsupportedCost = errors.New("unsupported shipping cost formula")
+
+// ShippingZone is a WooCommerce shipping zone with its locations and methods.
+// Zone 0 is "Locations not covered by your other zones".
+type ShippingZone struct {
+	ID        int                    `json:"id"`
+	Name      string                 `json:"name"`
+	Order     int                    `json:"order"`
+	Locations []ShippingZoneLocation `json:"-"`
+	Methods   []ShippingZoneMethod   `json:"-"`
+}
+
+// Location types of a shipping zone.
+const (
+	LocationPostcode  = "postcode"
+	LocationState     = "state"
+	LocationCountry   = "country"
+	LocationContinent = "continent"
+)
+
+// ShippingZoneLocation is one region of a shipping zone.
+// Codes look like "IT", "IT:RM", "EU" or "00100...00199".
+type ShippingZoneLocation struct {
+	Code string `json:"code"`
+	Type string `json:"type"` // One of the Location* constants
+}
+
+// ShippingZoneMethod is a shipping method instance configured in a zone.
+type ShippingZoneMethod struct {
+	InstanceID  int                              `json:"instance_id"`
+	Title       string                           `json:"title"`
+	Order       int                              `json:"order"`
+	Enabled     bool                             `json:"enabled"`
+	MethodID    string                           `json:"method_id"` // e.g. "flat_rate", "free_shipping", "local_pickup"
+	MethodTitle string                           `json:"method_title"`
+	Settings    map[string]ShippingMethodSetting `json:"settings"`
+}
+
+// ShippingMethodSetting is one setting of a shipping method instance.
+type ShippingMethodSetting struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Setting returns the value of a method setting, or "" if it is not set.
+func (m *ShippingZoneMethod) Setting(id string) string {
+	return m.Settings[id].Value
+}
+
+// ShippingPackage is what is being shipped and where to.
+type ShippingPackage struct {
+	Country  string // ISO 3166-1 alpha-2
+	State    string // State code without the country, e.g. "RM"
+	Postcode string
+	Contents Money // Subtotal of the items
+	Quantity int   // Total quantity of the items
+
+	Discount           Money // Coupon discount on the items
+	FreeShippingCoupon bool  // A coupon granting free shipping is applied
+
+	// Number format of the store, which flat rate costs may be entered in
+	DecimalSeparator  string
+	ThousandSeparator string
+}
+
+// ShippingRate is a shipping method that applies to a package, with its cost.
+type ShippingRate struct {
+	ZoneID     int
+	ZoneName   string
+	InstanceID int
+	MethodID   string
+	Title      string
+	Cost       Money
+	Taxable    bool // From the tax_status setting
+}
+
+// ShippingTable holds every shipping zone of the store and the continent of
+// each country, which is all that is needed to work out the rates for a package.
+type ShippingTable struct {
+	Zones      []ShippingZone    // Sorted by Order, zone 0 last
+	Continents map[string]string // Country code to continent code
+}
+
+// continent is an entry of the data/continents endpoint.
+type continent struct {
+	Code      string `json:"code"`
+	Countries []struct {
+		Code string `json:"code"`
+	} `json:"countries"`
+}
+
+// GetShippingZones fetches the shipping zones, without locations or methods.
+func (c *Client) GetShippingZones(ctx context.Context) ([]ShippingZone, error) {
+	var zones []ShippingZone
+	if err := c.doRequest(ctx, "/wp-json/wc/v3/shipping/zones", nil, &zones); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// GetShippingZoneLocations fetches the locations of a shipping zone.
+func (c *Client) GetShippingZoneLocations(ctx context.Context, zoneID int) ([]ShippingZoneLocation, error) {
+	endpoint := fmt.Sprintf("/wp-json/wc/v3/shipping/zones/%d/locations", zoneID)
+	var locations []ShippingZoneLocation
+	if err := c.doRequest(ctx, endpoint, nil, &locations); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+// GetShippingZoneMethods fetches the methods configured in a shipping zone.
+func (c *Client) GetShippingZoneMethods(ctx context.Context, zoneID int) ([]ShippingZoneMethod, error) {
+	endpoint := fmt.Sprintf("/wp-json/wc/v3/shipping/zones/%d/methods", zoneID)
+	var methods []ShippingZoneMethod
+	if err := c.doRequest(ctx, endpoint, nil, &methods); err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
+
+// GetShippingTable fetches every zone with its locations and methods, and
+// the continent list used to match continent locations.
+func (c *Client) GetShippingTable(ctx context.Context) (*ShippingTable, error) {
+	zones, err := c.GetShippingZones(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching shipping zones: %w", err)
+	}
+
+	for i := range zones {
+		zone := &zones[i]
+		if zone.ID != 0 {
+			// The catch-all zone has no locations
+			if zone.Locations, err = c.GetShippingZoneLocations(ctx, zone.ID); err != nil {
+				return nil, fmt.Errorf("fetching locations of zone %d: %w", zone.ID, err)
+			}
+		}
+		if zone.Methods, err = c.GetShippingZoneMethods(ctx, zone.ID); err != nil {
+			return nil, fmt.Errorf("fetching methods of zone %d: %w", zone.ID, err)
+		}
+	}
+
+	var continents []continent
+	if err := c.doRequest(ctx, "/wp-json/wc/v3/data/continents", nil, &continents); err != nil {
+		return nil, fmt.Errorf("fetching continents: %w", err)
+	}
+
+	return newShippingTable(zones, continents), nil
+}
+
+// newShippingTable sorts zones the way WooCommerce matches them.
+func newShippingTable(zones []ShippingZone, continents []continent) *ShippingTable {
+	sort.SliceStable(zones, func(i, j int) bool {
+		if (zones[i].ID == 0) != (zones[j].ID == 0) {
+			return zones[j].ID == 0
+		}
+		return zones[i].Order < zones[j].Order
+	})
+
+	countryContinent := make(map[string]string)
+	for _, cont := range continents {
+		for _, country := range cont.Countries {
+			countryContinent[country.Code] = cont.Code
+		}
+	}
+	return &ShippingTable{Zones: zones, Continents: countryContinent}
+}
+
+// MatchZone returns the first zone covering the destination, or the
+// catch-all zone 0 if there is one. It returns nil if no zone applies.
+func (t *ShippingTable) MatchZone(pkg ShippingPackage) *ShippingZone {
+	for i := range t.Zones {
+		zone := &t.Zones[i]
+		if zone.ID == 0 {
+			return zone
+		}
+		if t.zoneMatches(zone, pkg) {
+			return zone
+		}
+	}
+	return nil
+}
+
+// zoneMatches mirrors WooCommerce: the destination must be in one of the
+// zone's regions (country, state or continent), and when the zone lists
+// postcodes the postcode must match one of them as well.
+func (t *ShippingTable) zoneMatches(zone *ShippingZone, pkg ShippingPackage) bool {
+	country := strings.ToUpper(pkg.Country)
+	state := country + ":" + strings.ToUpper(pkg.State)
+
+	hasRegions, regionMatch := false, false
+	hasPostcodes, postcodeMatch := false, false
+	for _, loc := range zone.Locations {
+		switch loc.Type {
+		case LocationCountry:
+			hasRegions = true
+			regionMatch = regionMatch || loc.Code == country
+		case LocationState:
+			hasRegions = true
+			regionMatch = regionMatch || (pkg.State != "" && loc.Code == state)
+		case LocationContinent:
+			hasRegions = true
+			regionMatch = regionMatch || (t.Continents[country] != "" && loc.Code == t.Continents[country])
+		case LocationPostcode:
+			hasPostcodes = true
+			postcodeMatch = postcodeMatch || postcodeMatches(loc.Code, pkg.Postcode)
+		}
+	}
+
+	if !hasRegions && !hasPostcodes {
+		return false
+	}
+	return (!hasRegions || regionMatch) && (!hasPostcodes || postcodeMatch)
+}
+
+// postcodeMatches supports exact postcodes, "*" wildcards and numeric
+// ranges such as "00100...00199". Case and spaces are ignored.
+func postcodeMatches(pattern, postcode string) bool {
+	normalize := func(s string) string {
+		return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+	}
+	pattern, postcode = normalize(pattern), normalize(postcode)
+	if postcode == "" {
+		return false
+	}
+
+	if lo, hi, ok := strings.Cut(pattern, "..."); ok {
+		value, err := strconv.Atoi(postcode)
+		low, errLo := strconv.Atoi(lo)
+		high, errHi := strconv.Atoi(hi)
+		return err == nil && errLo == nil && errHi == nil && value >= low && value <= high
+	}
+
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(postcode, prefix)
+	}
+	return pattern == postcode
+}
+
+// Rates returns the shipping methods that apply to a package, in the
+// order configured in the zone. Methods whose cost cannot be worked out
+// are left out, with the reason in skipped. A nil result means the store
+// does not ship there.
+func (t *ShippingTable) Rates(pkg ShippingPackage) (rates []ShippingRate, skipped []error) {
+	zone := t.MatchZone(pkg)
+	if zone == nil {
+		return nil, nil
+	}
+
+	methods := append([]ShippingZoneMethod(nil), zone.Methods...)
+	sort.SliceStable(methods, func(i, j int) bool { return methods[i].Order < methods[j].Order })
+
+	for i := range methods {
+		method := &methods[i]
+		if !method.Enabled {
+			continue
+		}
+		cost, ok, err := methodCost(method, pkg)
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("shipping method %d (%s) in zone %d, cost %q: %w",
+				method.InstanceID, method.MethodID, zone.ID, method.Setting("cost"), err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		rates = append(rates, ShippingRate{
+			ZoneID:     zone.ID,
+			ZoneName:   zone.Name,
+			InstanceID: method.InstanceID,
+			MethodID:   method.MethodID,
+			Title:      method.Title,
+			Cost:       cost,
+			Taxable:    method.Setting("tax_status") == "taxable",
+		})
+	}
+	return rates, skipped
+}
+
+// methodCost works out the cost of a method for a package. ok is false
+// when the method does not apply, e.g. free shipping below its minimum.
+func methodCost(method *ShippingZoneMethod, pkg ShippingPackage) (Money, bool, error) {
+	currency := pkg.Contents.Currency()
+
+	switch method.MethodID {
+	case "free_shipping":
+		reachesMin, err := reachesMinAmount(method, pkg)
+		if err != nil {
+			return Money{}, false, err
+		}
+		var ok bool
+		switch method.Setting("requires") {
+		case "":
+			ok = true
+		case "coupon":
+			ok = pkg.FreeShippingCoupon
+		case "min_amount":
+			ok = reachesMin
+		case "either":
+			ok = pkg.FreeShippingCoupon || reachesMin
+		case "both":
+			ok = pkg.FreeShippingCoupon && reachesMin
+		}
+		return ZeroMoney(currency), ok, nil
+
+	default:
+		// flat_rate, local_pickup and most third-party methods use "cost"
+		cost, err := evalCost(method.Setting("cost"), pkg)
+		if err != nil {
+			return Money{}, false, err
+		}
+		return cost, true, nil
+	}
+}
+
+// reachesMinAmount reports whether the package reaches the minimum amount of
+// a free shipping method. Discounts count unless the method ignores them.
+func reachesMinAmount(method *ShippingZoneMethod, pkg ShippingPackage) (bool, error) {
+	minAmount := method.Setting("min_amount")
+	if minAmount == "" {
+		return true, nil
+	}
+	min, err := ParseMoney(normalizeCost(minAmount, pkg), pkg.Contents.Currency())
+	if err != nil {
+		return false, err
+	}
+	amount := pkg.Contents
+	if method.Setting("ignore_discounts") != "yes" {
+		if amount, err = amount.Sub(pkg.Discount); err != nil {
+			return false, err
+		}
+	}
+	less, err := amount.LessThan(min)
+	return !less, err
+}
+
+// normalizeCost turns an amount entered in the store's number format, such
+// as "5,00" or "1.000,50" in an Italian shop, into the "5.00" form that
+// ParseMoney reads. Amounts already written with a decimal point are left
+// alone.
+func normalizeCost(amount string, pkg ShippingPackage) string {
+	decimal := pkg.DecimalSeparator
+	if decimal == "" || decimal == "." || !strings.Contains(amount, decimal) {
+		return amount
+	}
+	if thousand := pkg.ThousandSeparator; thousand != "" && thousand != decimal {
+		amount = strings.ReplaceAll(amount, thousand, "")
+	}
+	return strings.ReplaceAll(amount, decimal, ".")
+}
+
+// evalCost evaluates a flat rate cost such as "5.00" or "4 + 1.50 * [qty]".
+// Only sums of products of numbers and [qty] are supported; fees and
+// other shortcodes return ErrUnsupportedCost.
+func evalCost(formula string, pkg ShippingPackage) (Money, error) {
+	currency := pkg.Contents.Currency()
+	total := ZeroMoney(currency)
+
+	formula = strings.TrimSpace(formula)
+	if formula == "" {
+		return total, nil
+	}
+
+	for _, term := range strings.Split(formula, "+") {
+		quantity := 1
+		var amount *Money
+		for _, factor := range strings.Split(term, "*") {
+			factor = strings.TrimSpace(factor)
+			if factor == "[qty]" {
+				quantity *= pkg.Quantity
+				continue
+			}
+			if amount != nil {
+				return Money{}, fmt.Errorf("%q: %w", formula, ErrUnsupportedCost)
+			}
+			value, err := ParseMoney(normalizeCost(factor, pkg), currency)
+			if err != nil {
+				return Money{}, fmt.Errorf("%q: %w", formula, ErrUnsupportedCost)
+			}
+			amount = &value
+		}
+		if amount == nil {
+			// A bare [qty] counts as one unit of currency per item
+			one := NewMoney(1, 0, currency)
+			amount = &one
+		}
+		// Every amount is parsed in the package currency
+		total, _ = total.Add(amount.Mul(quantity))
+	}
+	return total, nil
+}
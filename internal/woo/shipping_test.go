@@ -0,0 +1,262 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testShippingTable ships within Italy, with a Rome-only courier zone,
+// across the EU, and nowhere else.
+func testShippingTable() *ShippingTable {
+	setting := func(value string) ShippingMethodSetting {
+		return ShippingMethodSetting{Value: value}
+	}
+	zones := []ShippingZone{
+		{ID: 0, Name: "Rest of the world"},
+		{
+			ID: 3, Name: "Europe", Order: 2,
+			Locations: []ShippingZoneLocation{{Code: "EU", Type: LocationContinent}},
+			Methods: []ShippingZoneMethod{
+				{InstanceID: 5, Title: "EU Standard", MethodID: "flat_rate", Enabled: true,
+					Settings: map[string]ShippingMethodSetting{"cost": setting("9.50 + 0.50 * [qty]"), "tax_status": setting("taxable")}},
+			},
+		},
+		{
+			ID: 1, Name: "Italy", Order: 1,
+			Locations: []ShippingZoneLocation{{Code: "IT", Type: LocationCountry}},
+			Methods: []ShippingZoneMethod{
+				{InstanceID: 2, Title: "Free shipping", Order: 2, MethodID: "free_shipping", Enabled: true,
+					Settings: map[string]ShippingMethodSetting{"requires": setting("min_amount"), "min_amount": setting("50")}},
+				{InstanceID: 1, Title: "Corriere", Order: 1, MethodID: "flat_rate", Enabled: true,
+					Settings: map[string]ShippingMethodSetting{"cost": setting("5.00"), "tax_status": setting("taxable")}},
+				{InstanceID: 4, Title: "Old courier", Order: 3, MethodID: "flat_rate", Enabled: false},
+				{InstanceID: 6, Title: "Coupon only", Order: 4, MethodID: "free_shipping", Enabled: true,
+					Settings: map[string]ShippingMethodSetting{"requires": setting("coupon")}},
+			},
+		},
+		{
+			ID: 2, Name: "Rome same-day", Order: 0,
+			Locations: []ShippingZoneLocation{
+				{Code: "IT:RM", Type: LocationState},
+				{Code: "00100...00199", Type: LocationPostcode},
+			},
+			Methods: []ShippingZoneMethod{
+				{InstanceID: 3, Title: "Ritiro in negozio", MethodID: "local_pickup", Enabled: true},
+			},
+		},
+	}
+	continents := []continent{{Code: "EU", Countries: []struct {
+		Code string `json:"code"`
+	}{{Code: "IT"}, {Code: "FR"}, {Code: "DE"}}}}
+	return newShippingTable(zones, continents)
+}
+
+func TestShippingTableMatchZone(t *testing.T) {
+	table := testShippingTable()
+
+	tests := []struct {
+		name string
+		pkg  ShippingPackage
+		zone int
+	}{
+		{"rome postcode and state", ShippingPackage{Country: "IT", State: "RM", Postcode: "00186"}, 2},
+		{"rome state outside postcode range", ShippingPackage{Country: "IT", State: "RM", Postcode: "00200"}, 1},
+		{"milan", ShippingPackage{Country: "it", State: "MI", Postcode: "20121"}, 1},
+		{"france by continent", ShippingPackage{Country: "FR", Postcode: "75001"}, 3},
+		{"us falls back to zone 0", ShippingPackage{Country: "US", Postcode: "10001"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := table.MatchZone(tt.pkg)
+			if zone == nil || zone.ID != tt.zone {
+				t.Errorf("expected zone %d, got %+v", tt.zone, zone)
+			}
+		})
+	}
+}
+
+func TestShippingTableRates(t *testing.T) {
+	table := testShippingTable()
+
+	// Below the free shipping minimum only the courier applies
+	pkg := ShippingPackage{Country: "IT", State: "MI", Postcode: "20121", Contents: NewMoney(4999, 2, "EUR"), Quantity: 2}
+	rates, _ := table.Rates(pkg)
+	if len(rates) != 1 || rates[0].InstanceID != 1 {
+		t.Fatalf("expected only the courier, got %+v", rates)
+	}
+	if rates[0].Cost.String() != "5.00" || rates[0].Cost.Currency() != "EUR" || !rates[0].Taxable {
+		t.Errorf("expected taxable 5.00 EUR, got %+v", rates[0])
+	}
+
+	// At the minimum, free shipping is offered after the courier
+	pkg.Contents = NewMoney(5000, 2, "EUR")
+	rates, _ = table.Rates(pkg)
+	if len(rates) != 2 || rates[1].MethodID != "free_shipping" || !rates[1].Cost.IsZero() {
+		t.Fatalf("expected courier and free shipping, got %+v", rates)
+	}
+
+	// A discount can take the cart back below the minimum
+	pkg.Discount = NewMoney(500, 2, "EUR")
+	if rates, _ = table.Rates(pkg); len(rates) != 1 {
+		t.Errorf("expected no free shipping after the discount, got %+v", rates)
+	}
+
+	// A free shipping coupon unlocks the coupon-only method
+	pkg.FreeShippingCoupon = true
+	rates, _ = table.Rates(pkg)
+	if len(rates) != 2 || rates[1].InstanceID != 6 {
+		t.Errorf("expected the coupon-only free shipping, got %+v", rates)
+	}
+
+	// Formula with [qty]
+	rates, _ = table.Rates(ShippingPackage{Country: "DE", Contents: NewMoney(2000, 2, "EUR"), Quantity: 3})
+	if len(rates) != 1 || rates[0].Cost.String() != "11.00" {
+		t.Errorf("expected 9.50 + 3 * 0.50 = 11.00, got %+v", rates)
+	}
+
+	// Local pickup with no cost is free
+	rates, _ = table.Rates(ShippingPackage{Country: "IT", State: "RM", Postcode: "00186", Contents: NewMoney(1000, 2, "EUR"), Quantity: 1})
+	if len(rates) != 1 || rates[0].MethodID != "local_pickup" || !rates[0].Cost.IsZero() {
+		t.Errorf("expected free local pickup, got %+v", rates)
+	}
+
+	// Zone 0 has no methods: no shipping
+	if rates, _ := table.Rates(ShippingPackage{Country: "US", Contents: NewMoney(1000, 2, "EUR")}); len(rates) != 0 {
+		t.Errorf("expected no rates for the US, got %+v", rates)
+	}
+
+	// Methods whose cost can't be worked out are skipped and reported
+	europe := table.MatchZone(ShippingPackage{Country: "FR"})
+	europe.Methods = append(europe.Methods, ShippingZoneMethod{InstanceID: 7, Title: "EU Express", Order: 1, MethodID: "flat_rate", Enabled: true,
+		Settings: map[string]ShippingMethodSetting{"cost": {Value: `10 + [fee percent="10"]`}}})
+	rates, skipped := table.Rates(ShippingPackage{Country: "FR", Contents: NewMoney(2000, 2, "EUR"), Quantity: 1})
+	if len(rates) != 1 || rates[0].InstanceID != 5 {
+		t.Errorf("expected only EU Standard, got %+v", rates)
+	}
+	if len(skipped) != 1 || !errors.Is(skipped[0], ErrUnsupportedCost) {
+		t.Errorf("expected EU Express to be skipped, got %v", skipped)
+	}
+}
+
+func TestEvalCost(t *testing.T) {
+	pkg := ShippingPackage{Contents: ZeroMoney("EUR"), Quantity: 4}
+
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"", "0.00"},
+		{"7", "7.00"},
+		{"2 * [qty]", "8.00"},
+		{"[qty] * 1.25 + 3", "8.00"},
+	}
+	for _, tt := range tests {
+		got, err := evalCost(tt.formula, pkg)
+		if err != nil {
+			t.Errorf("evalCost(%q) failed: %v", tt.formula, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("evalCost(%q) = %s, want %s", tt.formula, got, tt.want)
+		}
+	}
+
+	for _, formula := range []string{`[fee percent="10"]`, "10 / [qty]", "2 * 3"} {
+		if _, err := evalCost(formula, pkg); err == nil {
+			t.Errorf("evalCost(%q): expected an error", formula)
+		}
+	}
+}
+
+func TestEvalCostStoreNumberFormat(t *testing.T) {
+	// An Italian shop may enter costs with a decimal comma
+	pkg := ShippingPackage{Contents: ZeroMoney("EUR"), Quantity: 2, DecimalSeparator: ",", ThousandSeparator: "."}
+
+	tests := []struct {
+		formula string
+		want    string
+	}{
+		{"5,00", "5.00"},
+		{"5.00", "5.00"},
+		{"1.000,50", "1000.50"},
+		{"2,5 * [qty] + 1", "6.00"},
+	}
+	for _, tt := range tests {
+		got, err := evalCost(tt.formula, pkg)
+		if err != nil {
+			t.Errorf("evalCost(%q) failed: %v", tt.formula, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("evalCost(%q) = %s, want %s", tt.formula, got, tt.want)
+		}
+	}
+}
+
+func TestPostcodeMatches(t *testing.T) {
+	tests := []struct {
+		pattern, postcode string
+		want              bool
+	}{
+		{"00186", "00186", true},
+		{"SW1A 1AA", "sw1a1aa", true},
+		{"SW1*", "SW1A 1AA", true},
+		{"00100...00199", "00150", true},
+		{"00100...00199", "00200", false},
+		{"00100...00199", "ABC", false},
+		{"00186", "", false},
+	}
+	for _, tt := range tests {
+		if got := postcodeMatches(tt.pattern, tt.postcode); got != tt.want {
+			t.Errorf("postcodeMatches(%q, %q) = %v, want %v", tt.pattern, tt.postcode, got, tt.want)
+		}
+	}
+}
+
+func TestGetShippingTable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/shipping/zones":
+			w.Write([]byte(`[{"id":0,"name":"Other","order":0},{"id":1,"name":"Italy","order":0}]`))
+		case "/wp-json/wc/v3/shipping/zones/1/locations":
+			w.Write([]byte(`[{"code":"IT","type":"country"}]`))
+		case "/wp-json/wc/v3/shipping/zones/1/methods":
+			w.Write([]byte(`[{"instance_id":1,"title":"Corriere","order":1,"enabled":true,"method_id":"flat_rate",
+				"settings":{"cost":{"id":"cost","label":"Cost","type":"text","value":"5.00"}}}]`))
+		case "/wp-json/wc/v3/shipping/zones/0/methods":
+			w.Write([]byte(`[]`))
+		case "/wp-json/wc/v3/data/continents":
+			json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"code": "EU", "name": "Europe", "countries": []map[string]string{{"code": "IT"}}},
+			})
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	table, err := client.GetShippingTable(context.Background())
+	if err != nil {
+		t.Fatalf("GetShippingTable failed: %v", err)
+	}
+
+	if len(table.Zones) != 2 || table.Zones[0].ID != 1 || table.Zones[1].ID != 0 {
+		t.Fatalf("expected zone 1 then zone 0, got %+v", table.Zones)
+	}
+	if table.Continents["IT"] != "EU" {
+		t.Errorf("expected IT in EU, got %q", table.Continents["IT"])
+	}
+
+	rates, _ := table.Rates(ShippingPackage{Country: "IT", Contents: NewMoney(1000, 2, "EUR"), Quantity: 1})
+	if len(rates) != 1 || rates[0].Title != "Corriere" || rates[0].Cost.String() != "5.00" {
+		t.Errorf("expected Corriere at 5.00, got %+v", rates)
+	}
+}
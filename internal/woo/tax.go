@@ -0,0 +1,425 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Tax statuses of products and shipping methods.
+const (
+	TaxStatusTaxable  = "taxable"
+	TaxStatusShipping = "shipping" // Only the shipping of the product is taxed
+	TaxStatusNone     = "none"
+)
+
+// Values of the woocommerce_tax_based_on setting.
+const (
+	TaxBasedOnShipping = "shipping"
+	TaxBasedOnBilling  = "billing"
+	TaxBasedOnBase     = "base" // The store address
+)
+
+// StandardTaxClass is the slug of the standard tax class. Products and
+// rates use "" and "standard" interchangeably for it.
+const StandardTaxClass = "standard"
+
+// taxPrecision is the number of decimals taxes are worked out at before
+// the totals are rounded, like WooCommerce's WC_ROUNDING_PRECISION.
+const taxPrecision = 6
+
+// TaxClass is a WooCommerce tax class such as "Standard" or "Reduced rate".
+type TaxClass struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// TaxRate is a tax rate configured for a tax class and location.
+// Empty Country, State, Postcodes or Cities match any location.
+type TaxRate struct {
+	ID        int      `json:"id"`
+	Country   string   `json:"country"`
+	State     string   `json:"state"`
+	Postcodes []string `json:"postcodes"`
+	Cities    []string `json:"cities"`
+	Rate      string   `json:"rate"` // Percentage, e.g. "22.0000"
+	Name      string   `json:"name"` // e.g. "IVA"
+	Priority  int      `json:"priority"`
+	Compound  bool     `json:"compound"` // Applied on top of the other taxes
+	Shipping  bool     `json:"shipping"` // Also applies to shipping
+	Order     int      `json:"order"`
+	Class     string   `json:"class"`
+}
+
+// TaxLocation is the address taxes are worked out for.
+type TaxLocation struct {
+	Country  string
+	State    string
+	Postcode string
+	City     string
+}
+
+// TaxTable holds the tax rates and options of the store.
+type TaxTable struct {
+	PricesIncludeTax bool       // Catalog prices are entered with tax
+	BasedOn          string     // One of the TaxBasedOn* constants
+	ShippingTaxClass string     // "inherit" or a tax class slug
+	Classes          []TaxClass // In the order configured in the store
+	Rates            []TaxRate  // Sorted by priority and order
+}
+
+// TaxableLine is a cart line to work out tax for.
+type TaxableLine struct {
+	Total     Money  // Price times quantity
+	TaxClass  string // "" for the standard class
+	TaxStatus string // One of the TaxStatus* constants, "" is taxable
+}
+
+// TaxTotal is the tax collected by one rate.
+type TaxTotal struct {
+	RateID   int
+	Label    string // e.g. "IVA 22%"
+	Compound bool
+	Amount   Money
+}
+
+// TaxEstimate is the tax on a cart, per rate and in total.
+type TaxEstimate struct {
+	Rates    []TaxTotal // In the order the rates apply
+	Items    Money      // Tax on the items
+	Shipping Money      // Tax on shipping
+	Total    Money
+	Included Money // Part of Total already included in the item prices
+}
+
+// GetTaxClasses fetches the tax classes of the store.
+func (c *Client) GetTaxClasses(ctx context.Context) ([]TaxClass, error) {
+	var classes []TaxClass
+	if err := c.doRequest(ctx, "/wp-json/wc/v3/taxes/classes", nil, &classes); err != nil {
+		return nil, err
+	}
+	return classes, nil
+}
+
+// GetTaxRates fetches every tax rate of a tax class.
+func (c *Client) GetTaxRates(ctx context.Context, class string) ([]TaxRate, error) {
+	query := url.Values{}
+	query.Set("class", class)
+	it := allPages[TaxRate](ctx, c, "/wp-json/wc/v3/taxes", query)
+	defer it.Close()
+	return collect(it)
+}
+
+// GetTaxTable fetches the tax options, the tax classes and the rates of each class.
+func (c *Client) GetTaxTable(ctx context.Context) (*TaxTable, error) {
+	var options []settingOption
+	if err := c.doRequest(ctx, "/wp-json/wc/v3/settings/tax", nil, &options); err != nil {
+		return nil, fmt.Errorf("fetching tax settings: %w", err)
+	}
+
+	table := &TaxTable{BasedOn: TaxBasedOnShipping, ShippingTaxClass: "inherit"}
+	for _, opt := range options {
+		var value string
+		if err := json.Unmarshal(opt.Value, &value); err != nil {
+			continue // Not a string setting
+		}
+		switch opt.ID {
+		case "woocommerce_prices_include_tax":
+			table.PricesIncludeTax = value == "yes"
+		case "woocommerce_tax_based_on":
+			table.BasedOn = value
+		case "woocommerce_shipping_tax_class":
+			table.ShippingTaxClass = value
+		}
+	}
+
+	classes, err := c.GetTaxClasses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching tax classes: %w", err)
+	}
+	table.Classes = classes
+
+	for _, class := range classes {
+		rates, err := c.GetTaxRates(ctx, class.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s tax rates: %w", class.Slug, err)
+		}
+		table.Rates = append(table.Rates, rates...)
+	}
+	sortTaxRates(table.Rates)
+
+	return table, nil
+}
+
+// sortTaxRates sorts rates the way WooCommerce applies them.
+func sortTaxRates(rates []TaxRate) {
+	sort.SliceStable(rates, func(i, j int) bool {
+		if rates[i].Priority != rates[j].Priority {
+			return rates[i].Priority < rates[j].Priority
+		}
+		return rates[i].Order < rates[j].Order
+	})
+}
+
+// normalizeTaxClass maps the empty class to StandardTaxClass.
+func normalizeTaxClass(class string) string {
+	if class == "" {
+		return StandardTaxClass
+	}
+	return class
+}
+
+// FindRates returns the rates of a tax class that apply to a location.
+// Like WooCommerce, only the first matching rate of each priority is used.
+func (t *TaxTable) FindRates(loc TaxLocation, class string) []TaxRate {
+	class = normalizeTaxClass(class)
+
+	var rates []TaxRate
+	seen := make(map[int]bool)
+	for _, rate := range t.Rates {
+		if normalizeTaxClass(rate.Class) != class || seen[rate.Priority] || !rate.matches(loc) {
+			continue
+		}
+		seen[rate.Priority] = true
+		rates = append(rates, rate)
+	}
+	return rates
+}
+
+// matches reports whether a rate applies to a location.
+func (r *TaxRate) matches(loc TaxLocation) bool {
+	if r.Country != "" && !strings.EqualFold(r.Country, loc.Country) {
+		return false
+	}
+	if r.State != "" && !strings.EqualFold(r.State, loc.State) {
+		return false
+	}
+	if len(r.Postcodes) > 0 {
+		matched := false
+		for _, pattern := range r.Postcodes {
+			matched = matched || postcodeMatches(pattern, loc.Postcode)
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.Cities) > 0 {
+		matched := false
+		for _, city := range r.Cities {
+			matched = matched || strings.EqualFold(strings.TrimSpace(city), strings.TrimSpace(loc.City))
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// ppm returns the rate in parts per million, so 22% is 220000.
+func (r *TaxRate) ppm() (int64, error) {
+	ppm, err := parsePercent(r.Rate)
+	if err != nil {
+		return 0, fmt.Errorf("tax rate %d: %w", r.ID, err)
+	}
+	return ppm, nil
+}
+
+// Label returns the name of the rate with its percentage, e.g. "IVA 22%".
+func (r *TaxRate) Label() string {
+	percent := strings.TrimSpace(r.Rate)
+	if strings.Contains(percent, ".") {
+		percent = strings.TrimRight(strings.TrimRight(percent, "0"), ".")
+	}
+	if r.Name == "" {
+		return fmt.Sprintf("Tax %s%%", percent)
+	}
+	return fmt.Sprintf("%s %s%%", r.Name, percent)
+}
+
+// CalcTax returns the tax of each rate on a price. Inclusive prices
+// already contain the tax, which is worked out backwards from them.
+// The amounts are not rounded.
+func CalcTax(price Money, rates []TaxRate, inclusive bool) ([]Money, error) {
+	price = price.Round(taxPrecision)
+	ppms := make([]int64, len(rates))
+	for i := range rates {
+		ppm, err := rates[i].ppm()
+		if err != nil {
+			return nil, err
+		}
+		ppms[i] = ppm
+	}
+
+	taxes := make([]Money, len(rates))
+	if inclusive {
+		// Compound taxes come off first, last applied first
+		remaining := price
+		for i := len(rates) - 1; i >= 0; i-- {
+			if rates[i].Compound {
+				tax, err := remaining.Sub(remaining.MulRatio(1000000, 1000000+ppms[i]))
+				if err != nil {
+					return nil, err
+				}
+				taxes[i] = tax
+				if remaining, err = remaining.Sub(tax); err != nil {
+					return nil, err
+				}
+			}
+		}
+		var regular int64
+		for i := range rates {
+			if !rates[i].Compound {
+				regular += ppms[i]
+			}
+		}
+		net := remaining.MulRatio(1000000, 1000000+regular)
+		for i := range rates {
+			if !rates[i].Compound {
+				taxes[i] = net.MulRatio(ppms[i], 1000000)
+			}
+		}
+		return taxes, nil
+	}
+
+	compoundBase := price
+	for i := range rates {
+		if !rates[i].Compound {
+			taxes[i] = price.MulRatio(ppms[i], 1000000)
+			var err error
+			if compoundBase, err = compoundBase.Add(taxes[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i := range rates {
+		if rates[i].Compound {
+			taxes[i] = compoundBase.MulRatio(ppms[i], 1000000)
+			var err error
+			if compoundBase, err = compoundBase.Add(taxes[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return taxes, nil
+}
+
+// Estimate works out the tax on cart lines and an optional shipping rate,
+// rounded to the given number of decimals per rate. Shipping costs are
+// always entered without tax, so shipping tax is never included.
+func (t *TaxTable) Estimate(loc TaxLocation, lines []TaxableLine, shipping *ShippingRate, decimals int) (*TaxEstimate, error) {
+	totals := make(map[int]*TaxTotal)
+	var order []int
+	itemsByRate := make(map[int]Money)
+	add := func(rate TaxRate, amount Money) error {
+		total, ok := totals[rate.ID]
+		if !ok {
+			total = &TaxTotal{RateID: rate.ID, Label: rate.Label(), Compound: rate.Compound}
+			totals[rate.ID] = total
+			order = append(order, rate.ID)
+		}
+		sum, err := total.Amount.Add(amount)
+		if err != nil {
+			return fmt.Errorf("tax rate %d: %w", rate.ID, err)
+		}
+		total.Amount = sum
+		return nil
+	}
+
+	var classes []string
+	for _, line := range lines {
+		if line.TaxStatus == TaxStatusNone {
+			continue
+		}
+		// Items whose shipping is taxed decide the inherited shipping class
+		classes = append(classes, normalizeTaxClass(line.TaxClass))
+		if line.TaxStatus == TaxStatusShipping {
+			continue
+		}
+		rates := t.FindRates(loc, line.TaxClass)
+		taxes, err := CalcTax(line.Total, rates, t.PricesIncludeTax)
+		if err != nil {
+			return nil, err
+		}
+		for i, rate := range rates {
+			if err := add(rate, taxes[i]); err != nil {
+				return nil, err
+			}
+			// Same currency as the total just added to
+			itemsByRate[rate.ID], _ = itemsByRate[rate.ID].Add(taxes[i])
+		}
+	}
+
+	if shipping != nil && shipping.Taxable {
+		var rates []TaxRate
+		for _, rate := range t.FindRates(loc, t.shippingClass(classes)) {
+			if rate.Shipping {
+				rates = append(rates, rate)
+			}
+		}
+		taxes, err := CalcTax(shipping.Cost, rates, false)
+		if err != nil {
+			return nil, err
+		}
+		for i, rate := range rates {
+			if err := add(rate, taxes[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Round each rate like WooCommerce, then add them up
+	estimate := &TaxEstimate{}
+	for _, id := range order {
+		total := totals[id]
+		total.Amount = total.Amount.Round(decimals)
+		items := itemsByRate[id].Round(decimals)
+
+		estimate.Rates = append(estimate.Rates, *total)
+		shipping, err := total.Amount.Sub(items)
+		if err != nil {
+			return nil, err
+		}
+		if estimate.Items, err = estimate.Items.Add(items); err != nil {
+			return nil, err
+		}
+		if estimate.Shipping, err = estimate.Shipping.Add(shipping); err != nil {
+			return nil, err
+		}
+		if estimate.Total, err = estimate.Total.Add(total.Amount); err != nil {
+			return nil, err
+		}
+	}
+	if t.PricesIncludeTax {
+		estimate.Included = estimate.Items
+	}
+	return estimate, nil
+}
+
+// shippingClass returns the tax class used for shipping. With "inherit"
+// it follows the items: their class if there is only one, otherwise the
+// standard class if present, otherwise the first class in store order.
+func (t *TaxTable) shippingClass(itemClasses []string) string {
+	if t.ShippingTaxClass != "inherit" {
+		return normalizeTaxClass(t.ShippingTaxClass)
+	}
+
+	found := make(map[string]bool)
+	for _, class := range itemClasses {
+		found[class] = true
+	}
+	switch {
+	case len(found) == 1:
+		return itemClasses[0]
+	case len(found) == 0 || found[StandardTaxClass]:
+		return StandardTaxClass
+	}
+	for _, class := range t.Classes {
+		if found[normalizeTaxClass(class.Slug)] {
+			return normalizeTaxClass(class.Slug)
+		}
+	}
+	return itemClasses[0]
+}
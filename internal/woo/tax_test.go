@@ -0,0 +1,219 @@
+package woo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testTaxTable has Italian VAT at 22% and 10%, German VAT, and a 0%
+// fallback for the rest of the world.
+func testTaxTable(pricesIncludeTax bool) *TaxTable {
+	rates := []TaxRate{
+		{ID: 4, Rate: "0.0000", Name: "No tax", Priority: 1, Order: 9, Shipping: true, Class: StandardTaxClass},
+		{ID: 1, Country: "IT", Rate: "22.0000", Name: "IVA", Priority: 1, Shipping: true, Class: StandardTaxClass},
+		{ID: 2, Country: "IT", Rate: "10.0000", Name: "IVA", Priority: 1, Shipping: true, Class: "reduced-rate"},
+		{ID: 3, Country: "DE", Rate: "19.0000", Name: "MwSt", Priority: 1, Order: 1, Shipping: true, Class: StandardTaxClass},
+	}
+	sortTaxRates(rates)
+	return &TaxTable{
+		PricesIncludeTax: pricesIncludeTax,
+		BasedOn:          TaxBasedOnBilling,
+		ShippingTaxClass: "inherit",
+		Classes:          []TaxClass{{Slug: StandardTaxClass, Name: "Standard"}, {Slug: "reduced-rate", Name: "Reduced rate"}},
+		Rates:            rates,
+	}
+}
+
+func TestTaxTableFindRates(t *testing.T) {
+	table := testTaxTable(false)
+
+	tests := []struct {
+		country string
+		class   string
+		want    int
+	}{
+		{"IT", "", 1},
+		{"it", "standard", 1},
+		{"IT", "reduced-rate", 2},
+		{"DE", "", 3},
+		{"US", "", 4}, // Only the fallback matches
+		{"US", "reduced-rate", 0},
+	}
+
+	for _, tt := range tests {
+		rates := table.FindRates(TaxLocation{Country: tt.country}, tt.class)
+		if tt.want == 0 {
+			if len(rates) != 0 {
+				t.Errorf("%s/%s: expected no rates, got %+v", tt.country, tt.class, rates)
+			}
+			continue
+		}
+		// The fallback has the same priority, so only one rate applies
+		if len(rates) != 1 || rates[0].ID != tt.want {
+			t.Errorf("%s/%s: expected rate %d, got %+v", tt.country, tt.class, tt.want, rates)
+		}
+	}
+}
+
+func TestCalcTax(t *testing.T) {
+	vat := TaxRate{ID: 1, Rate: "10.0000"}
+	compound := TaxRate{ID: 2, Rate: "5", Priority: 2, Compound: true}
+
+	tests := []struct {
+		name      string
+		price     string
+		rates     []TaxRate
+		inclusive bool
+		want      []string
+	}{
+		{"exclusive", "18.99", []TaxRate{{Rate: "22.0000"}}, false, []string{"4.177800"}},
+		{"inclusive", "12.20", []TaxRate{{Rate: "22.0000"}}, true, []string{"2.200000"}},
+		{"compound exclusive", "100.00", []TaxRate{vat, compound}, false, []string{"10.000000", "5.500000"}},
+		{"compound inclusive", "115.50", []TaxRate{vat, compound}, true, []string{"10.000000", "5.500000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, _ := ParseMoney(tt.price, "EUR")
+			taxes, err := CalcTax(price, tt.rates, tt.inclusive)
+			if err != nil {
+				t.Fatalf("CalcTax failed: %v", err)
+			}
+			for i, want := range tt.want {
+				if taxes[i].String() != want {
+					t.Errorf("tax %d: expected %s, got %s", i, want, taxes[i])
+				}
+			}
+		})
+	}
+
+	if _, err := CalcTax(NewMoney(100, 2, "EUR"), []TaxRate{{Rate: "22%"}}, false); err == nil {
+		t.Error("expected an error for an invalid rate")
+	}
+}
+
+func TestTaxTableEstimate(t *testing.T) {
+	eur := func(s string) Money {
+		m, _ := ParseMoney(s, "EUR")
+		return m
+	}
+	lines := []TaxableLine{
+		{Total: eur("37.98")},                              // 2 x 18.99 at 22%
+		{Total: eur("10.00"), TaxClass: "reduced-rate"},    // At 10%
+		{Total: eur("5.00"), TaxStatus: TaxStatusNone},     // Not taxed
+		{Total: eur("3.00"), TaxStatus: TaxStatusShipping}, // Only its shipping is taxed
+	}
+	shipping := &ShippingRate{Title: "Corriere", Cost: eur("5.00"), Taxable: true}
+	italy := TaxLocation{Country: "IT", Postcode: "00186"}
+
+	t.Run("exclusive", func(t *testing.T) {
+		estimate, err := testTaxTable(false).Estimate(italy, lines, shipping, 2)
+		if err != nil {
+			t.Fatalf("Estimate failed: %v", err)
+		}
+		if len(estimate.Rates) != 2 {
+			t.Fatalf("expected two rates, got %+v", estimate.Rates)
+		}
+		// 8.3556 on the items plus 1.10 on shipping (standard class, since it is among the items)
+		if r := estimate.Rates[0]; r.Label != "IVA 22%" || r.Amount.String() != "9.46" {
+			t.Errorf("expected IVA 22%% of 9.46, got %s of %s", r.Label, r.Amount)
+		}
+		if r := estimate.Rates[1]; r.Label != "IVA 10%" || r.Amount.String() != "1.00" {
+			t.Errorf("expected IVA 10%% of 1.00, got %s of %s", r.Label, r.Amount)
+		}
+		if estimate.Total.String() != "10.46" || estimate.Shipping.String() != "1.10" || estimate.Items.String() != "9.36" {
+			t.Errorf("unexpected totals %+v", estimate)
+		}
+		if !estimate.Included.IsZero() {
+			t.Errorf("expected no tax included in exclusive prices, got %s", estimate.Included)
+		}
+	})
+
+	t.Run("inclusive", func(t *testing.T) {
+		estimate, err := testTaxTable(true).Estimate(italy, []TaxableLine{{Total: eur("12.20")}}, shipping, 2)
+		if err != nil {
+			t.Fatalf("Estimate failed: %v", err)
+		}
+		// Shipping is always entered without tax
+		if estimate.Included.String() != "2.20" || estimate.Total.String() != "3.30" {
+			t.Errorf("expected 2.20 included and 3.30 in total, got %s and %s", estimate.Included, estimate.Total)
+		}
+	})
+
+	t.Run("untaxed shipping", func(t *testing.T) {
+		free := &ShippingRate{Title: "Free shipping", Cost: ZeroMoney("EUR")}
+		estimate, err := testTaxTable(false).Estimate(TaxLocation{Country: "DE"}, lines[:1], free, 2)
+		if err != nil {
+			t.Fatalf("Estimate failed: %v", err)
+		}
+		if estimate.Total.String() != "7.22" || !estimate.Shipping.IsZero() {
+			t.Errorf("expected 7.22 of German VAT on the items only, got %+v", estimate)
+		}
+	})
+}
+
+func TestTaxTableShippingClass(t *testing.T) {
+	table := testTaxTable(false)
+
+	tests := []struct {
+		setting string
+		items   []string
+		want    string
+	}{
+		{"inherit", []string{"reduced-rate"}, "reduced-rate"},
+		{"inherit", []string{"reduced-rate", StandardTaxClass}, StandardTaxClass},
+		{"inherit", nil, StandardTaxClass},
+		{"", []string{"reduced-rate"}, StandardTaxClass},
+		{"reduced-rate", []string{StandardTaxClass}, "reduced-rate"},
+	}
+
+	for _, tt := range tests {
+		table.ShippingTaxClass = tt.setting
+		if got := table.shippingClass(tt.items); got != tt.want {
+			t.Errorf("shippingClass(%q, %v) = %q, want %q", tt.setting, tt.items, got, tt.want)
+		}
+	}
+}
+
+func TestGetTaxTable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wp-json/wc/v3/settings/tax":
+			w.Write([]byte(`[
+				{"id": "woocommerce_prices_include_tax", "value": "yes"},
+				{"id": "woocommerce_tax_based_on", "value": "billing"},
+				{"id": "woocommerce_shipping_tax_class", "value": "inherit"},
+				{"id": "woocommerce_tax_classes", "value": "Reduced rate"}
+			]`))
+		case "/wp-json/wc/v3/taxes/classes":
+			w.Write([]byte(`[{"slug": "standard", "name": "Standard"}, {"slug": "reduced-rate", "name": "Reduced rate"}]`))
+		case "/wp-json/wc/v3/taxes":
+			rates := map[string][]TaxRate{
+				"standard":     {{ID: 1, Country: "IT", Rate: "22.0000", Name: "IVA", Priority: 1, Order: 1, Class: "standard"}},
+				"reduced-rate": {{ID: 2, Country: "IT", Rate: "10.0000", Name: "IVA", Priority: 1, Class: "reduced-rate"}},
+			}
+			json.NewEncoder(w).Encode(rates[r.URL.Query().Get("class")])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	table, err := NewClient(server.URL).GetTaxTable(context.Background())
+	if err != nil {
+		t.Fatalf("GetTaxTable failed: %v", err)
+	}
+	if !table.PricesIncludeTax || table.BasedOn != TaxBasedOnBilling || table.ShippingTaxClass != "inherit" {
+		t.Errorf("unexpected tax options %+v", table)
+	}
+	if len(table.Classes) != 2 || len(table.Rates) != 2 {
+		t.Fatalf("expected 2 classes and 2 rates, got %+v", table)
+	}
+	if table.Rates[0].ID != 2 {
+		t.Errorf("expected rates sorted by order, got %+v", table.Rates)
+	}
+}
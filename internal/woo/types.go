@@ -1,38 +1,126 @@
 // Package woo provides a client for the WooCommerce REST API.
 package woo
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
 // Product represents a WooCommerce product (simple or variable).
 type Product struct {
-	ID             int         `json:"id"`
-	Name           string      `json:"name"`
-	Type           string      `json:"type"` // "simple" or "variable"
-	Status         string      `json:"status"`
-	Description    string      `json:"description"`
-	ShortDescription string    `json:"short_description"`
-	Price          string      `json:"price"`
-	RegularPrice   string      `json:"regular_price"`
-	SalePrice      string      `json:"sale_price"`
-	StockStatus    string      `json:"stock_status"` // "instock", "outofstock", "onbackorder"
-	StockQuantity  *int        `json:"stock_quantity"`
-	Attributes     []Attribute `json:"attributes"`
-	Variations     []int       `json:"variations"` // IDs of variations for variable products
+	ID                int           `json:"id"`
+	Name              string        `json:"name"`
+	Type              string        `json:"type"` // "simple" or "variable"
+	Status            string        `json:"status"`
+	SKU               string        `json:"sku"`
+	Featured          bool          `json:"featured"`
+	OnSale            bool          `json:"on_sale"`
+	TotalSales        int           `json:"total_sales"`
+	DateCreated       string        `json:"date_created"`
+	Description       string        `json:"description"`
+	ShortDescription  string        `json:"short_description"`
+	Price             string        `json:"price"`
+	RegularPrice      string        `json:"regular_price"`
+	SalePrice         string        `json:"sale_price"`
+	StockStatus       string        `json:"stock_status"` // "instock", "outofstock", "onbackorder"
+	StockQuantity     *int          `json:"stock_quantity"`
+	BackordersAllowed bool          `json:"backorders_allowed"`
+	TaxStatus         string        `json:"tax_status"` // "taxable", "shipping" or "none"
+	TaxClass          string        `json:"tax_class"`  // "" for the standard class
+	Categories        []ProductTerm `json:"categories"`
+	Tags              []ProductTerm `json:"tags"`
+	Attributes        []Attribute   `json:"attributes"`
+	Images            []Image       `json:"images"`     // First is the main image
+	Variations        []int         `json:"variations"` // IDs of variations for variable products
+	ReviewsAllowed    bool          `json:"reviews_allowed"`
+	AverageRating     string        `json:"average_rating"` // e.g. "4.50", "0" without ratings
+	RatingCount       int           `json:"rating_count"`
+}
+
+// Image is a product image in the shop's media library.
+type Image struct {
+	ID   int    `json:"id"`
+	Src  string `json:"src"` // Full-size image URL
+	Name string `json:"name"`
+	Alt  string `json:"alt"`
+}
+
+// ProductTerm is a category or tag reference embedded in a product.
+type ProductTerm struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// Category represents a product category (e.g., "Single Origin" or "Decaf").
+type Category struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Parent      int    `json:"parent"` // 0 for top-level categories
+	Description string `json:"description"`
+	Count       int    `json:"count"` // Number of published products
+}
+
+// Tag represents a product tag (e.g., "Fruity").
+type Tag struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	Count       int    `json:"count"`
+}
+
+// PageInfo holds pagination metadata for a list endpoint.
+type PageInfo struct {
+	Page       int // 1-based page number that was requested
+	PerPage    int
+	TotalItems int // From the X-WP-Total header
+	TotalPages int // From the X-WP-TotalPages header
+}
+
+// HasNext returns true if there is a page after this one.
+func (p PageInfo) HasNext() bool {
+	return p.Page < p.TotalPages
+}
+
+// HasPrev returns true if there is a page before this one.
+func (p PageInfo) HasPrev() bool {
+	return p.Page > 1
+}
+
+// ProductPage is a single page of products with its pagination metadata.
+type ProductPage struct {
+	Products []Product
+	PageInfo
 }
 
 // Variation represents a product variation (e.g., 250g or 1kg version).
 type Variation struct {
-	ID            int                 `json:"id"`
-	Price         string              `json:"price"`
-	RegularPrice  string              `json:"regular_price"`
-	SalePrice     string              `json:"sale_price"`
-	StockStatus   string              `json:"stock_status"`
-	StockQuantity *int                `json:"stock_quantity"`
-	Attributes    []VariationAttribute `json:"attributes"`
+	ID                int                  `json:"id"`
+	Status            string               `json:"status"` // "publish", or "private" when disabled
+	Price             string               `json:"price"`
+	RegularPrice      string               `json:"regular_price"`
+	SalePrice         string               `json:"sale_price"`
+	OnSale            bool                 `json:"on_sale"`
+	StockStatus       string               `json:"stock_status"`
+	StockQuantity     *int                 `json:"stock_quantity"`
+	BackordersAllowed bool                 `json:"backorders_allowed"`
+	TaxStatus         string               `json:"tax_status"`
+	TaxClass          string               `json:"tax_class"` // "parent" to use the product's class
+	Attributes        []VariationAttribute `json:"attributes"`
 }
 
 // Attribute represents a product attribute (e.g., "Grind Size" or "Weight").
 type Attribute struct {
 	ID        int      `json:"id"`
 	Name      string   `json:"name"`
+	Slug      string   `json:"slug"` // Sent by newer stores, see Key
 	Position  int      `json:"position"`
 	Visible   bool     `json:"visible"`
 	Variation bool     `json:"variation"` // True if used for variations
@@ -46,6 +134,26 @@ type VariationAttribute struct {
 	Option string `json:"option"` // The selected value
 }
 
+// HasCategory returns true if the product belongs to the category with the given ID.
+func (p *Product) HasCategory(id int) bool {
+	for _, c := range p.Categories {
+		if c.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTag returns true if the product has the tag with the given ID.
+func (p *Product) HasTag(id int) bool {
+	for _, t := range p.Tags {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // IsInStock returns true if the product is in stock.
 func (p *Product) IsInStock() bool {
 	return p.StockStatus == "instock"
@@ -67,6 +175,11 @@ func (p *Product) GetDisplayPrice() string {
 	return p.RegularPrice
 }
 
+// DisplayPrice parses the display price as Money in the given currency.
+func (p *Product) DisplayPrice(currency string) (Money, error) {
+	return ParseMoney(p.GetDisplayPrice(), currency)
+}
+
 // GetAttribute returns the attribute with the given name, or nil if not found.
 func (p *Product) GetAttribute(name string) *Attribute {
 	for i := range p.Attributes {
@@ -77,6 +190,59 @@ func (p *Product) GetAttribute(name string) *Attribute {
 	return nil
 }
 
+// Key returns the attribute's slug, which WooCommerce keys the option
+// picked for it by: the taxonomy for global attributes, e.g. "pa_color",
+// and the sanitized name for custom ones, e.g. "gift-wrap". Stores that
+// don't send the slug get it worked out from the name.
+func (a *Attribute) Key() string {
+	if a.Slug != "" {
+		return a.Slug
+	}
+	if a.ID != 0 {
+		return "pa_" + sanitizeTitle(a.Name)
+	}
+	return sanitizeTitle(a.Name)
+}
+
+// sanitizeTitle makes a slug of a name the way WordPress does for plain
+// ASCII names: lower case, with runs of spaces and dashes as one dash and
+// other punctuation dropped.
+func sanitizeTitle(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			dash = false
+			sb.WriteRune(r)
+		case r == ' ' || r == '-':
+			dash = true
+		}
+	}
+	return sb.String()
+}
+
+// MainImage returns the product's main image, or nil if it has none.
+func (p *Product) MainImage() *Image {
+	if len(p.Images) == 0 || p.Images[0].Src == "" {
+		return nil
+	}
+	return &p.Images[0]
+}
+
+// Rating returns the product's average rating out of 5, or 0 if it has
+// no ratings.
+func (p *Product) Rating() float64 {
+	r, err := strconv.ParseFloat(p.AverageRating, 64)
+	if err != nil || p.RatingCount == 0 {
+		return 0
+	}
+	return r
+}
+
 // IsInStock returns true if the variation is in stock.
 func (v *Variation) IsInStock() bool {
 	return v.StockStatus == "instock"
@@ -93,6 +259,11 @@ func (v *Variation) GetDisplayPrice() string {
 	return v.RegularPrice
 }
 
+// DisplayPrice parses the display price as Money in the given currency.
+func (v *Variation) DisplayPrice(currency string) (Money, error) {
+	return ParseMoney(v.GetDisplayPrice(), currency)
+}
+
 // GetAttributeValue returns the value for an attribute by name.
 func (v *Variation) GetAttributeValue(name string) string {
 	for _, attr := range v.Attributes {
@@ -109,20 +280,25 @@ func (v *Variation) GetAttributeValue(name string) string {
 
 // OrderRequest represents the data needed to create a WooCommerce order.
 type OrderRequest struct {
-	PaymentMethod      string           `json:"payment_method"`
-	PaymentMethodTitle string           `json:"payment_method_title"`
-	SetPaid            bool             `json:"set_paid"`
-	Billing            BillingAddress   `json:"billing"`
-	Shipping           *BillingAddress  `json:"shipping,omitempty"`
-	LineItems          []OrderLineItem  `json:"line_items"`
-	ShippingLines      []ShippingLine   `json:"shipping_lines,omitempty"`
+	CustomerID         int             `json:"customer_id,omitempty"` // Zero for a guest order
+	PaymentMethod      string          `json:"payment_method"`
+	PaymentMethodTitle string          `json:"payment_method_title"`
+	SetPaid            bool            `json:"set_paid"`
+	Billing            BillingAddress  `json:"billing"`
+	Shipping           *BillingAddress `json:"shipping,omitempty"`
+	LineItems          []OrderLineItem `json:"line_items"`
+	ShippingLines      []ShippingLine  `json:"shipping_lines,omitempty"`
+	CouponLines        []CouponLine    `json:"coupon_lines,omitempty"`
+	MetaData           []OrderMetaData `json:"meta_data,omitempty"`
 }
 
 // ShippingLine represents a shipping line in an order.
 type ShippingLine struct {
-	MethodID    string `json:"method_id"`
-	MethodTitle string `json:"method_title"`
-	Total       string `json:"total"`
+	MethodID    string                  `json:"method_id"`
+	MethodTitle string                  `json:"method_title"`
+	InstanceID  string                  `json:"instance_id,omitempty"` // Shipping method instance in its zone
+	Total       string                  `json:"total"`
+	MetaData    []OrderLineItemMetaData `json:"meta_data,omitempty"`
 }
 
 // BillingAddress represents the billing address for an order.
@@ -139,32 +315,80 @@ type BillingAddress struct {
 	Country   string `json:"country"`
 }
 
-// OrderLineItem represents a line item in an order.
+// OrderLineItem represents a line item in an order. Name, SKU and the
+// totals are filled in by the store; leave them empty when placing an order.
 type OrderLineItem struct {
 	ProductID   int                     `json:"product_id"`
 	VariationID int                     `json:"variation_id,omitempty"`
 	Quantity    int                     `json:"quantity"`
 	MetaData    []OrderLineItemMetaData `json:"meta_data,omitempty"`
+	Name        string                  `json:"name,omitempty"`
+	SKU         string                  `json:"sku,omitempty"`
+	Subtotal    string                  `json:"subtotal,omitempty"`     // Line total before discounts and tax
+	SubtotalTax string                  `json:"subtotal_tax,omitempty"` // Tax on Subtotal
+	Total       string                  `json:"total,omitempty"`        // Line total after discounts, before tax
 }
 
 // OrderLineItemMetaData represents metadata for a line item (e.g., grind size).
+// Like OrderMetaData, plugins may store any JSON in the value.
 type OrderLineItemMetaData struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// StringLineItemMetaData returns line item metadata with a string value.
+func StringLineItemMetaData(key, value string) OrderLineItemMetaData {
+	encoded, _ := json.Marshal(value)
+	return OrderLineItemMetaData{Key: key, Value: encoded}
+}
+
+// String returns the value for display: a string as it is, a number or
+// boolean as written, and "" for null, objects and arrays.
+func (m OrderLineItemMetaData) String() string {
+	var value interface{}
+	if err := json.Unmarshal(m.Value, &value); err != nil {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64, bool:
+		return string(bytes.TrimSpace(m.Value))
+	}
+	return ""
 }
 
-// OrderResponse represents the response from creating an order.
+// OrderResponse represents an order as returned by the API.
 type OrderResponse struct {
-	ID            int             `json:"id"`
-	Status        string          `json:"status"`
-	Currency      string          `json:"currency"`
-	Total         string          `json:"total"`
-	TotalTax      string          `json:"total_tax"`
-	Billing       BillingAddress  `json:"billing"`
-	LineItems     []OrderLineItem `json:"line_items"`
-	DateCreated   string          `json:"date_created"`
-	OrderKey      string          `json:"order_key"`
-	PaymentMethod string          `json:"payment_method"`
+	ID                 int             `json:"id"`
+	CustomerID         int             `json:"customer_id"` // Zero for a guest order
+	Status             string          `json:"status"`
+	Currency           string          `json:"currency"`
+	Total              string          `json:"total"`
+	TotalTax           string          `json:"total_tax"`
+	DiscountTotal      string          `json:"discount_total"`
+	ShippingTotal      string          `json:"shipping_total"`
+	PricesIncludeTax   bool            `json:"prices_include_tax"`
+	Billing            BillingAddress  `json:"billing"`
+	Shipping           BillingAddress  `json:"shipping"`
+	LineItems          []OrderLineItem `json:"line_items"`
+	ShippingLines      []ShippingLine  `json:"shipping_lines"`
+	CouponLines        []CouponLine    `json:"coupon_lines"`
+	DateCreated        string          `json:"date_created"` // Store local time, "2006-01-02T15:04:05"
+	OrderKey           string          `json:"order_key"`
+	PaymentMethod      string          `json:"payment_method"`
+	PaymentMethodTitle string          `json:"payment_method_title"`
+	CustomerNote       string          `json:"customer_note"`
+	MetaData           []OrderMetaData `json:"meta_data"`
+}
+
+// ItemCount returns the number of units ordered.
+func (o *OrderResponse) ItemCount() int {
+	count := 0
+	for _, item := range o.LineItems {
+		count += item.Quantity
+	}
+	return count
 }
 
 // PaymentGateway represents an available payment method.
@@ -172,7 +396,7 @@ type PaymentGateway struct {
 	ID                string                 `json:"id"`
 	Title             string                 `json:"title"`
 	Description       string                 `json:"description"`
-	Order             int                    `json:"order"`
+	Order             GatewayOrder           `json:"order"`
 	Enabled           bool                   `json:"enabled"`
 	MethodTitle       string                 `json:"method_title"`
 	MethodDescription string                 `json:"method_description"`
@@ -180,3 +404,39 @@ type PaymentGateway struct {
 	Settings          map[string]interface{} `json:"settings"`
 	NeedsSetup        bool                   `json:"needs_setup"`
 }
+
+// GatewayOrder is a gateway's position at checkout, lowest first.
+// WooCommerce sends it as a number, a numeric string, or "" for gateways
+// the shop never sorted, which decode as Unordered.
+type GatewayOrder int
+
+// Unordered is the order of gateways without one; they are listed last.
+const Unordered GatewayOrder = math.MaxInt32
+
+// UnmarshalJSON accepts 1, "1" and "".
+func (o *GatewayOrder) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*o = Unordered
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data) // A number
+	}
+	if s == "" {
+		*o = Unordered
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid gateway order %s", data)
+	}
+	*o = GatewayOrder(n)
+	return nil
+}
+
+// IsAvailable returns true if customers can pay with the gateway:
+// it is enabled and its settings are complete.
+func (g *PaymentGateway) IsAvailable() bool {
+	return g.Enabled && !g.NeedsSetup
+}